@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ffuf/ffuf/v2/pkg/engine"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/output"
+	"github.com/ffuf/ffuf/v2/pkg/runner"
+)
+
+// auditFlags are the flags of the audit subcommand outside of the matcher,
+// filter and output sections, which it takes as a whole.
+var auditFlags = map[string]bool{
+	"c": true, "json": true, "s": true, "v": true, "t": true, "rate": true,
+	"timeout": true, "x": true, "replay-proxy": true,
+}
+
+// runAudit implements "ffuf audit": it reads the responses of an -audit-log back,
+// matches and filters them again with the matcher and filter flags given to it
+// and writes the matches to the output formats, without sending a request. With
+// -replay the requests of the matching responses are sent to the target again.
+// The files of a rotated log are read in the order they are given.
+func runAudit(args []string) int {
+	opts := ffuf.NewConfigOptions()
+	all := flag.NewFlagSet("ffuf", flag.ContinueOnError)
+	registry := ffuf.RegisterFlags(all, opts)
+
+	fs := flag.NewFlagSet("ffuf audit", flag.ContinueOnError)
+	all.VisitAll(func(f *flag.Flag) {
+		section, _ := registry.SectionOf(f.Name)
+		if section == ffuf.SectionMatcher || section == ffuf.SectionFilter || section == ffuf.SectionOutput || auditFlags[f.Name] {
+			if f.Name != "audit-log" && f.Name != "debug-log" {
+				fs.Var(f.Value, f.Name, f.Usage)
+			}
+		}
+	})
+	replay := fs.Bool("replay", false, "Send the requests of the matching responses to the target again, and report the live responses that still match")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ffuf audit [options] AUDITLOG...\n\n")
+		fmt.Fprintf(os.Stderr, "Match and filter the responses of an -audit-log again and write the results, without sending a request.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf, err := auditConfig(fs.Arg(0), opts, ctx, cancel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+
+	statusSet, matcherSet := false, false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "mc":
+			statusSet = true
+		case "ms", "ml", "mw", "mr", "mt", "mge", "mgm", "mgd":
+			matcherSet = true
+		}
+	})
+	if conf.MatcherManager, err = filter.FromConfig(opts, statusSet || !matcherSet); err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+
+	job := engine.NewJob(conf)
+	stdout := output.NewStdoutput(conf)
+	if conf.Baseline != "" {
+		if err = stdout.LoadBaseline(conf.Baseline); err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			return 1
+		}
+	}
+	job.Output = stdout
+	job.Runner = runner.NewRunner(conf, false)
+	if len(conf.ReplayProxyURL) > 0 {
+		job.ReplayRunner = runner.NewRunner(conf, true)
+	}
+
+	responses := make(chan ffuf.Response)
+	var wg sync.WaitGroup
+	for i := 0; i < conf.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for resp := range responses {
+				job.Reprocess(resp, *replay)
+			}
+		}()
+	}
+	for _, filename := range fs.Args() {
+		if err = readAuditResponses(filename, responses); err != nil {
+			break
+		}
+	}
+	close(responses)
+	wg.Wait()
+	if err != nil {
+		job.Output.Error(err.Error())
+	}
+	_ = job.Output.Finalize()
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// readAuditResponses sends the responses of an audit log file to responses
+func readAuditResponses(filename string, responses chan<- ffuf.Response) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("Could not open audit log: %s", err)
+	}
+	defer f.Close()
+	return output.ReadAuditLog(f, func(entry output.AuditEntry) error {
+		if entry.Response != nil {
+			responses <- *entry.Response
+		}
+		return nil
+	})
+}
+
+// auditConfig returns the config of the run an audit log was written by, with
+// the output, display and connection options of the audit subcommand applied.
+func auditConfig(filename string, opts *ffuf.ConfigOptions, ctx context.Context, cancel context.CancelFunc) (*ffuf.Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %s", err)
+	}
+	defer f.Close()
+
+	base := ffuf.NewConfig(ctx, cancel)
+	conf := &base
+	errStop := fmt.Errorf("config read")
+	err = output.ReadAuditLog(f, func(entry output.AuditEntry) error {
+		if entry.Config != nil {
+			conf = entry.Config
+		}
+		// The config is the first entry
+		return errStop
+	})
+	if err != nil && err != errStop {
+		return nil, err
+	}
+	conf.SetContext(ctx, cancel)
+	if conf.Headers == nil {
+		conf.Headers = make(map[string]string)
+	}
+	if conf.Resolve == nil {
+		conf.Resolve = make(map[string]string)
+	}
+
+	if opts.Output.OutputFile != "" && !output.ValidFormat(opts.Output.OutputFormat) {
+		return nil, fmt.Errorf("Unknown output file format (-of): %s", opts.Output.OutputFormat)
+	}
+	conf.OutputFile = opts.Output.OutputFile
+	conf.OutputFormat = opts.Output.OutputFormat
+	conf.OutputDirectory = opts.Output.OutputDirectory
+	conf.OutputRequestDir = opts.Output.OutputRequestDir
+	conf.OutputSkipEmptyFile = opts.Output.OutputSkipEmptyFile
+	conf.AuditLog = ""
+	conf.ResultsDB = opts.Output.ResultsDB
+	conf.Baseline = opts.Output.Baseline
+	conf.Colors = opts.General.Colors
+	conf.Json = opts.General.Json
+	conf.Quiet = opts.General.Quiet
+	conf.Verbose = opts.General.Verbose
+	conf.Noninteractive = true
+	conf.Threads = opts.General.Threads
+	if conf.Threads < 1 {
+		conf.Threads = 1
+	}
+	conf.Rate = int64(opts.General.Rate)
+	conf.Timeout = opts.HTTP.Timeout
+	conf.ProxyURL = opts.HTTP.ProxyURL
+	conf.ReplayProxyURL = opts.HTTP.ReplayProxyURL
+	conf.MatcherMode = opts.Matcher.Mode
+	conf.FilterMode = opts.Filter.Mode
+	// The logged requests already carry the values the preflights extracted
+	conf.Preflights = nil
+	conf.Postflights = nil
+	return conf, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// writeAuditLog runs a scan of /size/FUZZ matching everything and returns the
+// path of its audit log.
+func writeAuditLog(t *testing.T, url string, sizes []string) string {
+	t.Helper()
+	dir := t.TempDir()
+	wordlist := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(wordlist, []byte(strings.Join(sizes, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = url + "/size/FUZZ"
+	opts.Input.Wordlists = []string{wordlist}
+	opts.Output.AuditLog = filepath.Join(dir, "audit.log")
+	opts.General.Quiet = true
+	opts.General.Noninteractive = true
+	opts.Matcher.Status = "all"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.MatcherManager, err = filter.FromConfig(opts, true); err != nil {
+		t.Fatal(err)
+	}
+	job, err := assembly.BuildJob(conf)
+	if err != nil {
+		t.Fatalf("BuildJob: %v", err)
+	}
+	job.Start()
+	job.AuditLogger.Close()
+	return opts.Output.AuditLog
+}
+
+func readJSONInputs(t *testing.T, filename string) []string {
+	t.Helper()
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var doc struct {
+		Results []struct {
+			Input map[string]string `json:"input"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	var inputs []string
+	for _, r := range doc.Results {
+		inputs = append(inputs, r.Input["FUZZ"])
+	}
+	sort.Strings(inputs)
+	return inputs
+}
+
+func TestAuditRefilter(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	auditlog := writeAuditLog(t, target.URL, []string{"10", "20", "30"})
+	sent := target.Count()
+
+	out := filepath.Join(t.TempDir(), "out.json")
+	var code int
+	captureStdout(func() {
+		code = runAudit([]string{"-s", "-mc", "all", "-fs", "20", "-o", out, "-of", "json", auditlog})
+	})
+	if code != 0 {
+		t.Fatalf("audit exited with %d", code)
+	}
+	if got := readJSONInputs(t, out); strings.Join(got, ",") != "10,30" {
+		t.Errorf("refiltered results = %v, want [10 30]", got)
+	}
+	if target.Count() != sent {
+		t.Errorf("audit sent %d requests without -replay", target.Count()-sent)
+	}
+}
+
+func TestAuditReplay(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	auditlog := writeAuditLog(t, target.URL, []string{"10", "20", "30"})
+	sent := target.Count()
+
+	out := filepath.Join(t.TempDir(), "out.json")
+	var code int
+	captureStdout(func() {
+		code = runAudit([]string{"-s", "-ms", "20,30", "-replay", "-o", out, auditlog})
+	})
+	if code != 0 {
+		t.Fatalf("audit exited with %d", code)
+	}
+	if got := readJSONInputs(t, out); strings.Join(got, ",") != "20,30" {
+		t.Errorf("replayed results = %v, want [20 30]", got)
+	}
+	if replayed := target.Count() - sent; replayed != 2 {
+		t.Errorf("replayed %d requests, want the 2 matching ones", replayed)
+	}
+
+	if code := runAudit([]string{"-of", "nope", "-o", out, auditlog}); code == 0 {
+		t.Errorf("expected an unknown output format to fail")
+	}
+}
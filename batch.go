@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ffuf/ffuf/v2/pkg/batch"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// runBatch implements -batch: it runs the jobs of the manifest, each starting
+// from the ffufrc, and prints their summary. It exits with 1 when the manifest
+// cannot be read or a job could not be started.
+func runBatch(filename string) int {
+	b, err := ffuf.ReadBatch(filename, func() *ffuf.ConfigOptions {
+		opts, _ := ffuf.ReadDefaultConfig()
+		return opts
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	summaries, err := batch.Run(ctx, b)
+	batch.PrintSummary(os.Stderr, summaries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	for _, s := range summaries {
+		if s.Status == batch.StatusFailed {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/output"
+)
+
+// runDiff implements "ffuf diff": it compares the results of two JSON output
+// files and prints the new, changed and gone ones, or writes them to any of the
+// output formats. Like diff(1) it exits with 0 when there are no differences,
+// 1 when there are and 2 on error.
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("ffuf diff", flag.ContinueOnError)
+	colors := fs.Bool("c", false, "Colorize output.")
+	jsonOut := fs.Bool("json", false, "JSON output, printing newline-delimited JSON records")
+	quiet := fs.Bool("s", false, "Only print the inputs of the results, prefixed with +, - or ~ for new, gone and changed")
+	verbose := fs.Bool("v", false, "Verbose output, printing full URL and redirect location (if any) with the results.")
+	outputFile := fs.String("o", "", "Write the differences to a file")
+	outputFormat := fs.String("of", "json", "Output file format. Available formats: json, ejson, html, md, csv, ecsv, har (or, 'all' for all formats)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ffuf diff [options] OLD.json NEW.json\n\n")
+		fmt.Fprintf(os.Stderr, "Compare the results of two runs, matched on their URL and inputs, and output the new, changed and gone ones.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	if *outputFile != "" && !output.ValidFormat(*outputFormat) {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): Unknown output file format (-of): %s\n", *outputFormat)
+		return 2
+	}
+
+	old, err := output.ReadResults(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 2
+	}
+	current, err := output.ReadResults(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 2
+	}
+
+	diff := output.DiffResults(old, current)
+	out := resultsOutput(diff, func(conf *ffuf.Config) {
+		conf.Colors = *colors
+		conf.Json = *jsonOut
+		conf.Quiet = *quiet
+		conf.Verbose = *verbose
+		conf.OutputFile = *outputFile
+		conf.OutputFormat = *outputFormat
+	})
+	for _, res := range diff {
+		out.PrintResult(res)
+	}
+	if *outputFile != "" {
+		if err = out.SaveFile(*outputFile, *outputFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			return 2
+		}
+	}
+	if len(diff) > 0 {
+		return 1
+	}
+	return 0
+}
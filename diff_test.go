@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, results string) string {
+		filename := filepath.Join(dir, name)
+		content := `{"commandline":"ffuf","time":"2024-01-01T00:00:00Z","config":{},"results":[` + results + `]}`
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	old := write("old.json", `{"input":{"FUZZ":"admin"},"url":"https://example.org/admin","status":200,"length":10,"words":1},`+
+		`{"input":{"FUZZ":"backup"},"url":"https://example.org/backup","status":200,"length":10,"words":1}`)
+	current := write("new.json", `{"input":{"FUZZ":"login"},"url":"https://example.org/login","status":200,"length":10,"words":1},`+
+		`{"input":{"FUZZ":"admin"},"url":"https://example.org/admin","status":403,"length":10,"words":1}`)
+
+	var code int
+	out := captureStdout(func() { code = runDiff([]string{"-s", old, current}) })
+	if code != 1 {
+		t.Errorf("expected exit code 1 for differences, got %d", code)
+	}
+	if got := strings.Fields(out); strings.Join(got, " ") != "+ login ~ admin - backup" {
+		t.Errorf("unexpected diff output %q", out)
+	}
+
+	out = captureStdout(func() { code = runDiff([]string{old, old}) })
+	if code != 0 || strings.TrimSpace(out) != "" {
+		t.Errorf("expected no differences between the same files, got %d and %q", code, out)
+	}
+	if code := runDiff([]string{old}); code != 2 {
+		t.Errorf("expected exit code 2 for a missing file argument, got %d", code)
+	}
+}
@@ -7,12 +7,14 @@ require (
 	github.com/adrg/xdg v0.4.0
 	github.com/andybalholm/brotli v1.0.5
 	github.com/ffuf/pencode v0.0.0-20230421231718-2cea7e60a693
+	github.com/klauspost/compress v1.16.7
 	github.com/pelletier/go-toml v1.9.5
+	go.etcd.io/bbolt v1.3.7
+	go.starlark.net v0.0.0-20240123142251-f86470692795
 )
 
 require (
 	github.com/andybalholm/cascadia v1.3.1 // indirect
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	golang.org/x/net v0.7.0 // indirect
 	golang.org/x/sys v0.5.0 // indirect
 )
@@ -34,6 +34,15 @@ func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		os.Exit(runAudit(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "results" {
+		os.Exit(runResults(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
 
 	var err, optserr error
 	ctx, cancel := context.WithCancel(context.Background())
@@ -93,6 +102,10 @@ func main() {
 		log.Printf("Error while opening default config file: %s", optserr)
 	}
 
+	if opts.General.Batch != "" {
+		os.Exit(runBatch(opts.General.Batch))
+	}
+
 	if opts.General.ConfigFile != "" {
 		opts, err = ffuf.ReadConfig(opts.General.ConfigFile)
 		if err != nil {
@@ -116,22 +129,56 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The filters are set up first, the filter and matcher plugins are added
+	// to them
+	if err := SetupFilters(conf.Options, conf); err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		Usage()
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		os.Exit(1)
+	}
+
 	job, err := assembly.BuildJob(conf)
 
 	if job.AuditLogger != nil {
 		defer job.AuditLogger.Close()
 	}
+	if job.Hooks != nil {
+		// Wait for the hooks of the last events, like job-finished
+		defer job.Hooks.Close()
+	}
+	if job.Plugins != nil {
+		defer job.Plugins.Close()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
 		Usage()
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
 		os.Exit(1)
 	}
-	if err := SetupFilters(opts, conf); err != nil {
-		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
-		Usage()
-		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
-		os.Exit(1)
+
+	if conf.Api != "" {
+		server, err := interactive.ServeAPI(job, conf.Api, conf.ApiToken)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			os.Exit(1)
+		}
+		defer server.Close()
+		// The banner shows the address actually listened at, for port 0
+		conf.Api = server.Addr()
+		if conf.ApiToken == "" {
+			fmt.Fprintf(os.Stderr, "Control API token: %s\n", server.Token)
+		}
+	}
+
+	if conf.Metrics != "" {
+		server, err := engine.ServeMetrics(job, conf.Metrics)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			os.Exit(1)
+		}
+		defer server.Close()
+		conf.Metrics = server.Addr()
 	}
 
 	if !conf.Noninteractive {
@@ -170,7 +217,7 @@ func SetupFilters(parseOpts *ffuf.ConfigOptions, conf *ffuf.Config) error {
 		case "ms", "ml", "mw":
 			matcherSet = true
 			responseMatcherSet = true
-		case "mr", "mt":
+		case "mr", "mt", "mge", "mgm", "mgd":
 			matcherSet = true
 		}
 	})
@@ -198,7 +245,7 @@ func printSearchResults(conf *ffuf.Config, pos int, exectime time.Time, hash str
 	inputdata := inp.Value()
 	inputdata["FFUFHASH"] = []byte(hash)
 	basereq := ffuf.BaseRequest(conf)
-	dummyrunner := runner.NewSimpleRunner(conf, false)
+	dummyrunner := runner.NewRunner(conf, false)
 	ffufreq, _ := dummyrunner.Prepare(inputdata, &basereq)
 	rawreq, _ := dummyrunner.Dump(&ffufreq)
 	fmt.Printf("-------------------------------------------\n")
@@ -1,42 +1,83 @@
 // Package assembly wires a ffuf.Job together from a Config: the input provider,
-// runner(s), output provider, audit logger, and scraper. It exists as its own
-// package because it must import the provider packages (input/runner/output/
-// scraper), which themselves import pkg/ffuf - so this composition cannot live in
-// pkg/ffuf without an import cycle, and it cannot stay unexported in package main
-// without being untestable. Both main and the integration tests call BuildJob, so
-// the production and test wiring can never drift.
+// runner(s), output provider, audit logger, hooks, plugins, script, scraper and
+// the matchers of the content recursion strategy.
+// It exists as its own package because it must import the provider packages
+// (input/runner/output/scraper), which themselves import pkg/ffuf - so this
+// composition cannot live in pkg/ffuf without an import cycle, and it cannot
+// stay unexported in package main without being untestable. Both main and the
+// integration tests call BuildJob, so the production and test wiring can never
+// drift.
 package assembly
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/ffuf/ffuf/v2/pkg/engine"
 	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
 	"github.com/ffuf/ffuf/v2/pkg/input"
 	"github.com/ffuf/ffuf/v2/pkg/output"
+	"github.com/ffuf/ffuf/v2/pkg/plugin"
 	"github.com/ffuf/ffuf/v2/pkg/runner"
 	"github.com/ffuf/ffuf/v2/pkg/scraper"
+	"github.com/ffuf/ffuf/v2/pkg/script"
 )
 
 // BuildJob constructs and wires a Job from conf. It is the single source of truth
 // for how a Job is assembled. Matchers/filters are NOT installed here (they need
-// the CLI flag state); see filter.FromConfig and main.SetupFilters.
+// the CLI flag state); see filter.FromConfig and main.SetupFilters. They must be
+// installed before though when conf has plugins, the filter and matcher plugins
+// are added to them.
 func BuildJob(conf *ffuf.Config) (*engine.Job, error) {
+	return buildJob(conf, false)
+}
+
+// BuildEmbeddedJob constructs a Job like BuildJob, for running within another
+// program through the pkg/scan API: nothing is read from or written to the ffuf
+// config directory, the scrapers are only read from -scraperfile, and the job
+// leaves the signals to the program.
+func BuildEmbeddedJob(conf *ffuf.Config) (*engine.Job, error) {
+	return buildJob(conf, true)
+}
+
+func buildJob(conf *ffuf.Config, embedded bool) (*engine.Job, error) {
 	var err error
 	job := engine.NewJob(conf)
+	job.Embedded = embedded
 	var errs ffuf.Multierror
 
 	job.Input, errs = input.NewInputProvider(conf)
 
-	// Only the http (SimpleRunner) runner exists today.
-	job.Runner = runner.NewSimpleRunner(conf, false)
+	job.Runner = runner.NewRunner(conf, false)
 	if len(conf.ReplayProxyURL) > 0 {
-		job.ReplayRunner = runner.NewSimpleRunner(conf, true)
+		job.ReplayRunner = runner.NewRunner(conf, true)
+	}
+
+	// Feed the names suggested in GraphQL errors back to the -graphql-harvest input
+	if gql, ok := job.Runner.(*runner.GraphQLRunner); ok && conf.GraphQLHarvest != "" {
+		if harvester := input.Harvester(job.Input, conf.GraphQLHarvest); harvester != nil {
+			gql.OnSuggestions(harvester.Add)
+		}
 	}
 
 	// Only the stdout output provider exists today.
-	job.Output = output.NewStdoutput(conf)
+	stdout := output.NewStdoutput(conf)
+	if conf.Baseline != "" {
+		if err = stdout.LoadBaseline(conf.Baseline); err != nil {
+			errs.Add(err)
+		}
+	}
+	job.Output = stdout
 
 	if len(conf.AuditLog) > 0 {
-		job.AuditLogger, err = output.NewAuditLogger(conf.AuditLog)
+		job.AuditLogger, err = output.NewAuditLoggerWithOptions(conf.AuditLog, output.AuditLogOptions{
+			MaxSize:    conf.AuditLogMaxSize,
+			MaxAge:     time.Duration(conf.AuditLogMaxAge) * time.Second,
+			Compress:   conf.AuditLogCompress,
+			OmitBodies: conf.AuditLogNoBody,
+		})
 		if err != nil {
 			errs.Add(err)
 		} else {
@@ -46,11 +87,55 @@ func BuildJob(conf *ffuf.Config) (*engine.Job, error) {
 		}
 	}
 
-	newscraper, scraperErr := scraper.FromDir(ffuf.SCRAPERDIR, conf.Scrapers)
-	if scraperErr.ErrorOrNil() != nil {
-		errs.Add(scraperErr.ErrorOrNil())
+	if len(conf.Hooks) > 0 {
+		job.Hooks = output.NewHookRunner(conf)
+	}
+
+	if len(conf.Plugins) > 0 {
+		plugins, pluginErr := plugin.StartAll(conf.Plugins)
+		if pluginErr != nil {
+			errs.Add(pluginErr)
+		} else {
+			job.Plugins = plugins
+			if err = plugins.Setup(conf, job.Input); err != nil {
+				errs.Add(err)
+			}
+		}
+	}
+
+	if conf.Script != "" {
+		loaded, scriptErr := script.Load(conf.Script)
+		if scriptErr != nil {
+			errs.Add(scriptErr)
+		} else {
+			job.Script = loaded
+		}
+	}
+
+	if conf.Recursion && conf.RecursionStrategy == "content" {
+		if job.RecursionStatus, err = filter.NewStatusFilter(conf.RecursionStatus); err != nil {
+			errs.Add(fmt.Errorf("-recursion-status: %s", err))
+		}
+		for _, m := range conf.RecursionMatch {
+			name, value, _ := strings.Cut(m, ":")
+			matcher, matcherErr := filter.NewFilterByName(name, value)
+			if matcherErr != nil {
+				errs.Add(fmt.Errorf("-recursion-match %s: %s", m, matcherErr))
+				continue
+			}
+			job.RecursionMatchers = append(job.RecursionMatchers, matcher)
+		}
+	}
+
+	if embedded {
+		job.Scraper = &scraper.Scraper{Rules: make([]*scraper.ScraperRule, 0)}
+	} else {
+		newscraper, scraperErr := scraper.FromDir(ffuf.SCRAPERDIR, conf.Scrapers)
+		if scraperErr.ErrorOrNil() != nil {
+			errs.Add(scraperErr.ErrorOrNil())
+		}
+		job.Scraper = newscraper
 	}
-	job.Scraper = newscraper
 	if conf.ScraperFile != "" {
 		if err = job.Scraper.AppendFromFile(conf.ScraperFile); err != nil {
 			errs.Add(err)
@@ -0,0 +1,241 @@
+// Package batch runs the jobs of a -batch manifest (see ffuf.Batch) in one ffuf
+// process: Concurrency of them at a time, with their requests limited together
+// to the Rate of the batch. Each job writes its own output file to the Output
+// directory of the batch, or the results of all of them are merged into its
+// Output file.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/engine"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/output"
+)
+
+// The statuses of a job in its Summary, other than the reason it was stopped at
+const (
+	StatusFinished = "finished"
+	StatusStopped  = "stopped"
+	StatusSkipped  = "skipped"
+	StatusFailed   = "failed"
+)
+
+// Summary is how a job of the batch went
+type Summary struct {
+	Name     string
+	Url      string
+	Requests int64
+	Errors   int64
+	Matches  int64
+	Duration time.Duration
+	// Status is StatusFinished, the reason the job was stopped early by -sf,
+	// -se, -sa or -maxtime, StatusStopped for a job stopped with the batch,
+	// StatusSkipped for a job that was not started because the batch was
+	// stopped, or StatusFailed for a job that could not be started because of
+	// Err
+	Status string
+	Err    error
+}
+
+// Run runs the jobs of the batch and returns their summaries, in the order of
+// the jobs. Cancelling ctx stops the running jobs and skips the others. The
+// error is that of writing the merged output file.
+func Run(ctx context.Context, b *ffuf.Batch) ([]Summary, error) {
+	if !output.ValidFormat(b.OutputFormat) {
+		return nil, fmt.Errorf("unknown output file format of the batch: %s", b.OutputFormat)
+	}
+	if b.Output != "" && !b.Merge {
+		if err := os.MkdirAll(b.Output, 0750); err != nil {
+			return nil, err
+		}
+	}
+	var shared *engine.RateThrottle
+	if b.Rate > 0 {
+		shared = engine.NewRateThrottle(&ffuf.Config{Rate: int64(b.Rate)})
+		defer shared.RateLimiter.Stop()
+	}
+
+	summaries := make([]Summary, len(b.Jobs))
+	results := make([][]ffuf.Result, len(b.Jobs))
+	keywords := make([][]string, len(b.Jobs))
+	slots := make(chan bool, b.Concurrency)
+	var wg sync.WaitGroup
+	for i := range b.Jobs {
+		slots <- true
+		wg.Add(1)
+		go func(i int) {
+			defer func() { <-slots }()
+			defer wg.Done()
+			summaries[i], results[i], keywords[i] = runJob(ctx, b, b.Jobs[i], shared)
+		}(i)
+	}
+	wg.Wait()
+
+	if b.Merge {
+		return summaries, writeMerged(b, results, keywords)
+	}
+	return summaries, nil
+}
+
+func runJob(ctx context.Context, b *ffuf.Batch, bj ffuf.BatchJob, shared *engine.RateThrottle) (Summary, []ffuf.Result, []string) {
+	summary := Summary{Name: bj.Name, Url: bj.Options.HTTP.URL}
+	if ctx.Err() != nil {
+		summary.Status = StatusSkipped
+		return summary, nil, nil
+	}
+	fail := func(err error) (Summary, []ffuf.Result, []string) {
+		summary.Status = StatusFailed
+		summary.Err = err
+		return summary, nil, nil
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	conf, err := ffuf.ConfigFromOptions(bj.Options, jobCtx, cancel)
+	if err != nil {
+		return fail(err)
+	}
+	// The interactive console controls a single job, and the jobs of a batch run
+	// side by side
+	conf.Noninteractive = true
+	if b.Output != "" && !b.Merge && conf.OutputFile == "" {
+		conf.OutputFile = filepath.Join(b.Output, bj.Name)
+		if b.OutputFormat != "all" {
+			// The files of "all" are named by their format already
+			conf.OutputFile += "." + b.OutputFormat
+		}
+		conf.OutputFormat = b.OutputFormat
+	}
+	conf.MatcherManager, err = filter.FromConfig(conf.Options, filter.DefaultStatusMatcher(conf.Options))
+	if err != nil {
+		return fail(err)
+	}
+	job, err := assembly.BuildJob(conf)
+	if err != nil {
+		closeJob(job)
+		return fail(err)
+	}
+	job.SharedRate = shared
+	if job.Metrics == nil {
+		job.Metrics = engine.NewMetrics()
+	}
+	stdout, _ := job.Output.(*output.Stdoutput)
+	if b.Concurrency > 1 {
+		job.Output = &jobOutput{OutputProvider: job.Output, name: bj.Name, url: conf.Url}
+	}
+
+	start := time.Now()
+	job.Start()
+	closeJob(job)
+	summary.Duration = time.Since(start)
+	summary.Requests, summary.Errors, summary.Matches = job.Metrics.Totals()
+	summary.Status = StatusFinished
+	if reason := job.StopReason(); reason != "" {
+		summary.Status = reason
+	} else if ctx.Err() != nil {
+		summary.Status = StatusStopped
+	}
+
+	var results []ffuf.Result
+	if b.Merge && stdout != nil {
+		results = stdout.AllResults()
+	}
+	kws := make([]string, 0, len(conf.InputProviders))
+	for _, ip := range conf.InputProviders {
+		kws = append(kws, ip.Keyword)
+	}
+	return summary, results, kws
+}
+
+// closeJob waits for the hooks of the job, stops its plugins and closes its
+// audit log
+func closeJob(job *engine.Job) {
+	if job == nil {
+		return
+	}
+	if job.Hooks != nil {
+		job.Hooks.Close()
+	}
+	if job.Plugins != nil {
+		job.Plugins.Close()
+	}
+	if job.AuditLogger != nil {
+		job.AuditLogger.Close()
+	}
+}
+
+// writeMerged writes the results of all the jobs to the output file of the batch
+func writeMerged(b *ffuf.Batch, results [][]ffuf.Result, keywords [][]string) error {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.CommandLine = strings.Join(os.Args, " ")
+	conf.OutputFile = b.Output
+	conf.OutputFormat = b.OutputFormat
+	seen := make(map[string]bool)
+	for _, kws := range keywords {
+		for _, kw := range kws {
+			if !seen[kw] {
+				seen[kw] = true
+				conf.InputProviders = append(conf.InputProviders, ffuf.InputProviderConfig{Keyword: kw})
+			}
+		}
+	}
+	all := make([]ffuf.Result, 0)
+	for _, res := range results {
+		all = append(all, res...)
+	}
+	merged := output.NewStdoutput(&conf)
+	merged.SetCurrentResults(all)
+	return merged.SaveFile(b.Output, b.OutputFormat)
+}
+
+// jobOutput is the output of a job that runs side by side with other jobs of
+// the batch: its progress line would overwrite theirs, and its banner would be
+// mixed up with their results, so it announces the job in a line instead.
+type jobOutput struct {
+	ffuf.OutputProvider
+	name string
+	url  string
+}
+
+func (o *jobOutput) Banner() {
+	o.OutputProvider.Info(fmt.Sprintf("Starting job %s: %s", o.name, o.url))
+}
+
+func (o *jobOutput) Progress(ffuf.Progress) {}
+
+// SetRun passes the run of a job on to the wrapped output
+func (o *jobOutput) SetRun(hash string) {
+	if recorder, ok := o.OutputProvider.(ffuf.RunRecorder); ok {
+		recorder.SetRun(hash)
+	}
+}
+
+// SetResultObserver passes the observer of the results on to the wrapped output
+func (o *jobOutput) SetResultObserver(observe func(ffuf.Result)) {
+	if observer, ok := o.OutputProvider.(ffuf.ResultObserver); ok {
+		observer.SetResultObserver(observe)
+	}
+}
+
+// PrintSummary prints a table of the summaries of the jobs
+func PrintSummary(w io.Writer, summaries []Summary) {
+	fmt.Fprintf(w, ":: Batch summary\n")
+	fmt.Fprintf(w, "%-20s %8s %8s %8s %10s  %s\n", "Job", "Requests", "Matches", "Errors", "Duration", "Status")
+	for _, s := range summaries {
+		status := s.Status
+		if s.Err != nil {
+			status += ": " + strings.ReplaceAll(strings.TrimSpace(s.Err.Error()), "\n", " ")
+		}
+		fmt.Fprintf(w, "%-20s %8d %8d %8d %10s  %s\n", s.Name, s.Requests, s.Matches, s.Errors, s.Duration.Round(time.Millisecond), strings.TrimSpace(status))
+	}
+}
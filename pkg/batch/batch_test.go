@@ -0,0 +1,148 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// withConfigDir points the ffuf config directories at a temp dir: the jobs of a
+// batch write their history and read their scraper rules like any other job
+func withConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "scraper"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	origHistory, origScraper, origAutocalib := ffuf.HISTORYDIR, ffuf.SCRAPERDIR, ffuf.AUTOCALIBDIR
+	ffuf.HISTORYDIR = filepath.Join(dir, "history")
+	ffuf.SCRAPERDIR = filepath.Join(dir, "scraper")
+	ffuf.AUTOCALIBDIR = filepath.Join(dir, "autocalibration")
+	t.Cleanup(func() {
+		ffuf.HISTORYDIR, ffuf.SCRAPERDIR, ffuf.AUTOCALIBDIR = origHistory, origScraper, origAutocalib
+	})
+}
+
+func newBatch(t *testing.T, target *testtarget.Target) *ffuf.Batch {
+	wordlist := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := os.WriteFile(wordlist, []byte("200\n403\n404\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	newJob := func(name, path, status string) ffuf.BatchJob {
+		opts := ffuf.NewConfigOptions()
+		opts.HTTP.URL = target.URL + path
+		opts.Input.Wordlists = []string{wordlist}
+		opts.General.Quiet = true
+		opts.Matcher.Status = status
+		return ffuf.BatchJob{Name: name, Options: opts}
+	}
+	return &ffuf.Batch{
+		BatchOptions: ffuf.BatchOptions{Concurrency: 2, Rate: 1000, OutputFormat: "json"},
+		Jobs: []ffuf.BatchJob{
+			newJob("ok", "/status/FUZZ", "200"),
+			newJob("forbidden", "/status/FUZZ", "403,404"),
+			newJob("broken", "/status/FUZZ", "nope"),
+		},
+	}
+}
+
+func TestRunSeparateOutputs(t *testing.T) {
+	withConfigDir(t)
+	target := testtarget.New()
+	defer target.Close()
+	b := newBatch(t, target)
+	b.Output = filepath.Join(t.TempDir(), "results")
+
+	summaries, err := Run(context.Background(), b)
+	if err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+	if len(summaries) != 3 {
+		t.Fatalf("got %d summaries, want 3", len(summaries))
+	}
+	ok, forbidden, broken := summaries[0], summaries[1], summaries[2]
+	if ok.Name != "ok" || ok.Status != StatusFinished || ok.Requests != 3 || ok.Matches != 1 {
+		t.Errorf("unexpected summary %+v", ok)
+	}
+	if forbidden.Status != StatusFinished || forbidden.Matches != 2 {
+		t.Errorf("unexpected summary %+v", forbidden)
+	}
+	if broken.Status != StatusFailed || broken.Err == nil {
+		t.Errorf("the job with a bad matcher did not fail: %+v", broken)
+	}
+	for name, want := range map[string]int{"ok": 1, "forbidden": 2} {
+		if got := len(readResults(t, filepath.Join(b.Output, name+".json"))); got != want {
+			t.Errorf("the output file of %s has %d results, want %d", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(b.Output, "broken.json")); err == nil {
+		t.Errorf("the job that failed wrote an output file")
+	}
+
+	var buf bytes.Buffer
+	PrintSummary(&buf, summaries)
+	if !strings.Contains(buf.String(), "forbidden") || !strings.Contains(buf.String(), "failed: ") {
+		t.Errorf("unexpected summary table:\n%s", buf.String())
+	}
+}
+
+func TestRunMerged(t *testing.T) {
+	withConfigDir(t)
+	target := testtarget.New()
+	defer target.Close()
+	b := newBatch(t, target)
+	b.Jobs = b.Jobs[:2]
+	b.Output = filepath.Join(t.TempDir(), "merged.json")
+	b.Merge = true
+
+	if _, err := Run(context.Background(), b); err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+	statuses := make([]string, 0)
+	for _, res := range readResults(t, b.Output) {
+		statuses = append(statuses, res["input"].(map[string]interface{})["FUZZ"].(string))
+	}
+	sort.Strings(statuses)
+	if strings.Join(statuses, ",") != "200,403,404" {
+		t.Errorf("got merged results %v, want those of both jobs", statuses)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	withConfigDir(t)
+	target := testtarget.New()
+	defer target.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summaries, err := Run(ctx, newBatch(t, target))
+	if err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+	for _, s := range summaries {
+		if s.Status != StatusSkipped || s.Requests != 0 {
+			t.Errorf("the job %s of a cancelled batch was not skipped: %+v", s.Name, s)
+		}
+	}
+}
+
+func readResults(t *testing.T, path string) []map[string]interface{} {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %s", path, err)
+	}
+	var doc struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("parsing %s: %s", path, err)
+	}
+	return doc.Results
+}
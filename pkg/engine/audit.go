@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// Reprocess runs the -script, matchers and filters of the job over a response
+// read back from an audit log, and reports it like a live response if it
+// matches. With replay, the request of a matching response is sent to the target
+// again and the live response is matched and reported instead. A matching
+// request is also sent through the -replay-proxy, if any. Reprocess is safe to
+// call from several goroutines.
+func (j *Job) Reprocess(resp ffuf.Response, replay bool) {
+	j.incCounter()
+	if resp.Request == nil {
+		return
+	}
+	if resp.ScraperData == nil {
+		resp.ScraperData = make(map[string][]string)
+	}
+	if !j.evaluate(&resp) {
+		return
+	}
+	if replay {
+		j.waitRate()
+		req := ffuf.CopyRequest(resp.Request)
+		live, err := j.Runner.Execute(&req)
+		if err != nil {
+			j.incError()
+			j.Output.Error(fmt.Sprintf("Encountered an error while replaying %s: %s\n", req.Url, err))
+			log.Printf("%s", err)
+			return
+		}
+		if !j.evaluate(&live) {
+			return
+		}
+		resp = live
+	}
+	if j.ReplayRunner != nil {
+		req := ffuf.CopyRequest(resp.Request)
+		_, _ = j.ReplayRunner.Execute(&req)
+	}
+	j.Output.Result(resp)
+}
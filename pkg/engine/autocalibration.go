@@ -25,6 +25,12 @@ func (j *Job) autoCalibrationStrings() map[string][]string {
 
 	for _, strategy := range j.Config.AutoCalibrationStrategies {
 		jsonStrategy, err := os.ReadFile(filepath.Join(ffuf.AUTOCALIBDIR, strategy+".json"))
+		if builtin, ok := ffuf.DefaultAutocalibrationStrategies()[strategy]; ok && os.IsNotExist(err) {
+			// Without the config directory, as in the pkg/scan API, the built-in
+			// strategies are used as they are
+			cInputs = mergeMaps(cInputs, builtin)
+			continue
+		}
 		if err != nil {
 			j.Output.Warning(fmt.Sprintf("Skipping strategy \"%s\" because of error: %s\n", strategy, err))
 			continue
@@ -65,7 +71,18 @@ func mergeMaps(m1 map[string][]string, m2 map[string][]string) map[string][]stri
 
 func (j *Job) calibrationRequest(inputs map[string][]byte) (ffuf.Response, error) {
 	basereq := ffuf.BaseRequest(j.Config)
-	req, err := j.Runner.Prepare(inputs, &basereq)
+	var req ffuf.Request
+	var err error
+	if batcher, ok := j.Runner.(ffuf.BatchRunnerProvider); ok && j.Config.ParamMine != "" && batcher.BatchSize() > 1 {
+		// Parameter mining compares whole batches to the baseline, so calibrate
+		// with a batch of as many random names
+		req, err = batcher.PrepareBatch(j.calibrationBatch(inputs, batcher.BatchSize()), &basereq)
+	} else {
+		req, err = j.Runner.Prepare(inputs, &basereq)
+	}
+	if err == nil {
+		err = j.mutateRequest(&req)
+	}
 	if err != nil {
 		j.Output.Error(fmt.Sprintf("Encountered an error while preparing autocalibration request: %s\n", err))
 		j.incError()
@@ -86,6 +103,21 @@ func (j *Job) calibrationRequest(inputs map[string][]byte) (ffuf.Response, error
 	return resp, fmt.Errorf("Response wouldn't be matched")
 }
 
+// calibrationBatch returns size inputs: the calibration input itself, followed by
+// copies of it with the calibration string suffixed with random characters.
+func (j *Job) calibrationBatch(inputs map[string][]byte, size int) []map[string][]byte {
+	batch := []map[string][]byte{inputs}
+	for len(batch) < size {
+		input := make(map[string][]byte, len(inputs))
+		for k, v := range inputs {
+			input[k] = v
+		}
+		input[j.Config.AutoCalibrationKeyword] = []byte(string(inputs[j.Config.AutoCalibrationKeyword]) + ffuf.RandomString(4))
+		batch = append(batch, input)
+	}
+	return batch
+}
+
 // CalibrateForHost runs autocalibration for a specific host
 func (j *Job) CalibrateForHost(host string, baseinput map[string][]byte) error {
 	if j.Config.MatcherManager.CalibratedForDomain(host) {
@@ -101,6 +101,18 @@ func TestAutoCalibrationStrings(t *testing.T) {
 		t.Errorf("Expected missing strategy to be skipped, but got %v", cInputs)
 	}
 
+	// Verify that the built-in strategies are used without their files
+	job = &Job{
+		Config: &ffuf.Config{
+			AutoCalibrationStrategies: []string{"basic"},
+		},
+		Output: NewNullOutput(),
+	}
+	cInputs = job.autoCalibrationStrings()
+	if len(cInputs["basic_admin"]) != 2 || len(cInputs["basic_random"]) != 2 {
+		t.Errorf("Expected the built-in basic strategy, but got %v", cInputs)
+	}
+
 	// Verify that a malformed strategy is skipped
 	malformedStrategy := []byte(`{"test": "foo"}`)
 	malformedFile := filepath.Join(tmpDir, "malformed.json")
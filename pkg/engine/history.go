@@ -20,18 +20,10 @@ type ConfigOptionsHistory struct {
 }
 
 func WriteHistoryEntry(conf *ffuf.Config) (string, error) {
-	if conf.Options == nil {
-		return "", errors.New("cannot write history entry: config has no source options")
-	}
-	options := ConfigOptionsHistory{
-		ConfigOptions: historyOptions(conf),
-		Time:          time.Now(),
-	}
-	jsonoptions, err := json.Marshal(options)
+	hashstr, jsonoptions, err := historyEntry(conf)
 	if err != nil {
 		return "", err
 	}
-	hashstr := calculateHistoryHash(jsonoptions)
 	err = ffuf.CreateConfigDir(filepath.Join(ffuf.HISTORYDIR, hashstr))
 	if err != nil {
 		return "", err
@@ -40,6 +32,22 @@ func WriteHistoryEntry(conf *ffuf.Config) (string, error) {
 	return hashstr, err
 }
 
+// historyEntry returns the FFUFHASH history entry of a job and its hash
+func historyEntry(conf *ffuf.Config) (string, []byte, error) {
+	if conf.Options == nil {
+		return "", nil, errors.New("cannot write history entry: config has no source options")
+	}
+	options := ConfigOptionsHistory{
+		ConfigOptions: historyOptions(conf),
+		Time:          time.Now(),
+	}
+	jsonoptions, err := json.Marshal(options)
+	if err != nil {
+		return "", nil, err
+	}
+	return calculateHistoryHash(jsonoptions), jsonoptions, nil
+}
+
 // historyOptions projects the Config's retained source options into the form
 // persisted for FFUFHASH history. Most fields ride the retained snapshot
 // unchanged, but fields the engine mutates AFTER parsing must be refreshed from
@@ -3,6 +3,9 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ffuf/ffuf/v2/pkg/ffuf"
@@ -87,6 +90,8 @@ func (m *fakeMatcherManager) AddFilter(string, string, bool) error
 func (m *fakeMatcherManager) AddPerDomainFilter(string, string, string) error        { return nil }
 func (m *fakeMatcherManager) RemoveFilter(string)                                    {}
 func (m *fakeMatcherManager) AddMatcher(string, string) error                        { return nil }
+func (m *fakeMatcherManager) AddFilterProvider(string, ffuf.FilterProvider)          {}
+func (m *fakeMatcherManager) AddMatcherProvider(string, ffuf.FilterProvider)         {}
 func (m *fakeMatcherManager) GetFilters() map[string]ffuf.FilterProvider             { return m.filters }
 func (m *fakeMatcherManager) GetMatchers() map[string]ffuf.FilterProvider            { return m.matchers }
 func (m *fakeMatcherManager) FiltersForDomain(string) map[string]ffuf.FilterProvider { return nil }
@@ -128,3 +133,35 @@ func TestHistoryOptions_ReflectsRuntimeFilters(t *testing.T) {
 		t.Errorf("history Filter.Size = %q, want the runtime-installed %q", got.Filter.Size, "4242")
 	}
 }
+
+// TestWriteHistoryEntry_OmitsApiToken checks the bearer token of the control API
+// is not written to the history entry, which FFUFHASH is calculated from.
+func TestWriteHistoryEntry_OmitsApiToken(t *testing.T) {
+	historydir := ffuf.HISTORYDIR
+	ffuf.HISTORYDIR = t.TempDir()
+	defer func() { ffuf.HISTORYDIR = historydir }()
+
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/FUZZ"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.General.Api = "127.0.0.1:0"
+	opts.General.ApiToken = "s3cr3t-token"
+	conf, err := ffuf.ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.ApiToken != "s3cr3t-token" {
+		t.Fatalf("the API token was not set in the config")
+	}
+	hash, err := WriteHistoryEntry(conf)
+	if err != nil {
+		t.Fatalf("WriteHistoryEntry: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(ffuf.HISTORYDIR, hash, "options"))
+	if err != nil {
+		t.Fatalf("read history entry: %v", err)
+	}
+	if strings.Contains(string(data), "s3cr3t-token") {
+		t.Errorf("the history entry contains the API token: %s", data)
+	}
+}
@@ -0,0 +1,25 @@
+package engine
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestInterruptMonitor_Stops checks that stopping the interrupt monitor ends
+// its goroutine, so that the jobs of a -batch do not pile them up.
+func TestInterruptMonitor_Stops(t *testing.T) {
+	j := &Job{Output: NewNullOutput()}
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		stop := j.interruptMonitor()
+		stop()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before+5 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left running after stopping 50 monitors, had %d before", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -40,6 +40,20 @@ type Job struct {
 	Jobhash      string
 	Total        int
 	Rate         *RateThrottle
+	Metrics      *Metrics
+	Hooks        ffuf.HookProvider
+	Plugins      ffuf.PluginProvider
+	Script       ffuf.ScriptProvider
+	// RecursionStatus and RecursionMatchers are the -recursion-status and the
+	// -recursion-match matchers of the "content" recursion strategy
+	RecursionStatus   ffuf.FilterProvider
+	RecursionMatchers []ffuf.FilterProvider
+	// SharedRate, if set, limits the requests of the job together with those
+	// of the other jobs it is shared with, like the jobs of a -batch
+	SharedRate *RateThrottle
+	// Embedded is set for the jobs run by the pkg/scan API. They write no
+	// FFUFHASH history entries and leave SIGINT and SIGTERM to the program.
+	Embedded bool
 
 	queue     *jobQueue
 	recursion *recursionManager
@@ -47,10 +61,11 @@ type Job struct {
 	startTime    time.Time
 	startTimeJob time.Time
 	errorMsg     string
-	paused       bool // guarded by pauseStateMutex
+	stopReason   string // guarded by errMutex
+	paused       bool   // guarded by pauseStateMutex
 
 	timeMutex       sync.Mutex   // guards startTime and startTimeJob
-	errMutex        sync.Mutex   // guards errorMsg
+	errMutex        sync.Mutex   // guards errorMsg and stopReason
 	inputMutex      sync.Mutex   // serializes main-loop input iteration vs interactive restart Reset
 	calibMutex      sync.Mutex   // serializes autocalibration
 	pauseStateMutex sync.Mutex   // makes the pause-flag flip and the pauseMutex Lock/Unlock one atomic step
@@ -58,9 +73,16 @@ type Job struct {
 }
 
 type QueueJob struct {
-	Url   string
-	depth int
-	req   ffuf.Request
+	Url string
+	// Priority is set from the interactive console. The jobs with a higher
+	// priority are taken from the queue first, whatever its order.
+	Priority int
+	depth    int
+	req      ffuf.Request
+	// score is that of the response the job was queued for, parentMatches
+	// counts the matches of the job it was found in
+	score         int
+	parentMatches *int64
 }
 
 // jobContext carries the per-queue-job values a worker needs, passed BY VALUE so
@@ -68,20 +90,26 @@ type QueueJob struct {
 // immutable base request for the job; depth is its recursion depth. This is what
 // makes the recursion-depth and base-request reads correct by construction rather
 // than by drain timing.
+//
+// matches counts the matches of the queue job. The pointer is shared with the
+// recursion jobs queued from it, which include the count in their score.
 type jobContext struct {
 	basereq ffuf.Request
 	depth   int
+	matches *int64
+	// target is the URL and the Host header of the queue job, as shown to the user
+	target string
 }
 
 func NewJob(conf *ffuf.Config) *Job {
 	var j Job
 	j.Config = conf
-	j.queue = newJobQueue()
+	j.queue = newJobQueue(conf.RecursionOrder)
 	j.Rate = NewRateThrottle(conf)
 	// Let the runner meter preflight/postflight requests against the same rate
 	// limiter as the main dispatch loop, so -rate/-p bound total outgoing volume
 	// rather than only the fuzzing requests.
-	conf.RateLimitFunc = func() { <-j.Rate.RateLimiter.C }
+	conf.RateLimitFunc = j.waitRate
 	return &j
 }
 
@@ -156,6 +184,12 @@ func (j *Job) DeleteQueueItem(index int) {
 	j.queue.removeAt(index)
 }
 
+// SetQueuePriority sets the priority of a queued recursion job by its index in
+// the slice. The active job at index 0 cannot be reprioritized.
+func (j *Job) SetQueuePriority(index int, priority int) bool {
+	return j.queue.setPriority(index, priority)
+}
+
 // QueuedJobs returns the slice of queued recursive jobs
 func (j *Job) QueuedJobs() []QueueJob {
 	return j.queue.remaining()
@@ -169,6 +203,9 @@ func (j *Job) Start() {
 	// Output is wired by the assembly before Start, so the recursion manager can
 	// capture it here.
 	j.recursion = newRecursionManager(j.Config, j.queue, j.Output)
+	j.recursion.hooks = j.Hooks
+	j.recursion.status = j.RecursionStatus
+	j.recursion.matchers = j.RecursionMatchers
 
 	basereq := ffuf.BaseRequest(j.Config)
 
@@ -176,12 +213,19 @@ func (j *Job) Start() {
 		// process multiple payload locations and create a queue job for each location
 		reqs := ffuf.SniperRequests(&basereq, j.Config.InputProviders[0].Template)
 		for _, r := range reqs {
-			j.queue.push(QueueJob{Url: j.Config.Url, depth: 0, req: r})
+			j.recursion.push(QueueJob{Url: j.Config.Url, depth: 0, req: r})
+		}
+		j.Total = j.Input.Total() * len(reqs)
+	} else if j.Config.InputMode == "auto" {
+		// create a queue job for each injection point found in the request
+		reqs := ffuf.AutoInjectionRequests(&basereq)
+		for _, r := range reqs {
+			j.recursion.push(QueueJob{Url: j.Config.Url, depth: 0, req: r})
 		}
 		j.Total = j.Input.Total() * len(reqs)
 	} else {
 		// Add the default job to job queue
-		j.queue.push(QueueJob{Url: j.Config.Url, depth: 0, req: ffuf.BaseRequest(j.Config)})
+		j.recursion.push(QueueJob{Url: j.Config.Url, depth: 0, req: ffuf.BaseRequest(j.Config)})
 		j.Total = j.Input.Total()
 	}
 
@@ -194,12 +238,16 @@ func (j *Job) Start() {
 		j.Output.Banner()
 	}
 	// Monitor for SIGTERM and do cleanup properly (writing the output files etc)
-	j.interruptMonitor()
+	if !j.Embedded {
+		stopMonitor := j.interruptMonitor()
+		defer stopMonitor()
+	}
 	for j.jobsInQueue() {
 		ctx := j.prepareQueueJob()
 		j.Reset(true)
 		j.setRunningJob(true)
 		j.startExecution(ctx)
+		fireHook(j.Hooks, ffuf.HookJobFinished, j.Config.Url, "", nil)
 	}
 
 	err := j.Output.Finalize()
@@ -208,6 +256,14 @@ func (j *Job) Start() {
 	}
 }
 
+// waitRate blocks until the rate limiters of the job allow another request
+func (j *Job) waitRate() {
+	<-j.Rate.RateLimiter.C
+	if j.SharedRate != nil {
+		<-j.SharedRate.RateLimiter.C
+	}
+}
+
 // Reset resets the counters and wordlist position for a job
 func (j *Job) Reset(cycle bool) {
 	// inputMutex serializes this against the main loop's Input iteration, so an
@@ -236,19 +292,42 @@ func (j *Job) prepareQueueJob() jobContext {
 	// (FFUFHASH) and the queued-job banner serialize the current target. Workers
 	// never read it; they use the immutable jobContext returned from here.
 	j.Config.Url = job.Url
+	if dip, ok := j.Input.(ffuf.DepthInputProvider); ok {
+		dip.SetDepth(job.depth)
+	}
 
 	//Find all keywords present in new queued job
 	kws := j.Input.Keywords()
 	found_kws := make([]string, 0)
 	for _, k := range kws {
-		if ffuf.RequestContainsKeyword(job.req, k) {
+		// The -graphql query and -F parts are only turned into the body by the
+		// runner, and the -param-mine runner adds the inputs to the request itself
+		byRunner := ffuf.BodyTemplateContainsKeyword(j.Config, k) || j.Config.ParamMine != ""
+		if ffuf.RequestContainsKeyword(job.req, k) || byRunner {
 			found_kws = append(found_kws, k)
 		}
 	}
 	//And activate / disable inputproviders as needed
 	j.Input.ActivateKeywords(found_kws)
-	j.Jobhash, _ = WriteHistoryEntry(j.Config)
-	return jobContext{basereq: job.req, depth: job.depth}
+	if j.Embedded {
+		// The FFUFHASH values still identify the job, it just cannot be searched for
+		j.Jobhash, _, _ = historyEntry(j.Config)
+	} else {
+		j.Jobhash, _ = WriteHistoryEntry(j.Config)
+	}
+	if recorder, ok := j.Output.(ffuf.RunRecorder); ok {
+		recorder.SetRun(j.Jobhash)
+	}
+	if j.AuditLogger != nil {
+		j.auditWrite(&ffuf.AuditHeader{
+			Version:    ffuf.Version(),
+			ConfigHash: j.Jobhash,
+			Url:        j.Config.Url,
+			Time:       time.Now(),
+		}, "header")
+	}
+	fireHook(j.Hooks, ffuf.HookJobStarted, j.Config.Url, "", nil)
+	return jobContext{basereq: job.req, depth: job.depth, matches: new(int64), target: job.Target()}
 }
 
 // SkipQueue allows to skip the current job and advance to the next queued recursion job
@@ -310,6 +389,13 @@ func (j *Job) Resume() {
 	}
 }
 
+// Paused reports whether the job is paused
+func (j *Job) Paused() bool {
+	j.pauseStateMutex.Lock()
+	defer j.pauseStateMutex.Unlock()
+	return j.paused
+}
+
 // pauseCheckpoint blocks while the job is paused and returns immediately
 // otherwise. Acquiring and releasing the read lock is a cheap speed bump when no
 // pause is in effect.
@@ -326,82 +412,119 @@ func (j *Job) startExecution(ctx jobContext) {
 
 	// Print the base URL when starting a new recursion or sniper queue job
 	if j.queue.position() > 1 {
-		if j.Config.InputMode == "sniper" {
-			j.Output.Info(fmt.Sprintf("Starting queued sniper job (%d of %d) on target: %s", j.queue.position(), j.queue.total(), j.Config.Url))
+		if j.Config.InputMode == "sniper" || j.Config.InputMode == "auto" {
+			j.Output.Info(fmt.Sprintf("Starting queued %s job (%d of %d) on target: %s", j.Config.InputMode, j.queue.position(), j.queue.total(), ctx.target))
 		} else {
-			j.Output.Info(fmt.Sprintf("Starting queued job on target: %s", j.Config.Url))
+			j.Output.Info(fmt.Sprintf("Starting queued job on target: %s", ctx.target))
 		}
 	}
 
 	//Limiter blocks after reaching the buffer, ensuring limited concurrency
 	threadlimiter := make(chan bool, j.Config.Threads)
 
+	// A batching runner (-graphql-batch) sends several inputs in one request
+	batcher, _ := j.Runner.(ffuf.BatchRunnerProvider)
+	if batcher != nil && batcher.BatchSize() < 2 {
+		batcher = nil
+	}
+
 	for {
-		// Advancing the input cursor is done under inputMutex so an interactive
-		// restart (which calls Input.Reset) cannot race it.
-		j.inputMutex.Lock()
-		hasNext := j.Input.Next()
-		j.inputMutex.Unlock()
-		if !hasNext || j.isSkipQueue() {
-			break
-		}
+		for {
+			// Advancing the input cursor is done under inputMutex so an interactive
+			// restart (which calls Input.Reset) cannot race it.
+			j.inputMutex.Lock()
+			hasNext := j.Input.Next()
+			j.inputMutex.Unlock()
+			if !hasNext || j.isSkipQueue() {
+				break
+			}
 
-		// Check if we should stop the process
-		j.CheckStop()
+			// Check if we should stop the process
+			j.CheckStop()
 
-		if !j.isRunning() {
-			defer j.Output.Warning(j.getError())
-			break
+			if !j.isRunning() {
+				defer j.Output.Warning(j.getError())
+				break
+			}
+			j.pauseCheckpoint()
+			// Handle the rate & thread limiting
+			threadlimiter <- true
+			// Ratelimiter handles the rate ticker
+			j.waitRate()
+
+			j.inputMutex.Lock()
+			nextInput := j.Input.Value()
+			nextPosition := j.Input.Position()
+			var batchInputs []map[string][]byte
+			var batchPositions []int
+			if batcher != nil {
+				batchInputs, batchPositions = j.nextBatch(batcher.BatchSize(), nextInput, nextPosition)
+			}
+			j.inputMutex.Unlock()
+			// Add FFUFHASH and its value
+			nextInput["FFUFHASH"] = j.ffufHash(nextPosition)
+
+			wg.Add(1)
+			j.incCounter()
+
+			go func() {
+				defer func() { <-threadlimiter }()
+				defer wg.Done()
+				threadStart := time.Now()
+				if batchInputs != nil && j.Config.ParamMine != "" {
+					j.runMiningTask(ctx, batcher, batchInputs, batchPositions, true)
+				} else if batchInputs != nil {
+					j.runBatchTask(ctx, batcher, batchInputs, batchPositions, false)
+				} else {
+					j.runTask(ctx, nextInput, nextPosition, false)
+				}
+				j.sleepIfNeeded()
+				threadEnd := time.Now()
+				j.Rate.Tick(threadStart, threadEnd)
+			}()
+			if !j.isRunningJob() {
+				defer j.Output.Warning(j.getError())
+				// break, not return: fall through to wg.Wait() so the in-flight
+				// workers finish before Start() advances to the next queue job, which
+				// rewrites Config.Url / currentDepth / keyword-active state that those
+				// workers still read (the -maxtime-job drain race).
+				break
+			}
 		}
-		j.pauseCheckpoint()
-		// Handle the rate & thread limiting
-		threadlimiter <- true
-		// Ratelimiter handles the rate ticker
-		<-j.Rate.RateLimiter.C
-
-		j.inputMutex.Lock()
-		nextInput := j.Input.Value()
-		nextPosition := j.Input.Position()
-		j.inputMutex.Unlock()
-		// Add FFUFHASH and its value
-		nextInput["FFUFHASH"] = j.ffufHash(nextPosition)
-
-		wg.Add(1)
-		j.incCounter()
-
-		go func() {
-			defer func() { <-threadlimiter }()
-			defer wg.Done()
-			threadStart := time.Now()
-			j.runTask(ctx, nextInput, nextPosition, false)
-			j.sleepIfNeeded()
-			threadEnd := time.Now()
-			j.Rate.Tick(threadStart, threadEnd)
-		}()
-		if !j.isRunningJob() {
-			defer j.Output.Warning(j.getError())
-			// break, not return: fall through to wg.Wait() so the in-flight
-			// workers finish before Start() advances to the next queue job, which
-			// rewrites Config.Url / currentDepth / keyword-active state that those
-			// workers still read (the -maxtime-job drain race).
+		wg.Wait()
+		// A growing input (-graphql-harvest) may have been extended by the requests
+		// that were still in flight when the inputs ran out: dispatch those as well.
+		if !j.isRunning() || !j.isRunningJob() || j.isSkipQueue() || !j.inputGrew() {
 			break
 		}
 	}
-	wg.Wait()
 	j.updateProgress()
 }
 
-func (j *Job) interruptMonitor() {
+// interruptMonitor stops the job on SIGINT and SIGTERM until the returned
+// function is called, so that the jobs of a -batch do not leave their signal
+// handling behind
+func (j *Job) interruptMonitor() func() {
 	sigChan := make(chan os.Signal, 2)
+	done := make(chan struct{})
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		for range sigChan {
-			j.setError("Caught keyboard interrupt (Ctrl-C)\n")
-			// Stop reopens the pause gate itself, so a worker blocked at a
-			// checkpoint wakes and observes the stop.
-			j.Stop()
+		for {
+			select {
+			case <-sigChan:
+				j.setError("Caught keyboard interrupt (Ctrl-C)\n")
+				// Stop reopens the pause gate itself, so a worker blocked at a
+				// checkpoint wakes and observes the stop.
+				j.Stop()
+			case <-done:
+				return
+			}
 		}
 	}()
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
 }
 
 func (j *Job) runBackgroundTasks(wg *sync.WaitGroup) {
@@ -455,6 +578,9 @@ func (j *Job) ffufHash(pos int) []byte {
 func (j *Job) runTask(ctx jobContext, input map[string][]byte, position int, retried bool) {
 	basereq := ctx.basereq
 	req, err := j.Runner.Prepare(input, &basereq)
+	if err == nil {
+		err = j.mutateRequest(&req)
+	}
 	req.Timestamp = time.Now()
 
 	req.Position = position
@@ -462,20 +588,19 @@ func (j *Job) runTask(ctx jobContext, input map[string][]byte, position int, ret
 		j.Output.Error(fmt.Sprintf("Encountered an error while preparing request: %s\n", err))
 		j.incError()
 		log.Printf("%s", err)
+		fireHook(j.Hooks, ffuf.HookError, basereq.Url, err.Error(), nil)
 		return
 	}
 
 	resp, err := j.Runner.Execute(&req)
+	j.Metrics.request(req.Host, resp, err)
 	if err != nil {
 		req.Error = err.Error()
 	}
 
 	// Audit the request after sending to the runner so we get any changes
-	if j.AuditLogger != nil {
-		e := j.AuditLogger.Write(&req)
-		if e != nil {
-			j.Output.Error(fmt.Sprintf("Encountered error while writing request audit log: %s\n", e))
-		}
+	if j.auditCaptures("all") || (err != nil && j.auditCaptures("errored")) {
+		j.auditWrite(&req, "request")
 	}
 
 	if err != nil {
@@ -488,6 +613,7 @@ func (j *Job) runTask(ctx jobContext, input map[string][]byte, position int, ret
 		}
 		j.incError()
 		log.Printf("%s", err)
+		fireHook(j.Hooks, ffuf.HookError, req.Url, err.Error(), nil)
 		if os.IsTimeout(err) {
 			for name := range j.Config.MatcherManager.GetMatchers() {
 				if name == "time" {
@@ -514,13 +640,151 @@ func (j *Job) runTask(ctx jobContext, input map[string][]byte, position int, ret
 	}
 
 	// audit the response after the error handling
-	if j.AuditLogger != nil {
-		err = j.AuditLogger.Write(&resp)
-		if err != nil {
-			j.Output.Error(fmt.Sprintf("Encountered error while writing response audit log: %s\n", err))
+	if j.auditCaptures("all") {
+		j.auditWrite(&resp, "response")
+	}
+
+	j.handleResponse(ctx, req, resp, input, position)
+}
+
+// auditCaptures reports whether the exchanges of a kind are written to the audit
+// log: "all" of them, unless -audit-log-only limits it to the "matched" and
+// "errored" ones.
+func (j *Job) auditCaptures(kind string) bool {
+	if j.AuditLogger == nil {
+		return false
+	}
+	if len(j.Config.AuditLogOnly) == 0 {
+		return kind == "all"
+	}
+	return ffuf.StrInSlice(kind, j.Config.AuditLogOnly)
+}
+
+func (j *Job) auditWrite(data interface{}, what string) {
+	if err := j.AuditLogger.Write(data); err != nil {
+		j.Output.Error(fmt.Sprintf("Encountered error while writing %s audit log: %s\n", what, err))
+	}
+}
+
+// inputGrew reports whether inputs were added after the main loop ran out of them.
+func (j *Job) inputGrew() bool {
+	j.inputMutex.Lock()
+	defer j.inputMutex.Unlock()
+	return j.Input.Position() < j.Input.Total()
+}
+
+// nextBatch collects up to size inputs for a batching runner, starting with the
+// one the main loop already advanced to. The caller holds inputMutex.
+func (j *Job) nextBatch(size int, first map[string][]byte, firstPosition int) ([]map[string][]byte, []int) {
+	inputs := []map[string][]byte{first}
+	positions := []int{firstPosition}
+	for len(inputs) < size && j.Input.Next() {
+		input := j.Input.Value()
+		position := j.Input.Position()
+		input["FFUFHASH"] = j.ffufHash(position)
+		inputs = append(inputs, input)
+		positions = append(positions, position)
+		// The counters and progress are kept in inputs, not requests
+		j.incCounter()
+	}
+	return inputs, positions
+}
+
+// runBatchTask sends a batch of inputs in a single request and handles the
+// per-input responses the runner splits it to like runTask handles a response.
+func (j *Job) runBatchTask(ctx jobContext, batcher ffuf.BatchRunnerProvider, inputs []map[string][]byte, positions []int, retried bool) {
+	resp, ok := j.sendBatch(ctx, batcher, inputs, positions, retried)
+	if !ok {
+		return
+	}
+	for i, split := range batcher.SplitBatch(resp, inputs) {
+		split.Request.Position = positions[i]
+		j.handleResponse(ctx, *split.Request, split, inputs[i], positions[i])
+	}
+}
+
+// sendBatch prepares and executes the request for a batch of inputs, retrying
+// once on error, and audits it. The second return value is false on failure.
+func (j *Job) sendBatch(ctx jobContext, batcher ffuf.BatchRunnerProvider, inputs []map[string][]byte, positions []int, retried bool) (ffuf.Response, bool) {
+	basereq := ctx.basereq
+	req, err := batcher.PrepareBatch(inputs, &basereq)
+	if err == nil {
+		err = j.mutateRequest(&req)
+	}
+	req.Timestamp = time.Now()
+
+	req.Position = positions[0]
+	if err != nil {
+		j.Output.Error(fmt.Sprintf("Encountered an error while preparing request: %s\n", err))
+		j.incError()
+		log.Printf("%s", err)
+		fireHook(j.Hooks, ffuf.HookError, basereq.Url, err.Error(), nil)
+		return ffuf.Response{}, false
+	}
+
+	resp, err := j.Runner.Execute(&req)
+	j.Metrics.request(req.Host, resp, err)
+	if err != nil {
+		req.Error = err.Error()
+	}
+
+	if j.auditCaptures("all") || (err != nil && j.auditCaptures("errored")) {
+		j.auditWrite(&req, "request")
+	}
+
+	if err != nil {
+		if !retried {
+			return j.sendBatch(ctx, batcher, inputs, positions, true)
+		}
+		j.incError()
+		log.Printf("%s", err)
+		fireHook(j.Hooks, ffuf.HookError, req.Url, err.Error(), nil)
+		return ffuf.Response{}, false
+	}
+
+	if j.auditCaptures("all") {
+		j.auditWrite(&resp, "response")
+	}
+
+	return resp, true
+}
+
+// runMiningTask sends a batch of candidate parameter names (-param-mine) and, if
+// the response differs from the autocalibrated baseline or the -script matches
+// it, bisects the batch until the names that make the difference are isolated.
+// The response of each of them is handled like runTask handles a response, and
+// reported as a result.
+func (j *Job) runMiningTask(ctx jobContext, batcher ffuf.BatchRunnerProvider, inputs []map[string][]byte, positions []int, first bool) {
+	if !first {
+		// The bisection requests are made on top of the one the main loop accounted for
+		j.pauseCheckpoint()
+		if !j.isRunning() || !j.isRunningJob() {
+			return
 		}
+		j.waitRate()
+	}
+	resp, ok := j.sendBatch(ctx, batcher, inputs, positions, false)
+	if !ok {
+		return
+	}
+	resp.Request.Position = positions[0]
+	if len(inputs) == 1 {
+		j.handleResponse(ctx, *resp.Request, resp, inputs[0], positions[0])
+		return
+	}
+	_ = j.CalibrateIfNeeded(ffuf.HostURLFromRequest(*resp.Request), inputs[0])
+	if !j.evaluate(&resp) {
+		return
 	}
+	half := len(inputs) / 2
+	j.runMiningTask(ctx, batcher, inputs[:half], positions[:half], false)
+	j.runMiningTask(ctx, batcher, inputs[half:], positions[half:], false)
+}
 
+// handleResponse runs a successful response through the stop counters,
+// autocalibration, scrapers, matchers and filters, the output and recursion.
+func (j *Job) handleResponse(ctx jobContext, req ffuf.Request, resp ffuf.Response, input map[string][]byte, position int) {
+	basereq := ctx.basereq
 	if j.getSpuriousErrorCounter() > 0 {
 		j.resetSpuriousErrors()
 	}
@@ -541,15 +805,24 @@ func (j *Job) runTask(ctx jobContext, input map[string][]byte, position int, ret
 	// Handle autocalibration, must be done after the actual request to ensure sane value in req.Host
 	_ = j.CalibrateIfNeeded(ffuf.HostURLFromRequest(req), input)
 
+	matched := j.evaluate(&resp)
+
 	// Handle scraper actions
 	if j.Scraper != nil {
-		for _, sres := range j.Scraper.Execute(&resp, j.isMatch(resp)) {
+		for _, sres := range j.Scraper.Execute(&resp, matched) {
 			resp.ScraperData[sres.Name] = sres.Results
 			j.handleScraperResult(&resp, sres)
 		}
 	}
 
-	if j.isMatch(resp) {
+	if matched {
+		j.Metrics.match(req.Host)
+		atomic.AddInt64(ctx.matches, 1)
+		// With -audit-log-only the exchange is only written once it is known to match
+		if !j.auditCaptures("all") && j.auditCaptures("matched") {
+			j.auditWrite(&req, "request")
+			j.auditWrite(&resp, "response")
+		}
 		// Re-send request through replay-proxy if needed
 		if j.ReplayRunner != nil {
 			replayreq, err := j.ReplayRunner.Prepare(input, &basereq)
@@ -563,12 +836,16 @@ func (j *Job) runTask(ctx jobContext, input map[string][]byte, position int, ret
 			}
 		}
 		j.Output.Result(resp)
+		fireHook(j.Hooks, ffuf.HookMatch, req.Url, "", &resp)
 
 		// Refresh the progress indicator as we printed something out
 		j.updateProgress()
 		if j.Config.Recursion && j.Config.RecursionStrategy == "greedy" {
 			j.recursion.handleGreedy(ctx, resp)
 		}
+		if j.Config.Recursion && j.Config.RecursionStrategy == "content" {
+			j.recursion.handleContent(ctx, resp)
+		}
 	} else {
 		if len(resp.ScraperData) > 0 {
 			// print the result anyway, as scraper found something
@@ -576,7 +853,9 @@ func (j *Job) runTask(ctx jobContext, input map[string][]byte, position int, ret
 		}
 	}
 
-	if j.Config.Recursion && j.Config.RecursionStrategy == "default" && len(resp.GetRedirectLocation(false)) > 0 {
+	// The content strategy recurses on the redirects of the default one as well
+	redirectStrategy := j.Config.RecursionStrategy == "default" || j.Config.RecursionStrategy == "content"
+	if j.Config.Recursion && redirectStrategy && len(resp.GetRedirectLocation(false)) > 0 {
 		j.recursion.handleDefault(ctx, resp)
 	}
 }
@@ -592,28 +871,31 @@ func (j *Job) handleScraperResult(resp *ffuf.Response, sres ffuf.ScraperResult)
 
 // CheckStop stops the job if stopping conditions are met
 func (j *Job) CheckStop() {
+	if j.Config.Context.Err() != nil {
+		// The context of a pkg/scan scan was cancelled, possibly before the job
+		// was running to be stopped
+		j.Stop()
+		return
+	}
 	counter := j.getCounter()
 	if counter > 50 {
 		// We have enough samples
 		if j.Config.StopOn403 || j.Config.StopOnAll {
 			if float64(j.getCount403())/float64(counter) > 0.95 {
 				// Over 95% of requests are 403
-				j.setError("Getting an unusual amount of 403 responses, exiting.")
-				j.Stop()
+				j.stopWith("Getting an unusual amount of 403 responses, exiting.")
 			}
 		}
 		if j.Config.StopOnErrors || j.Config.StopOnAll {
 			if j.getSpuriousErrorCounter() > j.Config.Threads*2 {
 				// Most of the requests are erroring
-				j.setError("Receiving spurious errors, exiting.")
-				j.Stop()
+				j.stopWith("Receiving spurious errors, exiting.")
 			}
 
 		}
 		if j.Config.StopOnAll && (float64(j.getCount429())/float64(counter) > 0.2) {
 			// Over 20% of responses are 429
-			j.setError("Getting an unusual amount of 429 responses, exiting.")
-			j.Stop()
+			j.stopWith("Getting an unusual amount of 429 responses, exiting.")
 		}
 	}
 
@@ -622,8 +904,7 @@ func (j *Job) CheckStop() {
 		dur := time.Since(j.getStartTime())
 		runningSecs := int(dur / time.Second)
 		if runningSecs >= j.Config.MaxTime {
-			j.setError("Maximum running time for entire process reached, exiting.")
-			j.Stop()
+			j.stopWith("Maximum running time for entire process reached, exiting.")
 		}
 	}
 
@@ -639,6 +920,69 @@ func (j *Job) CheckStop() {
 	}
 }
 
+// stopWith stops the job for a -sf, -se, -sa or -maxtime reason, running the
+// stopped hooks unless the job was stopped already
+func (j *Job) stopWith(reason string) {
+	j.setError(reason)
+	if j.isRunning() {
+		j.errMutex.Lock()
+		j.stopReason = reason
+		j.errMutex.Unlock()
+		fireHook(j.Hooks, ffuf.HookStopped, j.Config.Url, reason, nil)
+	}
+	j.Stop()
+}
+
+// StopReason returns the reason the job was stopped by -sf, -se, -sa or
+// -maxtime, or "" if it was not
+func (j *Job) StopReason() string {
+	j.errMutex.Lock()
+	defer j.errMutex.Unlock()
+	return j.stopReason
+}
+
+// fireHook runs the -hook commands and webhooks on an event, if there are any
+func fireHook(hooks ffuf.HookProvider, name string, url string, message string, resp *ffuf.Response) {
+	if hooks == nil {
+		return
+	}
+	hooks.Fire(ffuf.HookEvent{Name: name, Url: url, Message: message, Response: resp})
+}
+
+// mutateRequest lets the mutator plugins and then the on_request of the
+// -script modify a prepared request, if there are any
+func (j *Job) mutateRequest(req *ffuf.Request) error {
+	if j.Plugins != nil {
+		if err := j.Plugins.Mutate(req); err != nil {
+			return err
+		}
+	}
+	if j.Script != nil {
+		return j.Script.OnRequest(req)
+	}
+	return nil
+}
+
+// evaluate reports whether a response is matched: as the on_response of the
+// -script decides, or as the matchers and filters do if it does not
+func (j *Job) evaluate(resp *ffuf.Response) bool {
+	if j.Script != nil {
+		verdict, err := j.Script.OnResponse(resp)
+		if err != nil {
+			j.Output.Error(fmt.Sprintf("Encountered an error while evaluating response: %s\n", err))
+			j.incError()
+			log.Printf("%s", err)
+		}
+		switch verdict {
+		case ffuf.ScriptMatch:
+			return true
+		case ffuf.ScriptFilter:
+			return false
+		}
+	}
+	return j.isMatch(*resp)
+}
+
 // Stop the execution of the Job
 func (j *Job) Stop() {
 	j.setRunning(false)
@@ -1,6 +1,9 @@
 package engine
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 // jobQueue owns the recursion/sniper job list and the active position, together
 // with the mutex that guards them. Worker goroutines append to the queue (from
@@ -8,20 +11,61 @@ import "sync"
 // progress goroutine and the interactive handler read it, so the slice and the
 // position must never be touched outside these methods. The fields are private:
 // there is no way to append without the lock.
+//
+// The jobs before the position have been taken. The ones after it are pending,
+// and are put in the order of the queue (-recursion-order) when a job is
+// pushed, reprioritized or taken, but not when they are listed, so that the
+// offsets queueshow lists stay the ones queuedel and queueprio use. "breadth"
+// keeps them in the order they were pushed, "depth" takes the deepest job first
+// and "score" the job with the highest score. A job with a higher priority, set
+// from the interactive console, goes before all of them.
 type jobQueue struct {
-	mu   sync.Mutex
-	jobs []QueueJob
-	pos  int
+	mu    sync.Mutex
+	jobs  []QueueJob
+	pos   int
+	order string
 }
 
-func newJobQueue() *jobQueue {
-	return &jobQueue{jobs: make([]QueueJob, 0)}
+func newJobQueue(order string) *jobQueue {
+	return &jobQueue{jobs: make([]QueueJob, 0), order: order}
+}
+
+// sortPending puts the pending jobs in the order of the queue. The sort is
+// stable, so jobs that are equal in the order keep the order they were pushed
+// in. The scores are read once, the match counts they include may still grow.
+// The caller holds the lock.
+func (q *jobQueue) sortPending() {
+	type pendingJob struct {
+		job   QueueJob
+		score int
+	}
+	pending := make([]pendingJob, 0, len(q.jobs)-q.pos)
+	for _, job := range q.jobs[q.pos:] {
+		pending = append(pending, pendingJob{job: job, score: job.Score()})
+	}
+	sort.SliceStable(pending, func(i, k int) bool {
+		a, b := pending[i], pending[k]
+		if a.job.Priority != b.job.Priority {
+			return a.job.Priority > b.job.Priority
+		}
+		switch q.order {
+		case "depth":
+			return a.job.depth > b.job.depth
+		case "score":
+			return a.score > b.score
+		}
+		return false
+	})
+	for i, p := range pending {
+		q.jobs[q.pos+i] = p.job
+	}
 }
 
 // push appends a job to the queue.
 func (q *jobQueue) push(j QueueJob) {
 	q.mu.Lock()
 	q.jobs = append(q.jobs, j)
+	q.sortPending()
 	q.mu.Unlock()
 }
 
@@ -37,6 +81,7 @@ func (q *jobQueue) hasNext() bool {
 func (q *jobQueue) advance() (QueueJob, int) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	q.sortPending()
 	job := q.jobs[q.pos]
 	q.pos++
 	return job, q.pos
@@ -56,7 +101,8 @@ func (q *jobQueue) total() int {
 	return len(q.jobs)
 }
 
-// remaining returns a copy of the jobs from the active position onward.
+// remaining returns a copy of the jobs from the active position onward, the
+// pending ones in the order they are to be taken in.
 func (q *jobQueue) remaining() []QueueJob {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -82,3 +128,18 @@ func (q *jobQueue) removeAt(offset int) bool {
 	q.jobs = append(q.jobs[:idx], q.jobs[idx+1:]...)
 	return true
 }
+
+// setPriority sets the priority of the queued job at the given offset from the
+// active position, like removeAt. The active job cannot be reprioritized, it
+// has been taken already.
+func (q *jobQueue) setPriority(offset int, priority int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	idx := q.pos + offset - 1
+	if idx < q.pos || idx >= len(q.jobs) {
+		return false
+	}
+	q.jobs[idx].Priority = priority
+	q.sortPending()
+	return true
+}
@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// metricsLatencyBuckets are the upper bounds in seconds of the request duration
+// histogram buckets
+var metricsLatencyBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects the request metrics of a job for the -metrics endpoint,
+// labelled by the host the requests were sent to. A nil *Metrics collects
+// nothing.
+type Metrics struct {
+	hosts map[string]*hostMetrics
+	mutex sync.Mutex
+}
+
+type hostMetrics struct {
+	requests   int64
+	errors     int64
+	matches    int64
+	statuses   map[int64]int64
+	latency    []int64 // cumulative counts of metricsLatencyBuckets
+	latencySum float64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{hosts: make(map[string]*hostMetrics)}
+}
+
+func (m *Metrics) host(name string) *hostMetrics {
+	h, ok := m.hosts[name]
+	if !ok {
+		h = &hostMetrics{statuses: make(map[int64]int64), latency: make([]int64, len(metricsLatencyBuckets))}
+		m.hosts[name] = h
+	}
+	return h
+}
+
+// request records a request that was sent, and its response unless it failed
+func (m *Metrics) request(host string, resp ffuf.Response, err error) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	h := m.host(host)
+	h.requests++
+	if err != nil {
+		h.errors++
+		return
+	}
+	h.statuses[resp.StatusCode]++
+	seconds := resp.Duration.Seconds()
+	h.latencySum += seconds
+	for i, bound := range metricsLatencyBuckets {
+		if seconds <= bound {
+			h.latency[i]++
+		}
+	}
+}
+
+// match records a matched response
+func (m *Metrics) match(host string) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.host(host).matches++
+}
+
+// Totals returns the requests, failed requests and matches of all the hosts
+func (m *Metrics) Totals() (requests int64, errors int64, matches int64) {
+	if m == nil {
+		return 0, 0, 0
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, h := range m.hosts {
+		requests += h.requests
+		errors += h.errors
+		matches += h.matches
+	}
+	return requests, errors, matches
+}
+
+// WriteMetrics writes the metrics of the job in the Prometheus text format
+func (j *Job) WriteMetrics(w io.Writer) {
+	paused := 0
+	if j.Paused() {
+		paused = 1
+	}
+	gauge := func(name, help string, value interface{}) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+	}
+	gauge("ffuf_job_position", "Inputs of the current job processed so far.", j.getCounter())
+	gauge("ffuf_job_inputs", "Inputs of the current job.", j.Input.Total())
+	gauge("ffuf_job_errors", "Requests of the current job that failed after the retry.", j.getErrorCounter())
+	gauge("ffuf_queue_position", "Position of the current job in the job queue.", j.queue.position())
+	gauge("ffuf_queue_jobs", "Jobs in the job queue.", j.queue.total())
+	gauge("ffuf_rate", "Current rate of requests per second.", j.Rate.CurrentRate())
+	gauge("ffuf_rate_configured", "Configured rate of requests per second, 0 when unlimited.", j.Rate.CurrentConfiguredRate())
+	gauge("ffuf_paused", "Whether the job is paused.", paused)
+
+	m := j.Metrics
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	hosts := make([]string, 0, len(m.hosts))
+	for host := range m.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	counter := func(name, help string, value func(h *hostMetrics) int64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, host := range hosts {
+			fmt.Fprintf(w, "%s{host=\"%s\"} %d\n", name, metricsLabel(host), value(m.hosts[host]))
+		}
+	}
+	counter("ffuf_requests_total", "Requests sent, including the retries.", func(h *hostMetrics) int64 { return h.requests })
+	counter("ffuf_request_errors_total", "Requests that failed without a response.", func(h *hostMetrics) int64 { return h.errors })
+	counter("ffuf_matches_total", "Responses that matched.", func(h *hostMetrics) int64 { return h.matches })
+
+	fmt.Fprintf(w, "# HELP ffuf_responses_total Responses by status code, the 403 and 429 ones included.\n# TYPE ffuf_responses_total counter\n")
+	for _, host := range hosts {
+		h := m.hosts[host]
+		statuses := make([]int64, 0, len(h.statuses))
+		for status := range h.statuses {
+			statuses = append(statuses, status)
+		}
+		sort.Slice(statuses, func(a, b int) bool { return statuses[a] < statuses[b] })
+		for _, status := range statuses {
+			fmt.Fprintf(w, "ffuf_responses_total{host=\"%s\",status=\"%d\"} %d\n", metricsLabel(host), status, h.statuses[status])
+		}
+	}
+
+	fmt.Fprintf(w, "# HELP ffuf_request_duration_seconds Time from sending a request to reading its response.\n# TYPE ffuf_request_duration_seconds histogram\n")
+	for _, host := range hosts {
+		h := m.hosts[host]
+		label := metricsLabel(host)
+		var responses int64
+		for _, count := range h.statuses {
+			responses += count
+		}
+		for i, bound := range metricsLatencyBuckets {
+			fmt.Fprintf(w, "ffuf_request_duration_seconds_bucket{host=\"%s\",le=\"%g\"} %d\n", label, bound, h.latency[i])
+		}
+		fmt.Fprintf(w, "ffuf_request_duration_seconds_bucket{host=\"%s\",le=\"+Inf\"} %d\n", label, responses)
+		fmt.Fprintf(w, "ffuf_request_duration_seconds_sum{host=\"%s\"} %g\n", label, h.latencySum)
+		fmt.Fprintf(w, "ffuf_request_duration_seconds_count{host=\"%s\"} %d\n", label, responses)
+	}
+}
+
+// metricsLabel escapes a label value of the Prometheus text format
+func metricsLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// MetricsServer serves the metrics of a job at /metrics for -metrics
+type MetricsServer struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// ServeMetrics starts collecting the metrics of a job and serving them at
+// addr. It is called before the job starts.
+func ServeMetrics(job *Job, addr string) (*MetricsServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not start the -metrics listener: %s", err)
+	}
+	job.Metrics = NewMetrics()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		job.WriteMetrics(w)
+	})
+	s := &MetricsServer{listener: listener, server: &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}}
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return s, nil
+}
+
+// Addr returns the address the metrics are served at
+func (s *MetricsServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops serving the metrics, letting a scrape in progress finish
+func (s *MetricsServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
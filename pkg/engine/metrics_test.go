@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func TestMetricsRequest(t *testing.T) {
+	m := NewMetrics()
+	m.request("a.example", ffuf.Response{StatusCode: 200, Duration: 20 * time.Millisecond}, nil)
+	m.request("a.example", ffuf.Response{StatusCode: 403, Duration: 2 * time.Second}, nil)
+	m.request("a.example", ffuf.Response{}, errors.New("timeout"))
+	m.request("b.example", ffuf.Response{StatusCode: 200, Duration: time.Minute}, nil)
+	m.match("a.example")
+
+	a := m.hosts["a.example"]
+	if a.requests != 3 || a.errors != 1 || a.matches != 1 {
+		t.Errorf("Unexpected counters %+v", a)
+	}
+	if want := map[int64]int64{200: 1, 403: 1}; !reflect.DeepEqual(a.statuses, want) {
+		t.Errorf("Expected statuses %v, got %v", want, a.statuses)
+	}
+	// Cumulative counts of the 0.01, 0.025, ... 2.5, 5 and 10 second buckets
+	if want := []int64{0, 1, 1, 1, 1, 1, 1, 2, 2, 2}; !reflect.DeepEqual(a.latency, want) {
+		t.Errorf("Expected latency buckets %v, got %v", want, a.latency)
+	}
+	if b := m.hosts["b.example"]; b.latency[len(b.latency)-1] != 0 {
+		t.Errorf("Expected a request slower than the last bucket to only count in +Inf, got %v", b.latency)
+	}
+
+	// A nil *Metrics, when -metrics is not used, collects nothing
+	var disabled *Metrics
+	disabled.request("a.example", ffuf.Response{}, nil)
+	disabled.match("a.example")
+}
+
+func TestMetricsLabel(t *testing.T) {
+	if got := metricsLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("Unexpected escaped label %s", got)
+	}
+}
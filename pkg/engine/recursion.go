@@ -2,19 +2,45 @@ package engine
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
 
 	"github.com/ffuf/ffuf/v2/pkg/ffuf"
 )
 
+// interestingNames are the parts of directory names that make a recursion job
+// more promising for the "score" order of the queue
+var interestingNames = []string{
+	"admin", "api", "backup", "bak", "conf", "console", "debug", "dev", "internal",
+	"manage", "old", "priv", "secret", "staging", "test", "upload",
+}
+
+// directoryListing matches the directory listings of the common web servers
+var directoryListing = regexp.MustCompile(`(?i)<title>\s*(index of|directory listing for) /|\[to parent directory\]`)
+
+const (
+	// nameScore is added to the score of a directory with an interesting name
+	nameScore = 3
+	// maxParentMatchesScore caps what the matches of the parent job add to the
+	// score: a parent that matches everything is not that interesting
+	maxParentMatchesScore = 5
+)
+
 // recursionManager owns the recursion policy: deciding whether a matched response
 // should spawn a deeper queue job and enqueuing it. It was lifted out of Job so the
 // greedy/default recursion logic lives in one place and is testable without a full
 // engine. It holds only what it needs: the config (for the depth limit and to build
-// recursion requests), the queue to push onto, and the output for its messages.
+// recursion requests), the queue to push onto, the output for its messages, the
+// hooks run on the queued jobs and the status and matchers of the content strategy.
 type recursionManager struct {
-	config *ffuf.Config
-	queue  *jobQueue
-	output ffuf.OutputProvider
+	config   *ffuf.Config
+	queue    *jobQueue
+	output   ffuf.OutputProvider
+	hooks    ffuf.HookProvider
+	status   ffuf.FilterProvider
+	matchers []ffuf.FilterProvider
 }
 
 func newRecursionManager(conf *ffuf.Config, queue *jobQueue, output ffuf.OutputProvider) *recursionManager {
@@ -26,10 +52,7 @@ func newRecursionManager(conf *ffuf.Config, queue *jobQueue, output ffuf.OutputP
 // by the caller.
 func (r *recursionManager) handleGreedy(ctx jobContext, resp ffuf.Response) {
 	if r.config.RecursionDepth == 0 || ctx.depth < r.config.RecursionDepth {
-		recUrl := resp.Request.Url + "/" + "FUZZ"
-		newJob := QueueJob{Url: recUrl, depth: ctx.depth + 1, req: ffuf.RecursionRequest(r.config, recUrl)}
-		r.queue.push(newJob)
-		r.output.Info(fmt.Sprintf("Adding a new job to the queue: %s", recUrl))
+		r.queueJob(ctx, resp)
 	} else {
 		r.output.Warning(fmt.Sprintf("Maximum recursion depth reached. Ignoring: %s", resp.Request.Url))
 	}
@@ -39,17 +62,143 @@ func (r *recursionManager) handleGreedy(ctx jobContext, resp ffuf.Response) {
 // (default strategy: the response redirects to its own path with a trailing
 // slash), if the recursion depth allows.
 func (r *recursionManager) handleDefault(ctx jobContext, resp ffuf.Response) {
-	recUrl := resp.Request.Url + "/" + "FUZZ"
 	if (resp.Request.Url + "/") != resp.GetRedirectLocation(true) {
 		// Not a directory, return early
 		return
 	}
 	if r.config.RecursionDepth == 0 || ctx.depth < r.config.RecursionDepth {
 		// We have yet to reach the maximum recursion depth
-		newJob := QueueJob{Url: recUrl, depth: ctx.depth + 1, req: ffuf.RecursionRequest(r.config, recUrl)}
-		r.queue.push(newJob)
-		r.output.Info(fmt.Sprintf("Adding a new job to the queue: %s", recUrl))
+		r.queueJob(ctx, resp)
 	} else {
 		r.output.Warning(fmt.Sprintf("Directory found, but recursion depth exceeded. Ignoring: %s", resp.GetRedirectLocation(true)))
 	}
 }
+
+// handleContent queues a recursion job for a matched response that looks like a
+// directory by its content (content strategy), if the recursion depth allows.
+// The redirects of the default strategy are left to handleDefault, which the
+// content strategy runs as well.
+func (r *recursionManager) handleContent(ctx jobContext, resp ffuf.Response) {
+	if (resp.Request.Url + "/") == resp.GetRedirectLocation(true) {
+		return
+	}
+	if !r.looksLikeDirectory(resp) {
+		return
+	}
+	if r.config.RecursionDepth == 0 || ctx.depth < r.config.RecursionDepth {
+		r.queueJob(ctx, resp)
+	} else {
+		r.output.Warning(fmt.Sprintf("Directory found, but recursion depth exceeded. Ignoring: %s", resp.Request.Url))
+	}
+}
+
+// looksLikeDirectory reports whether a response with one of the -recursion-status
+// status codes is a directory listing, or is not named like a file and matches
+// all of the -recursion-match matchers.
+func (r *recursionManager) looksLikeDirectory(resp ffuf.Response) bool {
+	if r.status == nil {
+		return false
+	}
+	if match, _ := r.status.Filter(&resp); !match {
+		return false
+	}
+	if directoryListing.Match(resp.Data) {
+		return true
+	}
+	name := ffuf.RecursionName(r.config, resp.Request)
+	if strings.LastIndex(name, ".") > 0 {
+		// index.php, not .git
+		return false
+	}
+	for _, m := range r.matchers {
+		if match, _ := m.Filter(&resp); !match {
+			return false
+		}
+	}
+	return true
+}
+
+// queueJob queues the recursion job for a response of the queue job of ctx
+func (r *recursionManager) queueJob(ctx jobContext, resp ffuf.Response) {
+	job := r.newJob(ctx, resp)
+	r.push(job)
+	r.output.Info(fmt.Sprintf("Adding a new job to the queue: %s", job.Target()))
+}
+
+// newJob returns the recursion job for a response of the queue job of ctx
+func (r *recursionManager) newJob(ctx jobContext, resp ffuf.Response) QueueJob {
+	req := ffuf.RecursionRequest(r.config, &ctx.basereq, resp.Request)
+	return QueueJob{
+		Url:           req.Url,
+		depth:         ctx.depth + 1,
+		req:           req,
+		score:         responseScore(resp, ffuf.RecursionName(r.config, resp.Request)),
+		parentMatches: ctx.matches,
+	}
+}
+
+// responseScore scores a response a recursion job is queued for: by its status
+// code, the protected directories first, and by the name of the directory
+func responseScore(resp ffuf.Response, name string) int {
+	score := 0
+	switch {
+	case resp.StatusCode == 401 || resp.StatusCode == 403:
+		score += 3
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		score += 2
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		score += 1
+	}
+	name = strings.ToLower(name)
+	for _, interesting := range interestingNames {
+		if strings.Contains(name, interesting) {
+			score += nameScore
+			break
+		}
+	}
+	return score
+}
+
+// Score is how promising the job is, for the "score" order of the queue: the
+// score of the response it was queued for and the matches of its parent job
+func (q QueueJob) Score() int {
+	score := q.score
+	if q.parentMatches != nil {
+		matches := int(atomic.LoadInt64(q.parentMatches))
+		if matches > maxParentMatchesScore {
+			matches = maxParentMatchesScore
+		}
+		score += matches
+	}
+	return score
+}
+
+// Host returns the Host header of the job if it is not the host of its URL, as
+// with a host -recursion-template that is only in the header
+func (q QueueJob) Host() string {
+	for name, value := range q.req.Headers {
+		if !strings.EqualFold(name, "Host") {
+			continue
+		}
+		if u, err := url.Parse(q.Url); err == nil && strings.EqualFold(u.Host, value) {
+			return ""
+		}
+		return value
+	}
+	return ""
+}
+
+// Target returns the URL of the job, and its Host header if that tells the job
+// apart, for showing the job to the user
+func (q QueueJob) Target() string {
+	if host := q.Host(); host != "" {
+		return fmt.Sprintf("%s (Host: %s)", q.Url, host)
+	}
+	return q.Url
+}
+
+// push adds a job to the queue, running the job-queued hooks
+func (r *recursionManager) push(job QueueJob) {
+	r.queue.push(job)
+	fireHook(r.hooks, ffuf.HookJobQueued, job.Url, "", nil)
+}
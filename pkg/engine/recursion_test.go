@@ -1,13 +1,14 @@
 package engine
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ffuf/ffuf/v2/pkg/ffuf"
 )
 
 func TestRecursionManager_GreedyQueuesWithinDepth(t *testing.T) {
-	q := newJobQueue()
+	q := newJobQueue("breadth")
 	rm := newRecursionManager(&ffuf.Config{Method: "GET", RecursionDepth: 2}, q, NewNullOutput())
 	resp := ffuf.Response{Request: &ffuf.Request{Url: "http://x/admin"}}
 
@@ -26,7 +27,7 @@ func TestRecursionManager_GreedyQueuesWithinDepth(t *testing.T) {
 }
 
 func TestRecursionManager_GreedyRespectsMaxDepth(t *testing.T) {
-	q := newJobQueue()
+	q := newJobQueue("breadth")
 	rm := newRecursionManager(&ffuf.Config{Method: "GET", RecursionDepth: 1}, q, NewNullOutput())
 	resp := ffuf.Response{Request: &ffuf.Request{Url: "http://x/admin"}}
 
@@ -39,7 +40,7 @@ func TestRecursionManager_GreedyRespectsMaxDepth(t *testing.T) {
 }
 
 func TestRecursionManager_DefaultSkipsNonDirectory(t *testing.T) {
-	q := newJobQueue()
+	q := newJobQueue("breadth")
 	rm := newRecursionManager(&ffuf.Config{Method: "GET", RecursionDepth: 0}, q, NewNullOutput())
 
 	// A 200 with no redirect is not a directory, so the default strategy queues
@@ -53,7 +54,7 @@ func TestRecursionManager_DefaultSkipsNonDirectory(t *testing.T) {
 }
 
 func TestRecursionManager_DefaultQueuesDirectory(t *testing.T) {
-	q := newJobQueue()
+	q := newJobQueue("breadth")
 	rm := newRecursionManager(&ffuf.Config{Method: "GET", RecursionDepth: 0}, q, NewNullOutput())
 
 	// A 301 redirecting to url + "/" is a directory; the default strategy descends.
@@ -72,3 +73,268 @@ func TestRecursionManager_DefaultQueuesDirectory(t *testing.T) {
 		t.Errorf("queued url = %q, want http://x/dir/FUZZ", job.Url)
 	}
 }
+
+func TestRecursionManager_GreedyTemplates(t *testing.T) {
+	q := newJobQueue("breadth")
+	conf := &ffuf.Config{Method: "GET", RecursionTemplates: []ffuf.RecursionTemplate{
+		{Keyword: "FUZZ", Location: ffuf.RecursionPath, Separator: "/"},
+		{Keyword: "VHOST", Location: ffuf.RecursionHost, Separator: "."},
+	}}
+	rm := newRecursionManager(conf, q, NewNullOutput())
+	basereq := ffuf.Request{
+		Method:  "GET",
+		Url:     "http://x/api/FUZZ/users",
+		Headers: map[string]string{"Host": "VHOST.example.org"},
+	}
+	resp := ffuf.Response{Request: &ffuf.Request{
+		Url:   "http://x/api/v1/users",
+		Input: map[string][]byte{"FUZZ": []byte("v1"), "VHOST": []byte("dev"), "FFUFHASH": []byte("1")},
+	}}
+
+	rm.handleGreedy(jobContext{basereq: basereq, depth: 0}, resp)
+
+	job, _ := q.advance()
+	if job.Url != "http://x/api/v1/FUZZ/users" {
+		t.Errorf("queued url = %q, want http://x/api/v1/FUZZ/users", job.Url)
+	}
+	if job.req.Headers["Host"] != "VHOST.dev.example.org" {
+		t.Errorf("queued Host header = %q, want VHOST.dev.example.org", job.req.Headers["Host"])
+	}
+	if basereq.Headers["Host"] != "VHOST.example.org" {
+		t.Errorf("the base request of the parent job was changed")
+	}
+}
+
+func TestRecursionManager_HostTemplateTarget(t *testing.T) {
+	q := newJobQueue("breadth")
+	conf := &ffuf.Config{Method: "GET", RecursionTemplates: []ffuf.RecursionTemplate{
+		{Keyword: "VHOST", Location: ffuf.RecursionHost, Separator: "."},
+	}}
+	rm := newRecursionManager(conf, q, NewNullOutput())
+	basereq := ffuf.Request{
+		Method:  "GET",
+		Url:     "http://10.0.0.1/",
+		Headers: map[string]string{"Host": "VHOST.example.org"},
+	}
+	for _, value := range []string{"admin", "dev"} {
+		resp := ffuf.Response{Request: &ffuf.Request{
+			Url:   "http://10.0.0.1/",
+			Input: map[string][]byte{"VHOST": []byte(value), "FFUFHASH": []byte("1")},
+		}}
+		rm.handleGreedy(jobContext{basereq: basereq, depth: 0}, resp)
+	}
+
+	var targets []string
+	for q.hasNext() {
+		job, _ := q.advance()
+		targets = append(targets, job.Target())
+	}
+	// The URL is the same, the Host header tells the jobs apart
+	want := "http://10.0.0.1/ (Host: VHOST.admin.example.org),http://10.0.0.1/ (Host: VHOST.dev.example.org)"
+	if got := strings.Join(targets, ","); got != want {
+		t.Errorf("queued targets %s, want %s", got, want)
+	}
+	if job := (QueueJob{Url: "http://example.org/FUZZ", req: ffuf.Request{Headers: map[string]string{"host": "example.org"}}}); job.Target() != job.Url {
+		t.Errorf("a Host header of the URL host was shown: %s", job.Target())
+	}
+}
+
+// takeAll advances through the queue and returns the urls in the order taken
+func takeAll(q *jobQueue) []string {
+	urls := make([]string, 0)
+	for q.hasNext() {
+		job, _ := q.advance()
+		urls = append(urls, job.Url)
+	}
+	return urls
+}
+
+func TestJobQueue_DepthOrderFinishesSubdirectoriesFirst(t *testing.T) {
+	q := newJobQueue("depth")
+	q.push(QueueJob{Url: "/a", depth: 1})
+	q.push(QueueJob{Url: "/b", depth: 1})
+	if job, _ := q.advance(); job.Url != "/a" {
+		t.Fatalf("took %s first, want /a", job.Url)
+	}
+	// /a is running, and finds two subdirectories of its own
+	q.push(QueueJob{Url: "/a/x", depth: 2})
+	q.push(QueueJob{Url: "/a/y", depth: 2})
+
+	if got := strings.Join(takeAll(q), ","); got != "/a/x,/a/y,/b" {
+		t.Errorf("took %s, want the subdirectories of /a before /b", got)
+	}
+}
+
+func TestJobQueue_BreadthOrderIsFIFO(t *testing.T) {
+	q := newJobQueue("breadth")
+	q.push(QueueJob{Url: "/a", depth: 1})
+	q.push(QueueJob{Url: "/b", depth: 1})
+	q.advance()
+	q.push(QueueJob{Url: "/a/x", depth: 2, score: 10})
+
+	if got := strings.Join(takeAll(q), ","); got != "/b,/a/x" {
+		t.Errorf("took %s, want the jobs in the order they were pushed", got)
+	}
+}
+
+func TestJobQueue_ScoreOrder(t *testing.T) {
+	q := newJobQueue("score")
+	busy, quiet := int64(4), int64(0)
+	q.push(QueueJob{Url: "/img", score: 1, parentMatches: &quiet})
+	q.push(QueueJob{Url: "/admin", score: 4, parentMatches: &quiet})
+	q.push(QueueJob{Url: "/docs", score: 2, parentMatches: &busy})
+	q.push(QueueJob{Url: "/css", score: 1, parentMatches: &quiet})
+
+	// The matches of the parent are read when the jobs are taken
+	busy = 100
+	if got := strings.Join(takeAll(q), ","); got != "/docs,/admin,/img,/css" {
+		t.Errorf("took %s, want the highest score first, equal ones in push order", got)
+	}
+}
+
+func TestJobQueue_PriorityGoesFirst(t *testing.T) {
+	q := newJobQueue("depth")
+	q.push(QueueJob{Url: "/active"})
+	q.advance()
+	q.push(QueueJob{Url: "/a", depth: 1})
+	q.push(QueueJob{Url: "/a/x", depth: 2})
+	q.push(QueueJob{Url: "/b", depth: 1})
+
+	// Offset 0 is the active job, the pending ones are listed in the order
+	// they will be taken in
+	pending := q.remaining()
+	if len(pending) != 4 || pending[1].Url != "/a/x" || pending[3].Url != "/b" {
+		t.Fatalf("unexpected queue %v", pending)
+	}
+	if q.setPriority(0, 1) {
+		t.Errorf("the active job was reprioritized")
+	}
+	if !q.setPriority(3, 1) {
+		t.Fatalf("could not reprioritize /b")
+	}
+	if got := strings.Join(takeAll(q), ","); got != "/b,/a/x,/a" {
+		t.Errorf("took %s, want the prioritized /b first", got)
+	}
+}
+
+func TestJobQueue_ListingKeepsOffsets(t *testing.T) {
+	q := newJobQueue("score")
+	q.push(QueueJob{Url: "/active"})
+	q.advance()
+	first, second := int64(2), int64(0)
+	q.push(QueueJob{Url: "/a", parentMatches: &first})
+	q.push(QueueJob{Url: "/b", parentMatches: &second})
+
+	if pending := q.remaining(); pending[1].Url != "/a" {
+		t.Fatalf("unexpected queue %v", pending)
+	}
+	// The active job finds more under /b while the console lists the queue
+	second = 10
+	if pending := q.remaining(); pending[1].Url != "/a" {
+		t.Fatalf("listing the queue reordered it: %v", pending)
+	}
+	if !q.removeAt(1) {
+		t.Fatalf("could not remove the job at offset 1")
+	}
+	if got := strings.Join(takeAll(q), ","); got != "/b" {
+		t.Errorf("took %s, want /b left after removing the listed /a", got)
+	}
+}
+
+func TestResponseScore(t *testing.T) {
+	tests := []struct {
+		url    string
+		status int64
+		want   int
+	}{
+		{"http://x/img", 404, 0},
+		{"http://x/img", 301, 1},
+		{"http://x/img", 200, 2},
+		{"http://x/img", 403, 3},
+		{"http://x/Backups", 301, 1 + nameScore},
+		{"http://x/site-admin", 401, 3 + nameScore},
+	}
+	for _, tc := range tests {
+		resp := ffuf.Response{Request: &ffuf.Request{Url: tc.url}, StatusCode: tc.status}
+		if got := responseScore(resp, ffuf.RecursionName(&ffuf.Config{}, resp.Request)); got != tc.want {
+			t.Errorf("responseScore(%s, %d) = %d, want %d", tc.url, tc.status, got, tc.want)
+		}
+	}
+
+	matches := int64(100)
+	job := QueueJob{score: 2, parentMatches: &matches}
+	if job.Score() != 2+maxParentMatchesScore {
+		t.Errorf("Score() = %d, want the parent matches capped at %d", job.Score(), maxParentMatchesScore)
+	}
+}
+
+// statusMatcher matches the responses with one status code, standing in for
+// the -recursion-status matcher the assembly builds
+type statusMatcher int64
+
+func (s statusMatcher) Filter(resp *ffuf.Response) (bool, error) {
+	return resp.StatusCode == int64(s), nil
+}
+func (s statusMatcher) Repr() string        { return "" }
+func (s statusMatcher) ReprVerbose() string { return "" }
+
+// bodyMatcher matches the responses whose body contains a string, standing in
+// for a -recursion-match matcher
+type bodyMatcher string
+
+func (b bodyMatcher) Filter(resp *ffuf.Response) (bool, error) {
+	return strings.Contains(string(resp.Data), string(b)), nil
+}
+func (b bodyMatcher) Repr() string        { return "" }
+func (b bodyMatcher) ReprVerbose() string { return "" }
+
+func TestRecursionManager_Content(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		status   int64
+		body     string
+		matchers []ffuf.FilterProvider
+		want     bool
+	}{
+		{"directory with a 200", "http://x/app", 200, "<a href=", nil, true},
+		{"status not recursed on", "http://x/app", 404, "<a href=", nil, false},
+		{"named like a file", "http://x/app.js", 200, "<a href=", nil, false},
+		{"hidden directory", "http://x/.git", 200, "<a href=", nil, true},
+		{"no -recursion-match match", "http://x/app", 200, "plain", []ffuf.FilterProvider{bodyMatcher("href")}, false},
+		{"-recursion-match match", "http://x/app", 200, "<a href=", []ffuf.FilterProvider{bodyMatcher("href")}, true},
+		{"listing named like a file", "http://x/v1.0", 200, "<title>Index of /v1.0</title>", []ffuf.FilterProvider{bodyMatcher("href")}, true},
+	}
+	for _, tc := range tests {
+		q := newJobQueue("breadth")
+		rm := newRecursionManager(&ffuf.Config{Method: "GET"}, q, NewNullOutput())
+		rm.status = statusMatcher(200)
+		rm.matchers = tc.matchers
+		resp := ffuf.Response{Request: &ffuf.Request{Url: tc.url}, StatusCode: tc.status, Data: []byte(tc.body)}
+
+		rm.handleContent(jobContext{depth: 0}, resp)
+
+		if got := q.total() == 1; got != tc.want {
+			t.Errorf("%s: queued a job: %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRecursionManager_ContentLeavesRedirectsToDefault(t *testing.T) {
+	q := newJobQueue("breadth")
+	rm := newRecursionManager(&ffuf.Config{Method: "GET"}, q, NewNullOutput())
+	rm.status = statusMatcher(301)
+	resp := ffuf.Response{
+		Request:    &ffuf.Request{Url: "http://x/dir"},
+		StatusCode: 301,
+		Headers:    map[string][]string{"Location": {"http://x/dir/"}},
+	}
+
+	// The redirect is queued once, by handleDefault
+	rm.handleContent(jobContext{depth: 0}, resp)
+	rm.handleDefault(jobContext{depth: 0}, resp)
+
+	if q.total() != 1 {
+		t.Errorf("expected the directory to be queued once, got %d", q.total())
+	}
+}
@@ -0,0 +1,115 @@
+package ffuf
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// BatchOptions are the settings of a -batch manifest that apply to all of its
+// jobs together
+type BatchOptions struct {
+	// Concurrency is the number of jobs run at the same time
+	Concurrency int `json:"concurrency" toml:"concurrency"`
+	// Rate is the number of requests per second of all the jobs together, on
+	// top of the -rate of each job. 0 does not limit them.
+	Rate int `json:"rate" toml:"rate"`
+	// Output is the directory the output file of each job is written to, or
+	// with Merge, the file the results of all the jobs are written to
+	Output       string `json:"output" toml:"output"`
+	OutputFormat string `json:"output_format" toml:"output_format"`
+	Merge        bool   `json:"merge" toml:"merge"`
+}
+
+// BatchJob is a job of a -batch manifest, with its options
+type BatchJob struct {
+	Name    string
+	Options *ConfigOptions
+}
+
+// Batch is a -batch manifest: a TOML file with the [batch] settings, the
+// [defaults] options shared by the jobs, and the jobs as [[jobs]] tables. The
+// defaults and the jobs are laid out like an ffufrc, with a name for each job:
+//
+//	[batch]
+//	concurrency = 2
+//	rate = 100
+//	output = "results"
+//
+//	[defaults.matcher]
+//	status = "200,301,403"
+//
+//	[[jobs]]
+//	name = "dirs"
+//	[jobs.http]
+//	url = "https://example.org/FUZZ"
+//	[jobs.input]
+//	wordlists = ["dirs.txt"]
+type Batch struct {
+	BatchOptions
+	Jobs []BatchJob
+}
+
+// ReadBatch reads a -batch manifest. The options of each job start from those
+// newOptions returns, then the defaults and the options of the job are read
+// over them.
+func ReadBatch(filename string, newOptions func() *ConfigOptions) (*Batch, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("batch manifest %s: %s", filename, err)
+	}
+	batch := &Batch{BatchOptions: BatchOptions{Concurrency: 1, OutputFormat: "json"}}
+	if settings, ok := tree.Get("batch").(*toml.Tree); ok {
+		if err := settings.Unmarshal(&batch.BatchOptions); err != nil {
+			return nil, fmt.Errorf("batch manifest %s: [batch]: %s", filename, err)
+		}
+	}
+	defaults, _ := tree.Get("defaults").(*toml.Tree)
+	jobs, _ := tree.Get("jobs").([]*toml.Tree)
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("batch manifest %s has no [[jobs]]", filename)
+	}
+
+	var errs Multierror
+	if batch.Concurrency < 1 {
+		errs.Add(fmt.Errorf("the concurrency of the batch must be at least 1"))
+	}
+	if batch.Rate < 0 {
+		errs.Add(fmt.Errorf("the rate of the batch must not be negative"))
+	}
+	if batch.Merge && batch.Output == "" {
+		errs.Add(fmt.Errorf("merging the results of the batch needs an output file"))
+	}
+	names := make(map[string]bool)
+	for i, jobTree := range jobs {
+		job := BatchJob{Name: fmt.Sprintf("job%d", i+1), Options: newOptions()}
+		if name, ok := jobTree.Get("name").(string); ok {
+			job.Name = name
+		}
+		if job.Name == "" || strings.ContainsAny(job.Name, `/\`) || strings.HasPrefix(job.Name, ".") {
+			errs.Add(fmt.Errorf("job %d: %q is not a name that can be used for its output file", i+1, job.Name))
+		} else if names[job.Name] {
+			errs.Add(fmt.Errorf("job %s is declared more than once", job.Name))
+		}
+		names[job.Name] = true
+		if defaults != nil {
+			if err := defaults.Unmarshal(job.Options); err != nil {
+				return nil, fmt.Errorf("batch manifest %s: [defaults]: %s", filename, err)
+			}
+		}
+		if err := jobTree.Unmarshal(job.Options); err != nil {
+			errs.Add(fmt.Errorf("job %s: %s", job.Name, err))
+		}
+		batch.Jobs = append(batch.Jobs, job)
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
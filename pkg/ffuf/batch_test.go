@@ -0,0 +1,80 @@
+package ffuf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBatch(t *testing.T, manifest string) string {
+	path := filepath.Join(t.TempDir(), "batch.toml")
+	if err := os.WriteFile(path, []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadBatch(t *testing.T) {
+	path := writeBatch(t, `
+[batch]
+concurrency = 3
+output = "results"
+
+[defaults.general]
+threads = 10
+[defaults.matcher]
+status = "200,403"
+
+[[jobs]]
+name = "dirs"
+[jobs.http]
+url = "https://example.org/FUZZ"
+[jobs.general]
+threads = 5
+
+[[jobs]]
+[jobs.http]
+url = "https://example.org/api/FUZZ"
+[jobs.matcher]
+status = "all"
+`)
+	b, err := ReadBatch(path, func() *ConfigOptions {
+		opts := NewConfigOptions()
+		opts.HTTP.Method = "HEAD"
+		return opts
+	})
+	if err != nil {
+		t.Fatalf("ReadBatch: %s", err)
+	}
+	if b.Concurrency != 3 || b.Rate != 0 || b.Output != "results" || b.OutputFormat != "json" || b.Merge {
+		t.Errorf("unexpected batch settings %+v", b.BatchOptions)
+	}
+	if len(b.Jobs) != 2 || b.Jobs[0].Name != "dirs" || b.Jobs[1].Name != "job2" {
+		t.Fatalf("unexpected jobs %+v", b.Jobs)
+	}
+	dirs, api := b.Jobs[0].Options, b.Jobs[1].Options
+	if dirs.HTTP.Method != "HEAD" || dirs.General.Threads != 5 || dirs.Matcher.Status != "200,403" {
+		t.Errorf("the dirs job did not layer its options over the defaults: %+v", dirs)
+	}
+	if api.HTTP.URL != "https://example.org/api/FUZZ" || api.General.Threads != 10 || api.Matcher.Status != "all" {
+		t.Errorf("the job2 job did not layer its options over the defaults: %+v", api)
+	}
+}
+
+func TestReadBatchErrors(t *testing.T) {
+	tests := map[string]string{
+		"[batch]\nconcurrency = 2\n":                                   "no [[jobs]]",
+		"[batch]\nconcurrency = 0\n[[jobs]]\nname = \"a\"\n":           "concurrency",
+		"[batch]\nmerge = true\n[[jobs]]\nname = \"a\"\n":              "needs an output file",
+		"[[jobs]]\nname = \"a\"\n[[jobs]]\nname = \"a\"\n":             "declared more than once",
+		"[[jobs]]\nname = \"../a\"\n":                                  "not a name",
+		"[[jobs]]\nname = \"a\"\n[jobs.general]\nthreads = \"many\"\n": "job a",
+	}
+	for manifest, want := range tests {
+		_, err := ReadBatch(writeBatch(t, manifest), NewConfigOptions)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ReadBatch(%q) returned %v, want an error containing %q", manifest, err, want)
+		}
+	}
+}
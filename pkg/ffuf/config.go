@@ -61,6 +61,7 @@ type Config struct {
 	OutputDirectory           string                `json:"outputdirectory"`
 	OutputFile                string                `json:"outputfile"`
 	OutputFormat              string                `json:"outputformat"`
+	OutputRequestDir          string                `json:"outputrequestdirectory"`
 	OutputSkipEmptyFile       bool                  `json:"OutputSkipEmptyFile"`
 	ProgressFrequency         int                   `json:"-"`
 	ProxyURL                  string                `json:"proxyurl"`
@@ -91,6 +92,77 @@ type Config struct {
 	Postflights               []PreflightConfig     `json:"postflights"`
 	PreflightMode             string                `json:"preflight_mode"`
 	PreflightError            string                `json:"preflight_error"`
+	// Resolve maps a "host:port" (port may be "*") to the IP address the runner
+	// connects to instead of resolving the host. The URL, Host header and SNI are
+	// left untouched, so the dial target can be chosen independently of them.
+	Resolve     map[string]string `json:"resolve"`
+	DNSResolver string            `json:"dns_resolver"`
+	// UnixSocket, when set, is the Unix domain socket every connection is made to.
+	// The URL is still a regular http(s) URL used for templating and Host headers.
+	UnixSocket string `json:"unix_socket"`
+	// WebSocket is set for ws:// and wss:// targets: the request is a handshake
+	// followed by Data sent as a message, and the replies collected for WSTimeout
+	// milliseconds (or until WSFrames messages arrived) make up the response.
+	WebSocket bool `json:"websocket"`
+	WSTimeout int  `json:"ws_timeout"`
+	WSFrames  int  `json:"ws_frames"`
+	// GraphQLQuery, when set, is the query template the request body is built
+	// from, see runner.GraphQLRunner. GraphQLHarvest is the keyword whose input
+	// grows with the names suggested in GraphQL error messages.
+	GraphQLQuery     string `json:"graphql_query"`
+	GraphQLVariables string `json:"graphql_variables"`
+	GraphQLBatch     int    `json:"graphql_batch"`
+	GraphQLHarvest   string `json:"graphql_harvest"`
+	// ParamMine is where the FUZZ inputs are added as candidate parameter names
+	// (query, form, json or header), ParamBatch of them per request, see
+	// runner.ParamMinerRunner.
+	ParamMine  string `json:"param_mine"`
+	ParamBatch int    `json:"param_batch"`
+	// Multipart are the -F parts the multipart/form-data body is built from
+	Multipart []MultipartPart `json:"multipart"`
+	// AuditLogMaxSize (in bytes) and AuditLogMaxAge (in seconds) rotate the audit
+	// log, AuditLogCompress is gzip or zstd, AuditLogNoBody leaves the bodies out
+	// of it and AuditLogOnly limits it to the "matched" and "errored" exchanges.
+	AuditLogMaxSize  int64    `json:"auditlog_max_size"`
+	AuditLogMaxAge   int      `json:"auditlog_max_age"`
+	AuditLogCompress string   `json:"auditlog_compress"`
+	AuditLogNoBody   bool     `json:"auditlog_no_body"`
+	AuditLogOnly     []string `json:"auditlog_only"`
+	// ResultsDB is the results database every result is stored to, see
+	// output.ResultsDB
+	ResultsDB string `json:"resultsdb"`
+	// Baseline is the output file of an earlier run the results are compared to
+	Baseline string `json:"baseline"`
+	// Cluster collapses near-identical results, see output.ClusterResults
+	Cluster bool `json:"cluster"`
+	// Api is the address of the control API, ApiToken the token its requests
+	// authenticate with. The token is never written to the output files.
+	Api      string `json:"api"`
+	ApiToken string `json:"-"`
+	// Metrics is the address the Prometheus metrics are served at
+	Metrics string `json:"metrics"`
+	// Hooks run on the events of the scan, HookConcurrency of them at a time
+	// and for at most HookTimeout seconds each
+	Hooks           []HookConfig `json:"hooks"`
+	HookConcurrency int          `json:"hook_concurrency"`
+	HookTimeout     int          `json:"hook_timeout"`
+	// Plugins are the out-of-process plugins declared in the config file
+	Plugins []PluginConfig `json:"plugins"`
+	// Script is the Starlark -script run on the requests and responses
+	Script string `json:"script"`
+	// RecursionOrder is the order the recursion jobs are taken from the queue in
+	RecursionOrder string `json:"recursion_order"`
+	// RecursionStatus and RecursionMatch are the status codes and the matchers
+	// of the responses the "content" recursion strategy recurses into
+	RecursionStatus string   `json:"recursion_status"`
+	RecursionMatch  []string `json:"recursion_match"`
+	// RecursionWordlists and RecursionExtensions are the FUZZ wordlist and the
+	// extensions of the recursion jobs from a depth on
+	RecursionWordlists  map[int]string   `json:"recursion_wordlists"`
+	RecursionExtensions map[int][]string `json:"recursion_extensions"`
+	// RecursionTemplates are where the recursion jobs put the values they were
+	// found with, instead of FUZZ at the end of the URL
+	RecursionTemplates []RecursionTemplate `json:"recursion_templates"`
 	// RateLimitFunc blocks until the shared rate limiter allows another request.
 	// The engine sets it so preflight/postflight requests (sent from the runner,
 	// outside the dispatch loop) also honor -rate and -p. Nil means unmetered.
@@ -127,6 +199,7 @@ func NewConfig(ctx context.Context, cancel context.CancelFunc) Config {
 	conf.Extensions = make([]string, 0)
 	conf.Headers = make(map[string]string)
 	conf.InputProviders = make([]InputProviderConfig, 0)
+	conf.Resolve = make(map[string]string)
 	conf.Wordlists = []string{}
 
 	conf.AutoCalibrationKeyword = "FUZZ"
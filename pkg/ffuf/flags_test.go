@@ -31,30 +31,42 @@ func TestRegisterFlags_Wellformed(t *testing.T) {
 
 	// The full expected flag surface. Unlike a bare count, this catches a net-zero
 	// add-and-remove, a rename, or a duplicate — update it DELIBERATELY when the CLI
-	// surface changes. 78 visible flags + 7 hidden compat (4 aliases + 3 dummies).
+	// surface changes. 122 visible flags + 7 hidden compat (4 aliases + 3 dummies).
 	expected := map[string]bool{
 		// HTTP
-		"H": true, "X": true, "b": true, "cc": true, "ck": true, "d": true,
+		"F": true, "H": true, "X": true, "b": true, "cc": true, "ck": true, "d": true,
 		"http2": true, "ignore-body": true, "r": true, "raw": true, "recursion": true,
-		"recursion-depth": true, "recursion-strategy": true, "replay-proxy": true,
+		"recursion-depth": true, "recursion-match": true, "recursion-order": true, "recursion-status": true, "recursion-strategy": true, "recursion-template": true, "replay-proxy": true,
 		"sni": true, "timeout": true, "u": true, "x": true,
 		"preflight-mode": true, "preflight-error": true,
 		"preflight": true, "preflight-var": true, "postflight": true, "postflight-var": true,
+		"resolve": true, "dns-resolver": true, "unix-socket": true,
+		"ws-timeout": true, "ws-frames": true,
+		"graphql": true, "graphql-vars": true, "graphql-batch": true,
 		// General
 		"V": true, "ac": true, "acc": true, "ach": true, "ack": true, "acs": true,
 		"c": true, "config": true, "json": true, "maxtime": true, "maxtime-job": true,
 		"noninteractive": true, "p": true, "rate": true, "s": true, "sa": true,
+		"api": true, "api-token": true, "metrics": true,
+		"hook": true, "hook-concurrency": true, "hook-timeout": true, "script": true, "batch": true,
 		"scraperfile": true, "scrapers": true, "se": true, "search": true, "sf": true,
 		"t": true, "v": true,
 		// Matcher
 		"mc": true, "ml": true, "mmode": true, "mr": true, "ms": true, "mt": true, "mw": true,
+		"mge": true, "mgm": true, "mgd": true,
 		// Filter
 		"fc": true, "fl": true, "fmode": true, "fr": true, "fs": true, "ft": true, "fw": true,
+		"fge": true, "fgm": true, "fgd": true,
 		// Input
 		"D": true, "e": true, "enc": true, "ic": true, "input-cmd": true, "input-num": true,
 		"input-shell": true, "mode": true, "request": true, "request-proto": true, "w": true,
+		"request-entry": true, "request-format": true, "request-fuzz": true,
+		"graphql-harvest": true, "param-mine": true, "param-batch": true,
+		"recursion-wordlist": true, "recursion-extensions": true,
 		// Output
-		"audit-log": true, "debug-log": true, "o": true, "od": true, "of": true, "or": true,
+		"audit-log": true, "debug-log": true, "o": true, "od": true, "odr": true, "of": true, "or": true,
+		"audit-log-compress": true, "audit-log-max-age": true, "audit-log-max-size": true,
+		"audit-log-no-body": true, "audit-log-only": true, "results-db": true, "baseline": true, "cluster": true,
 		// Compat aliases
 		"cookie": true, "data": true, "data-ascii": true, "data-binary": true,
 		// Compat dummies
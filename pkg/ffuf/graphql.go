@@ -0,0 +1,90 @@
+package ffuf
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GraphQLResponse is the part of a GraphQL response body ffuf looks at.
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data"`
+	Errors []GraphQLError `json:"errors"`
+}
+
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path"`
+}
+
+// ParseGraphQLResponse decodes a GraphQL response body. The second return value
+// is false when the body is not a JSON object with a data or errors member.
+func ParseGraphQLResponse(body []byte) (GraphQLResponse, bool) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return GraphQLResponse{}, false
+	}
+	_, hasData := members["data"]
+	_, hasErrors := members["errors"]
+	if !hasData && !hasErrors {
+		return GraphQLResponse{}, false
+	}
+	var resp GraphQLResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return GraphQLResponse{}, false
+	}
+	return resp, true
+}
+
+// HasPath reports whether the dot separated path (eg. "users.0.email") leads to
+// a non-null value in the response data. A name segment applied to a list
+// matches if any of its elements has the rest of the path.
+func (r GraphQLResponse) HasPath(path string) bool {
+	return hasPath(r.Data, strings.Split(path, "."))
+}
+
+func hasPath(value interface{}, segments []string) bool {
+	if value == nil {
+		return false
+	}
+	if len(segments) == 0 {
+		return true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[segments[0]]
+		return ok && hasPath(child, segments[1:])
+	case []interface{}:
+		if idx, err := strconv.Atoi(segments[0]); err == nil {
+			return idx >= 0 && idx < len(v) && hasPath(v[idx], segments[1:])
+		}
+		for _, elem := range v {
+			if hasPath(elem, segments) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var (
+	graphqlSuggestionRegexp = regexp.MustCompile(`(?i)did you mean (.*)`)
+	graphqlNameRegexp       = regexp.MustCompile(`"([_A-Za-z][_0-9A-Za-z]*)"`)
+)
+
+// Suggestions returns the field, argument and type names suggested in the error
+// messages, eg. `Cannot query field "usr" on type "Query". Did you mean "user"?`
+func (r GraphQLResponse) Suggestions() []string {
+	names := make([]string, 0)
+	for _, e := range r.Errors {
+		m := graphqlSuggestionRegexp.FindStringSubmatch(e.Message)
+		if m == nil {
+			continue
+		}
+		for _, name := range graphqlNameRegexp.FindAllStringSubmatch(m[1], -1) {
+			names = append(names, name[1])
+		}
+	}
+	return names
+}
@@ -0,0 +1,52 @@
+package ffuf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGraphQLResponse(t *testing.T) {
+	for _, body := range []string{`<html>`, `[]`, `{"message":"hi"}`} {
+		if _, ok := ParseGraphQLResponse([]byte(body)); ok {
+			t.Errorf("%s should not parse as a GraphQL response", body)
+		}
+	}
+	resp, ok := ParseGraphQLResponse([]byte(`{"data":null,"errors":[{"message":"nope","path":["user",0]}]}`))
+	if !ok {
+		t.Fatalf("expected a GraphQL response")
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "nope" || len(resp.Errors[0].Path) != 2 {
+		t.Errorf("unexpected errors: %+v", resp.Errors)
+	}
+}
+
+func TestGraphQLResponseHasPath(t *testing.T) {
+	resp, _ := ParseGraphQLResponse([]byte(`{"data":{"user":{"name":"a","email":null},"users":[{"id":null},{"id":2}]}}`))
+	for path, want := range map[string]bool{
+		"user":       true,
+		"user.name":  true,
+		"user.email": false,
+		"user.phone": false,
+		"users.id":   true,
+		"users.0.id": false,
+		"users.1.id": true,
+		"users.2":    false,
+		"nothing":    false,
+	} {
+		if got := resp.HasPath(path); got != want {
+			t.Errorf("HasPath(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
+
+func TestGraphQLResponseSuggestions(t *testing.T) {
+	resp, _ := ParseGraphQLResponse([]byte(`{"errors":[
+		{"message":"Cannot query field \"usr\" on type \"Query\". Did you mean \"user\" or \"users\"?"},
+		{"message":"Unknown argument \"nme\" on field \"Query.user\". Did you mean \"name\"?"},
+		{"message":"Field \"user\" argument \"name\" is required"}
+	]}`))
+	want := []string{"user", "users", "name"}
+	if got := resp.Suggestions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggestions() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The events a -hook can run on
+const (
+	HookMatch       = "match"
+	HookJobQueued   = "job-queued"
+	HookJobStarted  = "job-started"
+	HookJobFinished = "job-finished"
+	HookStopped     = "stopped"
+	HookError       = "error"
+)
+
+var hookEvents = []string{HookMatch, HookJobQueued, HookJobStarted, HookJobFinished, HookStopped, HookError}
+
+// HookConfig is a -hook: a local command, or a URL the events are posted to,
+// that runs on some of the events of the scan.
+type HookConfig struct {
+	Events  []string `json:"events"`
+	Command string   `json:"command,omitempty"`
+	Url     string   `json:"url,omitempty"`
+}
+
+// HookEvent is an event of the scan the -hook commands and webhooks run on.
+// Url is the URL of the job, Message the error or the reason the scan stopped
+// and Response the matched response.
+type HookEvent struct {
+	Name     string
+	Url      string
+	Message  string
+	Response *Response
+}
+
+// ParseHook parses a -hook of the form EVENTS:COMMAND or EVENTS:URL, where
+// EVENTS is a comma separated list of event names or "all".
+func ParseHook(spec string) (HookConfig, error) {
+	var hook HookConfig
+	events, target, found := strings.Cut(spec, ":")
+	target = strings.TrimSpace(target)
+	if !found || target == "" {
+		return hook, fmt.Errorf("-hook %q is not of the form EVENTS:COMMAND or EVENTS:URL", spec)
+	}
+	for _, event := range strings.Split(events, ",") {
+		event = strings.TrimSpace(event)
+		if event == "all" {
+			hook.Events = append(hook.Events, hookEvents...)
+		} else if StrInSlice(event, hookEvents) {
+			hook.Events = append(hook.Events, event)
+		} else {
+			return hook, fmt.Errorf("unknown -hook event %q, expected all or any of: %s", event, strings.Join(hookEvents, ", "))
+		}
+	}
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		hook.Url = target
+	} else {
+		hook.Command = target
+	}
+	return hook, nil
+}
+
+// Runs reports whether the hook runs on an event
+func (h HookConfig) Runs(event string) bool {
+	return StrInSlice(event, h.Events)
+}
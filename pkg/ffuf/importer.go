@@ -0,0 +1,642 @@
+package ffuf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Request file formats understood by -request, see ImportRequests
+const (
+	RequestFormatRaw  = "raw"
+	RequestFormatHAR  = "har"
+	RequestFormatCurl = "curl"
+	RequestFormatBurp = "burp"
+)
+
+// DetectRequestFormat guesses the format of a -request file from its contents:
+// a HAR archive, curl command lines, a Burp Suite saved items XML or, failing
+// those, a raw HTTP request.
+func DetectRequestFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return RequestFormatHAR
+	case bytes.HasPrefix(trimmed, []byte("curl ")) || bytes.HasPrefix(trimmed, []byte("curl\t")):
+		return RequestFormatCurl
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return RequestFormatBurp
+	}
+	return RequestFormatRaw
+}
+
+// ImportRequests parses the requests of a -request file in the given format.
+// proto is the scheme of a raw request, which carries only the path and the
+// Host header.
+func ImportRequests(data []byte, format string, proto string) ([]Request, error) {
+	switch format {
+	case RequestFormatRaw:
+		req, err := parseRawHTTP(data, proto)
+		if err != nil {
+			return nil, err
+		}
+		return []Request{req}, nil
+	case RequestFormatHAR:
+		return parseHAR(data)
+	case RequestFormatCurl:
+		return parseCurlCommands(string(data))
+	case RequestFormatBurp:
+		return parseBurpItems(data)
+	}
+	return nil, fmt.Errorf("unknown request format %q, available formats: raw, har, curl, burp", format)
+}
+
+// SelectRequest picks the request of an imported file to use. The selector is
+// either a 1-based index or a string the "METHOD URL" of the request contains;
+// an empty selector picks the first request.
+func SelectRequest(reqs []Request, selector string) (Request, error) {
+	if len(reqs) == 0 {
+		return Request{}, fmt.Errorf("no requests found")
+	}
+	if selector == "" {
+		return reqs[0], nil
+	}
+	if i, err := strconv.Atoi(selector); err == nil {
+		if i < 1 || i > len(reqs) {
+			return Request{}, fmt.Errorf("request entry %d out of range, the file has %d requests", i, len(reqs))
+		}
+		return reqs[i-1], nil
+	}
+	for _, req := range reqs {
+		if strings.Contains(req.Method+" "+req.Url, selector) {
+			return req, nil
+		}
+	}
+	return Request{}, fmt.Errorf("no request matching %q", selector)
+}
+
+// jsonMember matches a JSON object member, submatch 1 is its name and 2 its value
+var jsonMember = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"\s*:\s*("(?:[^"\\]|\\.)*"|[^\s,{}\[\]"]+)`)
+
+// MarkFuzzParameters replaces the value of every query parameter, form field,
+// scalar JSON member, cookie and header named in names with the FUZZ keyword, and
+// returns the names that were not found anywhere in the request.
+func MarkFuzzParameters(req *Request, names []string) []string {
+	wanted := make(map[string]bool, len(names))
+	for _, n := range names {
+		wanted[n] = false
+	}
+	mark := func(name string) bool {
+		if _, ok := wanted[name]; ok {
+			wanted[name] = true
+			return true
+		}
+		return false
+	}
+
+	if q := strings.Index(req.Url, "?"); q != -1 {
+		req.Url = req.Url[:q+1] + markPairs(req.Url[q+1:], "&", "=", mark, url.QueryUnescape)
+	}
+
+	ct := strings.ToLower(headerValue(req.Headers, "Content-Type"))
+	data := string(req.Data)
+	switch {
+	case strings.Contains(ct, "json") || (ct == "" && json.Valid(req.Data)):
+		req.Data = []byte(jsonMember.ReplaceAllStringFunc(data, func(member string) string {
+			m := jsonMember.FindStringSubmatch(member)
+			if !mark(m[1]) {
+				return member
+			}
+			return `"` + m[1] + `":"FUZZ"`
+		}))
+	case strings.Contains(ct, "x-www-form-urlencoded") || (ct == "" && strings.Contains(data, "=")):
+		req.Data = []byte(markPairs(data, "&", "=", mark, url.QueryUnescape))
+	}
+
+	for h, v := range req.Headers {
+		if strings.EqualFold(h, "Cookie") {
+			req.Headers[h] = markPairs(v, ";", "=", mark, nil)
+		} else if mark(h) {
+			req.Headers[h] = "FUZZ"
+		}
+	}
+
+	var missing []string
+	for _, n := range names {
+		if !wanted[n] {
+			missing = append(missing, n)
+		}
+	}
+	return missing
+}
+
+// markPairs replaces the values of the marked name=value pairs of s with FUZZ
+func markPairs(s, sep, eq string, mark func(string) bool, unescape func(string) (string, error)) string {
+	pairs := strings.Split(s, sep)
+	for i, pair := range pairs {
+		name, _, found := strings.Cut(pair, eq)
+		if !found {
+			continue
+		}
+		if unescape != nil {
+			if n, err := unescape(name); err == nil {
+				name = n
+			}
+		}
+		if mark(strings.TrimSpace(name)) {
+			pairs[i] = pair[:strings.Index(pair, eq)+len(eq)] + "FUZZ"
+		}
+	}
+	return strings.Join(pairs, sep)
+}
+
+// parseRawHTTP parses a raw HTTP request as saved from a proxy
+func parseRawHTTP(data []byte, proto string) (Request, error) {
+	req := Request{Headers: make(map[string]string)}
+	r := bufio.NewReader(bytes.NewReader(data))
+
+	s, err := r.ReadString('\n')
+	if err != nil {
+		return req, fmt.Errorf("could not read request: %s", err)
+	}
+	parts := strings.Split(s, " ")
+	if len(parts) < 3 {
+		return req, fmt.Errorf("malformed request supplied")
+	}
+	// Set the request Method
+	req.Method = parts[0]
+
+	for {
+		line, err := r.ReadString('\n')
+		line = strings.TrimSpace(line)
+
+		if err != nil || line == "" {
+			break
+		}
+
+		p := strings.SplitN(line, ":", 2)
+		if len(p) != 2 {
+			continue
+		}
+
+		if strings.EqualFold(p[0], "content-length") {
+			continue
+		}
+
+		req.Headers[strings.TrimSpace(p[0])] = strings.TrimSpace(p[1])
+	}
+
+	// Handle case with the full http url in path. In that case, use the path
+	// as request URL, and its host as the Host header unless one is given, as
+	// in the -odr files of virtual host matches
+	if strings.HasPrefix(parts[1], "http") {
+		parsed, err := url.Parse(parts[1])
+		if err != nil {
+			return req, fmt.Errorf("could not parse request URL: %s", err)
+		}
+		req.Url = parts[1]
+		if headerValue(req.Headers, "Host") == "" {
+			req.Headers["Host"] = parsed.Host
+		}
+	} else {
+		// Build the request URL from the request
+		req.Url = proto + "://" + req.Headers["Host"] + parts[1]
+	}
+
+	// Set the request body
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return req, fmt.Errorf("could not read request body: %s", err)
+	}
+
+	// Remove newline (typically added by the editor) at the end of the file
+	//nolint:gosimple // we specifically want to remove just a single newline, not all of them
+	if bytes.HasSuffix(b, []byte("\r\n")) {
+		b = b[:len(b)-2]
+	} else if bytes.HasSuffix(b, []byte("\n")) {
+		b = b[:len(b)-1]
+	}
+	req.Data = b
+	return req, nil
+}
+
+type harArchive struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				Cookies []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"cookies"`
+				PostData *struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Params   []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"params"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// parseHAR returns the requests of the entries of a HAR archive
+func parseHAR(data []byte) ([]Request, error) {
+	var har harArchive
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("could not parse HAR: %s", err)
+	}
+	reqs := make([]Request, 0, len(har.Log.Entries))
+	for _, e := range har.Log.Entries {
+		req := Request{Method: e.Request.Method, Url: e.Request.URL, Headers: make(map[string]string)}
+		for _, h := range e.Request.Headers {
+			// HTTP/2 pseudo headers and the length of the original body are not sent as such
+			if strings.HasPrefix(h.Name, ":") || strings.EqualFold(h.Name, "Content-Length") {
+				continue
+			}
+			req.Headers[h.Name] = h.Value
+		}
+		if headerValue(req.Headers, "Cookie") == "" && len(e.Request.Cookies) > 0 {
+			cookies := make([]string, 0, len(e.Request.Cookies))
+			for _, c := range e.Request.Cookies {
+				cookies = append(cookies, c.Name+"="+c.Value)
+			}
+			req.Headers["Cookie"] = strings.Join(cookies, "; ")
+		}
+		if pd := e.Request.PostData; pd != nil {
+			if pd.Text == "" && len(pd.Params) > 0 {
+				params := make([]string, 0, len(pd.Params))
+				for _, p := range pd.Params {
+					params = append(params, url.QueryEscape(p.Name)+"="+url.QueryEscape(p.Value))
+				}
+				pd.Text = strings.Join(params, "&")
+			}
+			req.Data = []byte(pd.Text)
+			if headerValue(req.Headers, "Content-Type") == "" && pd.MimeType != "" {
+				req.Headers["Content-Type"] = pd.MimeType
+			}
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+type burpItems struct {
+	Items []struct {
+		URL      string `xml:"url"`
+		Protocol string `xml:"protocol"`
+		Method   string `xml:"method"`
+		Request  struct {
+			Base64 bool   `xml:"base64,attr"`
+			Data   string `xml:",chardata"`
+		} `xml:"request"`
+	} `xml:"item"`
+}
+
+// parseBurpItems returns the requests of a Burp Suite "Save items" XML file
+func parseBurpItems(data []byte) ([]Request, error) {
+	var items burpItems
+	if err := xml.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("could not parse Burp XML: %s", err)
+	}
+	reqs := make([]Request, 0, len(items.Items))
+	for i, item := range items.Items {
+		raw := []byte(item.Request.Data)
+		if item.Request.Base64 {
+			var err error
+			if raw, err = base64.StdEncoding.DecodeString(strings.TrimSpace(item.Request.Data)); err != nil {
+				return nil, fmt.Errorf("could not decode Burp item %d: %s", i+1, err)
+			}
+		}
+		proto := item.Protocol
+		if proto == "" {
+			proto = "https"
+		}
+		req, err := parseRawHTTP(raw, proto)
+		if err != nil {
+			return nil, fmt.Errorf("Burp item %d: %s", i+1, err)
+		}
+		// The raw request carries only the path, the item has the URL with the port
+		if item.URL != "" {
+			req.Url = item.URL
+		}
+		// Burp saves the body as it was sent, no editor newline to remove
+		if _, body, found := bytes.Cut(raw, []byte("\r\n\r\n")); found {
+			req.Data = body
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// parseCurlCommands returns the requests of the curl command lines in s, as
+// copied from the developer tools of a browser ("Copy as cURL"). Commands are
+// separated by newlines or semicolons.
+func parseCurlCommands(s string) ([]Request, error) {
+	commands, err := shellSplit(s)
+	if err != nil {
+		return nil, err
+	}
+	var reqs []Request
+	for _, args := range commands {
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] != "curl" {
+			return nil, fmt.Errorf("not a curl command: %s", args[0])
+		}
+		req, err := parseCurlArgs(args[1:])
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// curlValueOptions are the curl options that take a value and do not affect the request
+var curlValueOptions = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+	"-x": true, "--proxy": true, "--retry": true, "-w": true, "--write-out": true,
+	"--resolve": true, "--cacert": true, "-E": true, "--cert": true, "--key": true,
+	"-c": true, "--cookie-jar": true, "--max-redirs": true, "-r": true, "--range": true,
+}
+
+func parseCurlArgs(args []string) (Request, error) {
+	req := Request{Headers: make(map[string]string)}
+	var data []string
+	get := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		opt, value, inline := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			opt, value, inline = strings.Cut(arg, "=")
+		} else if len(arg) > 2 && arg[0] == '-' && strings.ContainsRune("XHdbAeuF", rune(arg[1])) {
+			// Short options may have their value attached: -XPOST
+			opt, value, inline = arg[:2], arg[2:], true
+		}
+		next := func() (string, error) {
+			if inline {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("curl option %s needs a value", opt)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch opt {
+		case "-X", "--request":
+			req.Method, err = next()
+		case "-H", "--header":
+			var h string
+			if h, err = next(); err == nil {
+				if name, v, found := strings.Cut(h, ":"); found && !strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+					req.Headers[strings.TrimSpace(name)] = strings.TrimSpace(v)
+				}
+			}
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			var d string
+			if d, err = next(); err == nil {
+				if strings.HasPrefix(d, "@") && opt != "--data-raw" {
+					return req, fmt.Errorf("curl data read from a file (%s) is not supported", d)
+				}
+				data = append(data, d)
+			}
+		case "--data-urlencode":
+			var d string
+			if d, err = next(); err == nil {
+				if name, v, found := strings.Cut(d, "="); found {
+					data = append(data, name+"="+url.QueryEscape(v))
+				} else {
+					data = append(data, url.QueryEscape(d))
+				}
+			}
+		case "--json":
+			var d string
+			if d, err = next(); err == nil {
+				data = append(data, d)
+				req.Headers["Content-Type"] = "application/json"
+				req.Headers["Accept"] = "application/json"
+			}
+		case "-b", "--cookie":
+			var c string
+			if c, err = next(); err == nil {
+				if existing := req.Headers["Cookie"]; existing != "" {
+					c = existing + "; " + c
+				}
+				req.Headers["Cookie"] = c
+			}
+		case "-A", "--user-agent":
+			req.Headers["User-Agent"], err = next()
+		case "-e", "--referer":
+			req.Headers["Referer"], err = next()
+		case "-u", "--user":
+			var u string
+			if u, err = next(); err == nil {
+				req.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(u))
+			}
+		case "--url":
+			req.Url, err = next()
+		case "-G", "--get":
+			get = true
+		case "-I", "--head":
+			req.Method = "HEAD"
+		case "-F", "--form":
+			return req, fmt.Errorf("curl multipart forms (%s) are not supported, use ffuf -F instead", opt)
+		default:
+			switch {
+			case curlValueOptions[opt]:
+				_, err = next()
+			case strings.HasPrefix(arg, "-") && arg != "-":
+				// Flags without a value: --compressed, -k, -L, -s, -i, -v and so on
+			case req.Url == "":
+				req.Url = arg
+			default:
+				return req, fmt.Errorf("unexpected curl argument %q", arg)
+			}
+		}
+		if err != nil {
+			return req, err
+		}
+	}
+	if req.Url == "" {
+		return req, fmt.Errorf("curl command without a URL")
+	}
+	if !strings.Contains(req.Url, "://") {
+		req.Url = "http://" + req.Url
+	}
+
+	body := strings.Join(data, "&")
+	if get && body != "" {
+		sep := "?"
+		if strings.Contains(req.Url, "?") {
+			sep = "&"
+		}
+		req.Url += sep + body
+		body = ""
+	}
+	if body != "" {
+		req.Data = []byte(body)
+		if headerValue(req.Headers, "Content-Type") == "" {
+			req.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+		if body != "" {
+			req.Method = "POST"
+		}
+	}
+	return req, nil
+}
+
+// shellSplit splits s into commands of words the way a POSIX shell would for
+// the quoting used by "Copy as cURL": single and double quotes, ANSI-C $'...'
+// quotes and backslash escapes and line continuations. Unquoted newlines and
+// semicolons separate the commands.
+func shellSplit(s string) ([][]string, error) {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(s) {
+				i++
+				if s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+					i++
+				}
+				if s[i] != '\n' {
+					word.WriteByte(s[i])
+					inWord = true
+				}
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'':
+			n, err := ansiCQuoted(s[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i += n + 2
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`\n", s[i+1]) != -1 {
+					i++
+					if s[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		case c == '\n' || c == ';':
+			endCommand()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands, nil
+}
+
+// ansiCQuoted writes the contents of a $'...' string starting after the opening
+// quote to word, and returns the number of bytes consumed including the closing quote.
+func ansiCQuoted(s string, word *strings.Builder) (int, error) {
+	escapes := map[byte]byte{'n': '\n', 'r': '\r', 't': '\t', '\\': '\\', '\'': '\'', '"': '"', '0': 0}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			return i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				break
+			}
+			i++
+			if e, ok := escapes[s[i]]; ok {
+				word.WriteByte(e)
+			} else if n := hexDigits(s[i+1:], s[i]); n > 0 {
+				// Like bash, \x takes one or two hex digits and \u up to four
+				v, _ := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+				if s[i] == 'x' {
+					word.WriteByte(byte(v))
+				} else {
+					word.WriteRune(rune(v))
+				}
+				i += n
+			} else {
+				word.WriteByte('\\')
+				word.WriteByte(s[i])
+			}
+		default:
+			word.WriteByte(s[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
+
+// hexDigits returns the number of hex digits at the start of s that belong to
+// the escape: up to two for \x, up to four for \u, none for the other escapes
+func hexDigits(s string, escape byte) int {
+	max := 0
+	switch escape {
+	case 'x':
+		max = 2
+	case 'u':
+		max = 4
+	}
+	n := 0
+	for n < max && n < len(s) && strings.IndexByte("0123456789abcdefABCDEF", s[n]) != -1 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,201 @@
+package ffuf
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestDetectRequestFormat(t *testing.T) {
+	for data, want := range map[string]string{
+		"GET / HTTP/1.1\r\nHost: a\r\n\r\n":      RequestFormatRaw,
+		"  {\"log\": {}}":                        RequestFormatHAR,
+		"curl 'https://example.org'":             RequestFormatCurl,
+		"<?xml version=\"1.0\"?><items></items>": RequestFormatBurp,
+	} {
+		if got := DetectRequestFormat([]byte(data)); got != want {
+			t.Errorf("%q detected as %s, want %s", data, got, want)
+		}
+	}
+}
+
+func TestImportRequests_Raw(t *testing.T) {
+	reqs, err := ImportRequests([]byte("POST /login?x=1 HTTP/1.1\r\nHost: example.org\r\nContent-Length: 3\r\n\r\na=b\n"), RequestFormatRaw, "http")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	req := reqs[0]
+	if req.Method != "POST" || req.Url != "http://example.org/login?x=1" || string(req.Data) != "a=b" {
+		t.Errorf("unexpected request %+v", req)
+	}
+	if _, ok := req.Headers["Content-Length"]; ok {
+		t.Errorf("Content-Length should not be kept")
+	}
+}
+
+func TestImportRequests_HAR(t *testing.T) {
+	har := `{"log": {"entries": [
+		{"request": {"method": "GET", "url": "https://example.org/", "headers": [{"name": ":authority", "value": "example.org"}]}},
+		{"request": {"method": "POST", "url": "https://example.org/api",
+			"headers": [{"name": "Content-Length", "value": "9"}, {"name": "X-Token", "value": "t"}],
+			"cookies": [{"name": "session", "value": "abc"}, {"name": "lang", "value": "en"}],
+			"postData": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "q", "value": "a b"}]}}}
+	]}}`
+	reqs, err := ImportRequests([]byte(har), RequestFormatHAR, "https")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(reqs) != 2 || len(reqs[0].Headers) != 0 {
+		t.Fatalf("unexpected requests %+v", reqs)
+	}
+	want := map[string]string{
+		"X-Token":      "t",
+		"Cookie":       "session=abc; lang=en",
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+	if !reflect.DeepEqual(reqs[1].Headers, want) || string(reqs[1].Data) != "q=a+b" {
+		t.Errorf("unexpected request %+v", reqs[1])
+	}
+}
+
+func TestImportRequests_Burp(t *testing.T) {
+	raw := "PUT /item/1 HTTP/1.1\r\nHost: example.org:8443\r\nContent-Type: application/json\r\n\r\n{\"a\":1}\n"
+	xml := `<?xml version="1.0"?>
+<items burpVersion="2023.1">
+  <item>
+    <url><![CDATA[https://example.org:8443/item/1]]></url>
+    <protocol>https</protocol>
+    <method><![CDATA[PUT]]></method>
+    <request base64="true"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte(raw)) + `]]></request>
+  </item>
+</items>`
+	reqs, err := ImportRequests([]byte(xml), RequestFormatBurp, "https")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	req := reqs[0]
+	if req.Method != "PUT" || req.Url != "https://example.org:8443/item/1" || string(req.Data) != "{\"a\":1}\n" {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestImportRequests_Curl(t *testing.T) {
+	cmds := `curl 'https://example.org/api?id=1' \
+  -H 'Accept: */*' \
+  -H "X-Quote: say \"hi\"" \
+  -b 'session=abc' \
+  --data-raw $'{"note":"line\nbreak","n":5}' \
+  --compressed ;
+curl -G -u user:pass https://example.org/search -d q=x --data-urlencode 'r=a b'
+curl -XDELETE example.org/item`
+	reqs, err := ImportRequests([]byte(cmds), RequestFormatCurl, "https")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(reqs))
+	}
+	want := Request{
+		Method: "POST",
+		Url:    "https://example.org/api?id=1",
+		Headers: map[string]string{
+			"Accept":       "*/*",
+			"X-Quote":      `say "hi"`,
+			"Cookie":       "session=abc",
+			"Content-Type": "application/x-www-form-urlencoded",
+		},
+		Data: []byte("{\"note\":\"line\nbreak\",\"n\":5}"),
+	}
+	if !reflect.DeepEqual(reqs[0], want) {
+		t.Errorf("got %+v\nwant %+v", reqs[0], want)
+	}
+	if reqs[1].Method != "GET" || reqs[1].Url != "https://example.org/search?q=x&r=a+b" || len(reqs[1].Data) != 0 {
+		t.Errorf("unexpected -G request %+v", reqs[1])
+	}
+	if reqs[1].Headers["Authorization"] != "Basic dXNlcjpwYXNz" {
+		t.Errorf("unexpected Authorization %q", reqs[1].Headers["Authorization"])
+	}
+	if reqs[2].Method != "DELETE" || reqs[2].Url != "http://example.org/item" {
+		t.Errorf("unexpected request %+v", reqs[2])
+	}
+
+	for _, bad := range []string{"curl", "curl 'https://a", "curl -F a=b https://a", "wget https://a", "curl https://a https://b"} {
+		if _, err := ImportRequests([]byte(bad), RequestFormatCurl, "https"); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestShellSplit_ANSICQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`$'a\x41b'`, "aAb"},
+		{`$'\x7'`, "\x07"},
+		{`$'\x7g'`, "\x07g"},
+		{`$'\x414'`, "A4"},
+		{`$'\xz'`, `\xz`},
+		{`$'\u263a'`, "\u263a"},
+		{`$'\u41!'`, "A!"},
+	}
+	for _, tc := range tests {
+		words, err := shellSplit(tc.in)
+		if err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if len(words) != 1 || len(words[0]) != 1 || words[0][0] != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.in, words, tc.want)
+		}
+	}
+}
+
+func TestSelectRequest(t *testing.T) {
+	reqs := []Request{
+		{Method: "GET", Url: "https://example.org/"},
+		{Method: "POST", Url: "https://example.org/login"},
+	}
+	for selector, want := range map[string]int{"": 0, "2": 1, "POST": 1, "/login": 1, "example": 0} {
+		got, err := SelectRequest(reqs, selector)
+		if err != nil || got.Url != reqs[want].Url {
+			t.Errorf("%q selected %+v (%v), want entry %d", selector, got, err, want)
+		}
+	}
+	for _, selector := range []string{"0", "3", "PUT"} {
+		if _, err := SelectRequest(reqs, selector); err == nil {
+			t.Errorf("%q: expected an error", selector)
+		}
+	}
+}
+
+func TestMarkFuzzParameters(t *testing.T) {
+	req := Request{
+		Url: "https://example.org/?id=1&page=2",
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"Cookie":       "session=abc; lang=en",
+			"X-Api-Key":    "k",
+		},
+		Data: []byte(`{"user": {"name": "bob", "age": 30}, "tags": ["a"]}`),
+	}
+	missing := MarkFuzzParameters(&req, []string{"id", "name", "age", "lang", "X-Api-Key", "nope"})
+	if !reflect.DeepEqual(missing, []string{"nope"}) {
+		t.Errorf("missing = %v", missing)
+	}
+	if req.Url != "https://example.org/?id=FUZZ&page=2" {
+		t.Errorf("url = %s", req.Url)
+	}
+	if want := `{"user": {"name":"FUZZ", "age":"FUZZ"}, "tags": ["a"]}`; string(req.Data) != want {
+		t.Errorf("data = %s, want %s", req.Data, want)
+	}
+	if req.Headers["Cookie"] != "session=abc; lang=FUZZ" || req.Headers["X-Api-Key"] != "FUZZ" {
+		t.Errorf("headers = %v", req.Headers)
+	}
+
+	form := Request{Headers: map[string]string{}, Data: []byte("user=bob&pass=secret")}
+	MarkFuzzParameters(&form, []string{"pass"})
+	if string(form.Data) != "user=bob&pass=FUZZ" {
+		t.Errorf("form data = %s", form.Data)
+	}
+}
@@ -0,0 +1,227 @@
+package ffuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Injection point encodings, set as Request.Encoding by AutoInjectionRequests
+const (
+	EncodingJSON  = "json"
+	EncodingXML   = "xml"
+	EncodingForm  = "form"
+	EncodingQuery = "query"
+)
+
+// injectionPoint is a span of a request part to be replaced with the keyword
+type injectionPoint struct {
+	start, end int
+	// quote is set for JSON literals (numbers, booleans, null), which are replaced with a string
+	quote    bool
+	encoding string
+}
+
+// AutoInjectionRequests returns an array of requests, each with one of the
+// injection points of basereq replaced by the FUZZ keyword: every query
+// parameter value, and every JSON leaf, XML text node and attribute or form
+// field value of the body, depending on its content type. Request.Encoding is
+// set to the type of the injection point so the input can be encoded to fit it.
+func AutoInjectionRequests(basereq *Request) []Request {
+	var reqs []Request
+	keyword := "FUZZ"
+
+	if q := strings.Index(basereq.Url, "?"); q != -1 {
+		query := basereq.Url[q+1:]
+		if h := strings.Index(query, "#"); h != -1 {
+			query = query[:h]
+		}
+		for _, p := range formInjectionPoints(query, EncodingQuery) {
+			newreq := CopyRequest(basereq)
+			newreq.Url = basereq.Url[:q+1] + injectAt(query, keyword, p) + basereq.Url[q+1+len(query):]
+			newreq.Encoding = p.encoding
+			reqs = append(reqs, newreq)
+		}
+	}
+
+	data := string(basereq.Data)
+	for _, p := range bodyInjectionPoints(data, headerValue(basereq.Headers, "Content-Type")) {
+		newreq := CopyRequest(basereq)
+		newreq.Data = []byte(injectAt(data, keyword, p))
+		newreq.Encoding = p.encoding
+		reqs = append(reqs, newreq)
+	}
+	return reqs
+}
+
+// EncodeInjection encodes an input value for the type of injection point it is placed in
+func EncodeInjection(encoding string, value []byte) []byte {
+	switch encoding {
+	case EncodingJSON:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(string(value))
+		// Strip the trailing newline and the quotes, the keyword is already quoted
+		encoded := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		return encoded[1 : len(encoded)-1]
+	case EncodingXML:
+		var buf bytes.Buffer
+		_ = xml.EscapeText(&buf, value)
+		return buf.Bytes()
+	case EncodingForm, EncodingQuery:
+		return []byte(url.QueryEscape(string(value)))
+	}
+	return value
+}
+
+func injectAt(input string, keyword string, p injectionPoint) string {
+	if p.quote {
+		keyword = `"` + keyword + `"`
+	}
+	return input[:p.start] + keyword + input[p.end:]
+}
+
+// headerValue returns the value of header name, matched case insensitively
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// bodyInjectionPoints finds the injection points of a request body by its content
+// type, or by its first character if there is no Content-Type header.
+func bodyInjectionPoints(data string, contentType string) []injectionPoint {
+	ct := strings.ToLower(contentType)
+	trimmed := strings.TrimSpace(data)
+	switch {
+	case trimmed == "":
+		return nil
+	case strings.Contains(ct, "json"):
+		return jsonInjectionPoints(data)
+	case strings.Contains(ct, "xml"):
+		return xmlInjectionPoints(data)
+	case strings.Contains(ct, "x-www-form-urlencoded"):
+		return formInjectionPoints(data, EncodingForm)
+	case ct != "":
+		return nil
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		return jsonInjectionPoints(data)
+	case strings.HasPrefix(trimmed, "<"):
+		return xmlInjectionPoints(data)
+	case strings.Contains(trimmed, "="):
+		return formInjectionPoints(data, EncodingForm)
+	}
+	return nil
+}
+
+// formInjectionPoints returns the value of each name=value pair of an urlencoded string
+func formInjectionPoints(data string, encoding string) []injectionPoint {
+	var points []injectionPoint
+	offset := 0
+	for _, pair := range strings.Split(data, "&") {
+		if eq := strings.Index(pair, "="); eq > 0 {
+			points = append(points, injectionPoint{start: offset + eq + 1, end: offset + len(pair), encoding: encoding})
+		}
+		offset += len(pair) + 1
+	}
+	return points
+}
+
+// jsonInjectionPoints returns the contents of each string leaf (between the
+// quotes) and each number, boolean and null of a JSON document. Object keys are
+// left alone.
+func jsonInjectionPoints(data string) []injectionPoint {
+	if !json.Valid([]byte(data)) {
+		return nil
+	}
+	var points []injectionPoint
+	// inObject tracks the enclosing containers, expectKey whether the next string is an object key
+	var inObject []bool
+	expectKey := false
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case ' ', '\t', '\r', '\n':
+		case '{':
+			inObject = append(inObject, true)
+			expectKey = true
+		case '[':
+			inObject = append(inObject, false)
+		case '}', ']':
+			inObject = inObject[:len(inObject)-1]
+		case ',':
+			expectKey = len(inObject) > 0 && inObject[len(inObject)-1]
+		case ':':
+			expectKey = false
+		case '"':
+			end := i + 1
+			for ; end < len(data) && data[end] != '"'; end++ {
+				if data[end] == '\\' {
+					end++
+				}
+			}
+			if !expectKey {
+				points = append(points, injectionPoint{start: i + 1, end: end, encoding: EncodingJSON})
+			}
+			i = end
+		default:
+			end := i
+			for end < len(data) && !strings.ContainsRune(",}] \t\r\n", rune(data[end])) {
+				end++
+			}
+			points = append(points, injectionPoint{start: i, end: end, quote: true, encoding: EncodingJSON})
+			i = end - 1
+		}
+	}
+	return points
+}
+
+// xmlAttribute matches an attribute of an XML start tag, submatch 2 or 3 is its value
+var xmlAttribute = regexp.MustCompile(`([^\s=<>/]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// xmlInjectionPoints returns each non-blank text node and each attribute value
+// (namespace declarations excluded) of an XML document.
+func xmlInjectionPoints(data string) []injectionPoint {
+	var points []injectionPoint
+	dec := xml.NewDecoder(strings.NewReader(data))
+	for {
+		start := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return points
+		}
+		if err != nil {
+			return nil
+		}
+		end := int(dec.InputOffset())
+		switch tok.(type) {
+		case xml.CharData:
+			text := data[start:end]
+			trimmed := strings.TrimSpace(text)
+			if trimmed == "" {
+				continue
+			}
+			lead := strings.Index(text, trimmed)
+			points = append(points, injectionPoint{start: start + lead, end: start + lead + len(trimmed), encoding: EncodingXML})
+		case xml.StartElement:
+			for _, m := range xmlAttribute.FindAllStringSubmatchIndex(data[start:end], -1) {
+				name := data[start+m[2] : start+m[3]]
+				if name == "xmlns" || strings.HasPrefix(name, "xmlns:") {
+					continue
+				}
+				value := 4
+				if m[value] == -1 {
+					value = 6
+				}
+				points = append(points, injectionPoint{start: start + m[value], end: start + m[value+1], encoding: EncodingXML})
+			}
+		}
+	}
+}
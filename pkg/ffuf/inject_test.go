@@ -0,0 +1,102 @@
+package ffuf
+
+import (
+	"testing"
+)
+
+func TestAutoInjectionRequests(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		url         string
+		contentType string
+		data        string
+		want        []string
+	}{
+		{
+			name: "query",
+			url:  "http://example.com/?a=1&flag&b=#frag",
+			want: []string{
+				"http://example.com/?a=FUZZ&flag&b=#frag",
+				"http://example.com/?a=1&flag&b=FUZZ#frag",
+			},
+		},
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			data:        `{"a": "x", "b": [1, true, {"c": null}], "d\"": "y\"z"}`,
+			want: []string{
+				`{"a": "FUZZ", "b": [1, true, {"c": null}], "d\"": "y\"z"}`,
+				`{"a": "x", "b": ["FUZZ", true, {"c": null}], "d\"": "y\"z"}`,
+				`{"a": "x", "b": [1, "FUZZ", {"c": null}], "d\"": "y\"z"}`,
+				`{"a": "x", "b": [1, true, {"c": "FUZZ"}], "d\"": "y\"z"}`,
+				`{"a": "x", "b": [1, true, {"c": null}], "d\"": "FUZZ"}`,
+			},
+		},
+		{
+			name: "sniffed xml",
+			data: `<?xml version="1.0"?><r xmlns="urn:x"><u id='7' name="bob">text</u> <e/></r>`,
+			want: []string{
+				`<?xml version="1.0"?><r xmlns="urn:x"><u id='FUZZ' name="bob">text</u> <e/></r>`,
+				`<?xml version="1.0"?><r xmlns="urn:x"><u id='7' name="FUZZ">text</u> <e/></r>`,
+				`<?xml version="1.0"?><r xmlns="urn:x"><u id='7' name="bob">FUZZ</u> <e/></r>`,
+			},
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			data:        "user=bob&pass=",
+			want:        []string{"user=FUZZ&pass=", "user=bob&pass=FUZZ"},
+		},
+		{
+			name: "unknown content",
+			url:  "http://example.com/",
+			data: "plain text",
+		},
+		{
+			name:        "invalid json",
+			contentType: "application/json",
+			data:        `{"a": `,
+		},
+	} {
+		url := test.url
+		if url == "" {
+			url = "http://example.com/"
+		}
+		base := Request{Method: "POST", Url: url, Headers: map[string]string{}, Data: []byte(test.data)}
+		if test.contentType != "" {
+			base.Headers["content-type"] = test.contentType
+		}
+		reqs := AutoInjectionRequests(&base)
+		if len(reqs) != len(test.want) {
+			t.Errorf("%s: got %d requests, want %d", test.name, len(reqs), len(test.want))
+			continue
+		}
+		for i, req := range reqs {
+			got := string(req.Data)
+			if test.data == "" {
+				got = req.Url
+			}
+			if got != test.want[i] {
+				t.Errorf("%s: request %d = %s, want %s", test.name, i, got, test.want[i])
+			}
+			if req.Encoding == "" {
+				t.Errorf("%s: request %d has no encoding", test.name, i)
+			}
+		}
+	}
+}
+
+func TestEncodeInjection(t *testing.T) {
+	value := []byte(`a"<b>&c d`)
+	for encoding, want := range map[string]string{
+		EncodingJSON:  `a\"<b>&c d`,
+		EncodingXML:   `a&#34;&lt;b&gt;&amp;c d`,
+		EncodingForm:  `a%22%3Cb%3E%26c+d`,
+		EncodingQuery: `a%22%3Cb%3E%26c+d`,
+		"":            `a"<b>&c d`,
+	} {
+		if got := string(EncodeInjection(encoding, value)); got != want {
+			t.Errorf("EncodeInjection(%q) = %s, want %s", encoding, got, want)
+		}
+	}
+}
@@ -12,6 +12,10 @@ type MatcherManager interface {
 	AddPerDomainFilter(domain string, name string, option string) error
 	RemoveFilter(name string)
 	AddMatcher(name string, option string) error
+	// AddFilterProvider and AddMatcherProvider add a filter or matcher that was
+	// created elsewhere, like the ones of the plugins
+	AddFilterProvider(name string, filter FilterProvider)
+	AddMatcherProvider(name string, matcher FilterProvider)
 	GetFilters() map[string]FilterProvider
 	GetMatchers() map[string]FilterProvider
 	FiltersForDomain(domain string) map[string]FilterProvider
@@ -37,6 +41,19 @@ type RunnerProvider interface {
 	Dump(req *Request) ([]byte, error)
 }
 
+// BatchRunnerProvider is implemented by runners that can send several inputs in
+// a single request. The engine groups BatchSize() inputs, executes the request
+// built by PrepareBatch once and handles the responses SplitBatch returns, one
+// per input and in the same order, as if they had been requested separately.
+// With -param-mine the engine bisects the batches whose response differs from
+// the autocalibrated baseline instead of splitting them.
+type BatchRunnerProvider interface {
+	RunnerProvider
+	BatchSize() int
+	PrepareBatch(inputs []map[string][]byte, basereq *Request) (Request, error)
+	SplitBatch(resp Response, inputs []map[string][]byte) []Response
+}
+
 // InputProvider interface handles the input data for RunnerProvider
 type InputProvider interface {
 	ActivateKeywords([]string)
@@ -50,6 +67,13 @@ type InputProvider interface {
 	Total() int
 }
 
+// DepthInputProvider is implemented by the input providers with inputs that
+// depend on the recursion depth, like the -recursion-wordlist ones. SetDepth is
+// called with the depth of every queue job as it starts.
+type DepthInputProvider interface {
+	SetDepth(depth int)
+}
+
 // InternalInputProvider interface handles providing input data to InputProvider
 type InternalInputProvider interface {
 	Keyword() string
@@ -86,6 +110,20 @@ type OutputProvider interface {
 	Cycle()
 }
 
+// RunRecorder is implemented by the output providers that keep track of the
+// run a result belongs to. SetRun is called with the hash of the FFUFHASH
+// history entry of every job as it starts.
+type RunRecorder interface {
+	SetRun(hash string)
+}
+
+// ResultObserver is implemented by the output providers that report the results
+// they keep. The function set with SetResultObserver is called with every
+// result as the output records it, its -od file and -baseline diff included.
+type ResultObserver interface {
+	SetResultObserver(observe func(Result))
+}
+
 // AuditLogger is responsible for providing auditing output of every request/response
 // sent and recieved by FFUF
 type AuditLogger interface {
@@ -93,6 +131,40 @@ type AuditLogger interface {
 	Write(data interface{}) error
 }
 
+// AuditHeader is written to the audit log at the start of every job. ConfigHash
+// is the hash of the FFUFHASH history entry of the job, the FFUFHASH values of
+// its requests start with the first five characters of it.
+type AuditHeader struct {
+	Version    string    `json:"version"`
+	ConfigHash string    `json:"confighash"`
+	Url        string    `json:"url"`
+	Time       time.Time `json:"time"`
+}
+
+// HookProvider runs the -hook commands and webhooks on the events of the scan.
+// Fire must not block the caller, Close waits for the hooks fired so far.
+type HookProvider interface {
+	Fire(event HookEvent)
+	Close()
+}
+
+// PluginProvider runs the out-of-process plugins of the scan. Mutate lets the
+// mutator plugins modify a request after it was prepared and before it is sent,
+// Close stops the plugins.
+type PluginProvider interface {
+	Mutate(req *Request) error
+	Close()
+}
+
+// ScriptProvider runs the hooks of the -script. OnRequest lets on_request modify
+// a request after it was prepared and before it is sent. OnResponse evaluates a
+// response with on_response and adds the data it returns to the scraper data of
+// the response.
+type ScriptProvider interface {
+	OnRequest(req *Request) error
+	OnResponse(resp *Response) (ScriptVerdict, error)
+}
+
 type Scraper interface {
 	Execute(resp *Response, matched bool) []ScraperResult
 	AppendFromFile(path string) error
@@ -119,7 +191,19 @@ type Result struct {
 	ScraperData      map[string][]string `json:"scraper"`
 	ResultFile       string              `json:"resultfile"`
 	Host             string              `json:"host"`
+	RemoteAddr       string              `json:"remoteaddr"`
 	HTMLColor        string              `json:"-"`
+	// Diff is how the result differs from the -baseline, or in ffuf diff
+	Diff *ResultDiff `json:"diff,omitempty"`
+	// ClusterSize is the number of results in the -cluster this result is the
+	// representative of, when it is printed
+	ClusterSize int `json:"cluster_size,omitempty"`
+	// Simhash of the body, to cluster near-identical results with -cluster
+	Simhash uint64 `json:"-"`
+	// Response is the matched exchange, only kept for the HAR output and the
+	// pkg/scan results as it holds both of the bodies. Not serialized to the
+	// other output formats.
+	Response *Response `json:"-"`
 	// Printed reports whether this result has already been shown to the user
 	// (streamed live, or surfaced in the "N new matches" summary on resume). It
 	// is the single source of truth for the interactive console's pending count,
@@ -127,3 +211,13 @@ type Result struct {
 	// count. Not serialized to any output format.
 	Printed bool `json:"-"`
 }
+
+// ResultDiff classifies a result compared to a baseline as "new", "gone" or
+// "changed". The baseline values are those of the baseline result a changed
+// result was compared to.
+type ResultDiff struct {
+	Change                string `json:"change"`
+	BaselineStatusCode    int64  `json:"baseline_status"`
+	BaselineContentLength int64  `json:"baseline_length"`
+	BaselineContentWords  int64  `json:"baseline_words"`
+}
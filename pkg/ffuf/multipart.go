@@ -0,0 +1,70 @@
+package ffuf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MultipartPart is a part of the multipart/form-data body built with -F. A
+// part with a File or a Filename is sent as a file upload. Keywords may be used
+// in every member, the body is built from them for each request, see
+// runner.SimpleRunner.Prepare.
+type MultipartPart struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	File        string `json:"file,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"type,omitempty"`
+}
+
+// multipartAttribute matches the ;filename= and ;type= attributes of a -F value
+var multipartAttribute = regexp.MustCompile(`(?i);(filename|type)=`)
+
+// ParseMultipartPart parses a curl style -F value: "name=value", "name=@/path/to/file"
+// for the contents of a file, each optionally followed by ";filename=..." and
+// ";type=..." attributes.
+func ParseMultipartPart(spec string) (MultipartPart, error) {
+	var part MultipartPart
+	name, rest, found := strings.Cut(spec, "=")
+	if !found || name == "" {
+		return part, fmt.Errorf("multipart part (-F) %q is not in the form name=value", spec)
+	}
+	part.Name = name
+	attrs := multipartAttribute.FindAllStringSubmatchIndex(rest, -1)
+	value := rest
+	if len(attrs) > 0 {
+		value = rest[:attrs[0][0]]
+	}
+	for i, m := range attrs {
+		end := len(rest)
+		if i+1 < len(attrs) {
+			end = attrs[i+1][0]
+		}
+		switch strings.ToLower(rest[m[2]:m[3]]) {
+		case "filename":
+			part.Filename = rest[m[1]:end]
+		case "type":
+			part.ContentType = rest[m[1]:end]
+		}
+	}
+	if strings.HasPrefix(value, "@") {
+		part.File = value[1:]
+		if part.File == "" {
+			return part, fmt.Errorf("multipart part (-F) %q is missing the file path after @", spec)
+		}
+	} else {
+		part.Value = value
+	}
+	return part, nil
+}
+
+// ContainsKeyword reports whether keyword is used in any member of the part
+func (p MultipartPart) ContainsKeyword(keyword string) bool {
+	for _, s := range []string{p.Name, p.Value, p.File, p.Filename, p.ContentType} {
+		if strings.Contains(s, keyword) {
+			return true
+		}
+	}
+	return false
+}
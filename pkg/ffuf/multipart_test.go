@@ -0,0 +1,28 @@
+package ffuf
+
+import (
+	"testing"
+)
+
+func TestParseMultipartPart(t *testing.T) {
+	for spec, want := range map[string]MultipartPart{
+		"name=value":                           {Name: "name", Value: "value"},
+		"json={\"a\";1}":                       {Name: "json", Value: "{\"a\";1}"},
+		"file=@/tmp/FUZZ.png":                  {Name: "file", File: "/tmp/FUZZ.png"},
+		"file=@/tmp/x;type=image/png":          {Name: "file", File: "/tmp/x", ContentType: "image/png"},
+		"f=<?php ?>;filename=a.FUZZ;type=FUZZ": {Name: "f", Value: "<?php ?>", Filename: "a.FUZZ", ContentType: "FUZZ"},
+		"f=;FileName=x.txt":                    {Name: "f", Filename: "x.txt"},
+	} {
+		got, err := ParseMultipartPart(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+		} else if got != want {
+			t.Errorf("%s: got %+v, want %+v", spec, got, want)
+		}
+	}
+	for _, spec := range []string{"novalue", "=value", "file=@"} {
+		if _, err := ParseMultipartPart(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
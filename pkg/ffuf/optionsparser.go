@@ -1,10 +1,10 @@
 package ffuf
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
-	"io"
+	"net"
 	"net/textproto"
 	"net/url"
 	"os"
@@ -41,9 +41,13 @@ type HTTPOptions struct {
 	Method            string   `json:"method" ffuf:"X" section:"http" usage:"HTTP method to use"`
 	ProxyURL          string   `json:"proxy_url" ffuf:"x" section:"http" usage:"Proxy URL (SOCKS5 or HTTP). For example: http://127.0.0.1:8080 or socks5://127.0.0.1:8080"`
 	Raw               bool     `json:"raw" ffuf:"raw" section:"http" usage:"Do not encode URI"`
-	Recursion         bool     `json:"recursion" ffuf:"recursion" section:"http" usage:"Scan recursively. Only FUZZ keyword is supported, and URL (-u) has to end in it."`
+	Recursion         bool     `json:"recursion" ffuf:"recursion" section:"http" usage:"Scan recursively. Without -recursion-template only FUZZ keyword is supported, and URL (-u) has to end in it."`
 	RecursionDepth    int      `json:"recursion_depth" ffuf:"recursion-depth" section:"http" usage:"Maximum recursion depth."`
-	RecursionStrategy string   `json:"recursion_strategy" ffuf:"recursion-strategy" section:"http" usage:"Recursion strategy: \"default\" for a redirect based, and \"greedy\" to recurse on all matches"`
+	RecursionMatch    []string `json:"recursion_match" ffuf:"recursion-match" kind:"multistring" section:"http" usage:"Matcher a response has to match for the \"content\" recursion strategy to recurse into it, as NAME:VALUE with the name of a matcher, eg. 'regexp:<a href'. Can be used multiple times"`
+	RecursionOrder    string   `json:"recursion_order" ffuf:"recursion-order" section:"http" usage:"Order of the recursion queue: \"breadth\" for the order the jobs were found in, \"depth\" to finish the subdirectories of a job first, and \"score\" for the most promising jobs first"`
+	RecursionStrategy string   `json:"recursion_strategy" ffuf:"recursion-strategy" section:"http" usage:"Recursion strategy: \"default\" for a redirect based, \"greedy\" to recurse on all matches, and \"content\" to also recurse on the matches that look like directories"`
+	RecursionStatus   string   `json:"recursion_status" ffuf:"recursion-status" section:"http" usage:"Status codes of the matches the \"content\" recursion strategy recurses into when they are a directory listing, or are not named like a file and match the -recursion-match matchers"`
+	RecursionTemplate []string `json:"recursion_template" ffuf:"recursion-template" kind:"multistring" section:"http" usage:"Where the recursion jobs put the value they were found with, as KEYWORD:LOCATION[:SEPARATOR] with the location path, host or param, eg. 'FUZZ:path' for /api/FUZZ/users or 'VHOST:host' for the Host header VHOST.example.org. Can be used multiple times"`
 	ReplayProxyURL    string   `json:"replay_proxy_url" ffuf:"replay-proxy" section:"http" usage:"Replay matched requests using this proxy."`
 	SNI               string   `json:"sni" ffuf:"sni" section:"http" usage:"Target TLS SNI, does not support FUZZ keyword"`
 	Timeout           int      `json:"timeout" ffuf:"timeout" section:"http" usage:"HTTP request timeout in seconds."`
@@ -51,6 +55,15 @@ type HTTPOptions struct {
 	Http2             bool     `json:"http2" ffuf:"http2" section:"http" usage:"Use HTTP2 protocol"`
 	ClientCert        string   `json:"client-cert" ffuf:"cc" section:"http" usage:"Client cert for authentication. Client key needs to be defined as well for this to work"`
 	ClientKey         string   `json:"client-key" ffuf:"ck" section:"http" usage:"Client key for authentication. Client certificate needs to be defined as well for this to work"`
+	Resolve           []string `json:"resolve" ffuf:"resolve" kind:"multistring" section:"http" usage:"Connect to an address instead of resolving the host, curl style \"host:port:addr\". Port can be \"*\". Multiple -resolve flags are accepted."`
+	DNSResolver       string   `json:"dns_resolver" ffuf:"dns-resolver" section:"http" usage:"Custom DNS server (host[:port]) used to resolve target hosts"`
+	UnixSocket        string   `json:"unix_socket" ffuf:"unix-socket" section:"http" usage:"Connect to a Unix domain socket instead of the URL host. A \"unix:///path/to.sock:/path\" URL (-u) implies this"`
+	WSTimeout         int      `json:"ws_timeout" ffuf:"ws-timeout" section:"http" usage:"Time in milliseconds to collect WebSocket reply frames after sending the message (-d). Used with ws:// and wss:// URLs"`
+	WSFrames          int      `json:"ws_frames" ffuf:"ws-frames" section:"http" usage:"Stop collecting WebSocket replies after this many messages, 0 waits for the full -ws-timeout"`
+	GraphQL           string   `json:"graphql" ffuf:"graphql" section:"http" usage:"GraphQL query template. It is sent in a JSON envelope {\"query\": ...} and keywords in it are substituted before encoding"`
+	GraphQLVariables  string   `json:"graphql_variables" ffuf:"graphql-vars" section:"http" usage:"GraphQL variables as a JSON object, for use with -graphql. Keywords are JSON escaped"`
+	GraphQLBatch      int      `json:"graphql_batch" ffuf:"graphql-batch" section:"http" usage:"Number of inputs to send in a single GraphQL request as aliased copies of the query. Keywords may only be used in the query"`
+	Multipart         []string `json:"multipart" ffuf:"F" kind:"multistring" section:"http" usage:"Multipart form-data part, curl style: \"name=value\" or \"name=@/path/to/file\", optionally followed by \";filename=...\" and \";type=...\". Keywords are substituted in all of it, file path included. Multiple -F flags are accepted."`
 	// Preflights/Postflights are not plain flags: -preflight and -preflight-var
 	// bind positionally (a -preflight-var attaches to the preceding -preflight), so
 	// they are appended by the extraFlags Func callbacks in flags.go rather than a
@@ -62,92 +75,136 @@ type HTTPOptions struct {
 }
 
 type GeneralOptions struct {
+	Api                       string   `json:"api" ffuf:"api" section:"general" usage:"Serve a JSON API to control the scan, like the interactive console, and stream its results and progress at this address. For example: 127.0.0.1:8008"`
+	ApiToken                  string   `json:"-" ffuf:"api-token" section:"general" usage:"Token the -api requests authenticate with, as a bearer token. Generated and printed if not given"`
 	AutoCalibration           bool     `json:"autocalibration" ffuf:"ac" section:"general" usage:"Automatically calibrate filtering options"`
 	AutoCalibrationKeyword    string   `json:"autocalibration_keyword" ffuf:"ack" section:"general" usage:"Autocalibration keyword"`
 	AutoCalibrationPerHost    bool     `json:"autocalibration_per_host" ffuf:"ach" section:"general" usage:"Per host autocalibration"`
 	AutoCalibrationStrategies []string `json:"autocalibration_strategies" ffuf:"acs" kind:"csvreplace" section:"general" usage:"Custom auto-calibration strategies. Can be used multiple times. Implies -ac"`
 	AutoCalibrationStrings    []string `json:"autocalibration_strings" ffuf:"acc" kind:"multistring" section:"general" usage:"Custom auto-calibration string. Can be used multiple times. Implies -ac"`
+	Batch                     string   `toml:"-" json:"-" ffuf:"batch" section:"general" usage:"Run the jobs of a TOML batch manifest instead of a single job. The jobs start from the ffufrc, the other flags do not apply to them"`
 	Colors                    bool     `json:"colors" ffuf:"c" section:"general" usage:"Colorize output."`
 	ConfigFile                string   `toml:"-" json:"config_file" ffuf:"config" section:"general" usage:"Load configuration from a file"`
 	Delay                     string   `json:"delay" ffuf:"p" section:"general" usage:"Seconds of delay between requests, or a range of random delay. For example \"0.1\" or \"0.1-2.0\""`
-	Json                      bool     `json:"json" ffuf:"json" section:"general" usage:"JSON output, printing newline-delimited JSON records"`
-	MaxTime                   int      `json:"maxtime" ffuf:"maxtime" section:"general" usage:"Maximum running time in seconds for entire process."`
-	MaxTimeJob                int      `json:"maxtime_job" ffuf:"maxtime-job" section:"general" usage:"Maximum running time in seconds per job."`
-	Noninteractive            bool     `json:"noninteractive" ffuf:"noninteractive" section:"general" usage:"Disable the interactive console functionality"`
-	Quiet                     bool     `json:"quiet" ffuf:"s" section:"general" usage:"Do not print additional information (silent mode)"`
-	Rate                      int      `json:"rate" ffuf:"rate" section:"general" usage:"Rate of requests per second"`
-	ScraperFile               string   `json:"scraperfile" ffuf:"scraperfile" section:"general" usage:"Custom scraper file path"`
-	Scrapers                  string   `json:"scrapers" ffuf:"scrapers" section:"general" usage:"Active scraper groups"`
-	Searchhash                string   `json:"-" ffuf:"search" section:"general" usage:"Search for a FFUFHASH payload from ffuf history"`
-	ShowVersion               bool     `toml:"-" json:"-" ffuf:"V" section:"general" usage:"Show version information."`
-	StopOn403                 bool     `json:"stop_on_403" ffuf:"sf" section:"general" usage:"Stop when > 95% of responses return 403 Forbidden"`
-	StopOnAll                 bool     `json:"stop_on_all" ffuf:"sa" section:"general" usage:"Stop on all error cases. Implies -sf and -se."`
-	StopOnErrors              bool     `json:"stop_on_errors" ffuf:"se" section:"general" usage:"Stop on spurious errors"`
-	Threads                   int      `json:"threads" ffuf:"t" section:"general" usage:"Number of concurrent threads."`
-	Verbose                   bool     `json:"verbose" ffuf:"v" section:"general" usage:"Verbose output, printing full URL and redirect location (if any) with the results."`
+	Hooks                     []string `json:"hooks" ffuf:"hook" kind:"multistring" section:"general" usage:"Run a command with the event JSON on stdin, or POST the event JSON to a URL, on events of the scan: EVENTS:COMMAND or EVENTS:URL. The events are match, job-queued, job-started, job-finished, stopped, error or all, comma separated. Can be used multiple times"`
+	HookConcurrency           int      `json:"hook_concurrency" ffuf:"hook-concurrency" section:"general" usage:"Number of hooks run at the same time"`
+	HookTimeout               int      `json:"hook_timeout" ffuf:"hook-timeout" section:"general" usage:"Seconds a hook may run before it is stopped"`
+	// Plugins are not flags, they are declared in the config file as
+	// [[general.plugins]] tables
+	Plugins        []PluginConfig `json:"plugins" toml:"plugins"`
+	Json           bool           `json:"json" ffuf:"json" section:"general" usage:"JSON output, printing newline-delimited JSON records"`
+	MaxTime        int            `json:"maxtime" ffuf:"maxtime" section:"general" usage:"Maximum running time in seconds for entire process."`
+	MaxTimeJob     int            `json:"maxtime_job" ffuf:"maxtime-job" section:"general" usage:"Maximum running time in seconds per job."`
+	Metrics        string         `json:"metrics" ffuf:"metrics" section:"general" usage:"Serve Prometheus metrics of the scan at /metrics on this address. For example: 127.0.0.1:9090"`
+	Noninteractive bool           `json:"noninteractive" ffuf:"noninteractive" section:"general" usage:"Disable the interactive console functionality"`
+	Quiet          bool           `json:"quiet" ffuf:"s" section:"general" usage:"Do not print additional information (silent mode)"`
+	Rate           int            `json:"rate" ffuf:"rate" section:"general" usage:"Rate of requests per second"`
+	ScraperFile    string         `json:"scraperfile" ffuf:"scraperfile" section:"general" usage:"Custom scraper file path"`
+	Scrapers       string         `json:"scrapers" ffuf:"scrapers" section:"general" usage:"Active scraper groups"`
+	Script         string         `json:"script" ffuf:"script" section:"general" usage:"Starlark script defining on_request(req) to modify the requests and on_response(resp) to match, filter or scrape the responses"`
+	Searchhash     string         `json:"-" ffuf:"search" section:"general" usage:"Search for a FFUFHASH payload from ffuf history"`
+	ShowVersion    bool           `toml:"-" json:"-" ffuf:"V" section:"general" usage:"Show version information."`
+	StopOn403      bool           `json:"stop_on_403" ffuf:"sf" section:"general" usage:"Stop when > 95% of responses return 403 Forbidden"`
+	StopOnAll      bool           `json:"stop_on_all" ffuf:"sa" section:"general" usage:"Stop on all error cases. Implies -sf and -se."`
+	StopOnErrors   bool           `json:"stop_on_errors" ffuf:"se" section:"general" usage:"Stop on spurious errors"`
+	Threads        int            `json:"threads" ffuf:"t" section:"general" usage:"Number of concurrent threads."`
+	Verbose        bool           `json:"verbose" ffuf:"v" section:"general" usage:"Verbose output, printing full URL and redirect location (if any) with the results."`
 }
 
 type InputOptions struct {
 	DirSearchCompat        bool     `json:"dirsearch_compat" ffuf:"D" section:"input" usage:"DirSearch wordlist compatibility mode. Used in conjunction with -e flag."`
 	Encoders               []string `json:"encoders" ffuf:"enc" kind:"wordlist" section:"input" usage:"Encoders for keywords, eg. 'FUZZ:urlencode b64encode'"`
 	Extensions             string   `json:"extensions" ffuf:"e" section:"input" usage:"Comma separated list of extensions. Extends FUZZ keyword."`
+	GraphQLHarvest         string   `json:"graphql_harvest" ffuf:"graphql-harvest" section:"input" usage:"Keyword whose wordlist is extended with the field and type names suggested in GraphQL error messages (\"Did you mean ...\")"`
 	IgnoreWordlistComments bool     `json:"ignore_wordlist_comments" ffuf:"ic" section:"input" usage:"Ignore wordlist comments"`
-	InputMode              string   `json:"input_mode" ffuf:"mode" section:"input" usage:"Multi-wordlist operation mode. Available modes: clusterbomb, pitchfork, sniper"`
+	InputMode              string   `json:"input_mode" ffuf:"mode" section:"input" usage:"Multi-wordlist operation mode. Available modes: clusterbomb, pitchfork, sniper, auto (sniper over every query parameter and JSON, XML or form body value)"`
 	InputNum               int      `json:"input_num" ffuf:"input-num" section:"input" usage:"Number of inputs to test. Used in conjunction with --input-cmd."`
 	InputShell             string   `json:"input_shell" ffuf:"input-shell" section:"input" usage:"Shell to be used for running command"`
 	Inputcommands          []string `json:"input_commands" ffuf:"input-cmd" kind:"multistring" section:"input" usage:"Command producing the input. --input-num is required when using this input method. Overrides -w."`
-	Request                string   `json:"request_file" ffuf:"request" section:"input" usage:"File containing the raw http request"`
+	ParamBatch             int      `json:"param_batch" ffuf:"param-batch" section:"input" usage:"Number of candidate parameter names to send in a single request with -param-mine"`
+	ParamMine              string   `json:"param_mine" ffuf:"param-mine" section:"input" usage:"Discover hidden parameters: the FUZZ wordlist entries are candidate names, sent in batches and bisected when the response differs from the autocalibrated baseline. Location: query, form, json, header"`
+	RecursionExtensions    []string `json:"recursion_extensions" ffuf:"recursion-extensions" kind:"multistring" section:"input" usage:"Comma separated list of extensions for the FUZZ keyword of the recursion jobs from a depth on, as DEPTH:EXTENSIONS, eg. '2:.php,.bak'. Can be used multiple times"`
+	RecursionWordlists     []string `json:"recursion_wordlists" ffuf:"recursion-wordlist" kind:"multistring" section:"input" usage:"Wordlist for the FUZZ keyword of the recursion jobs from a depth on, as DEPTH:PATH, eg. '2:/path/to/small.txt'. Can be used multiple times"`
+	Request                string   `json:"request_file" ffuf:"request" section:"input" usage:"File containing the raw http request, or a HAR archive, curl command lines or Burp Suite saved items to import it from"`
+	RequestEntry           string   `json:"request_entry" ffuf:"request-entry" section:"input" usage:"Request to use of a -request file holding several: a 1-based index, or a string its method and URL contain"`
+	RequestFormat          string   `json:"request_format" ffuf:"request-format" section:"input" usage:"Format of the -request file: raw, har, curl, burp. Detected from the contents if not set"`
+	RequestFuzz            []string `json:"request_fuzz" ffuf:"request-fuzz" kind:"csvreplace" section:"input" usage:"Comma separated list of query, form, JSON, cookie and header parameters of the -request file whose value is replaced with FUZZ. Can be used multiple times"`
 	RequestProto           string   `json:"request_proto" ffuf:"request-proto" section:"input" usage:"Protocol to use along with raw request"`
 	Wordlists              []string `json:"wordlists" ffuf:"w" kind:"wordlist" section:"input" usage:"Wordlist file path and (optional) keyword separated by colon. eg. '/path/to/wordlist:KEYWORD'"`
 }
 
 type OutputOptions struct {
-	AuditLog            string `json:"audit_log" ffuf:"audit-log" section:"output" usage:"Write audit log containing all requests, responses and config"`
-	DebugLog            string `json:"debug_log" ffuf:"debug-log" section:"output" usage:"Write all of the internal logging to the specified file."`
-	OutputDirectory     string `json:"output_directory" ffuf:"od" section:"output" usage:"Directory path to store matched results to."`
-	OutputFile          string `json:"output_file" ffuf:"o" section:"output" usage:"Write output to file"`
-	OutputFormat        string `json:"output_format" ffuf:"of" section:"output" usage:"Output file format. Available formats: json, ejson, html, md, csv, ecsv (or, 'all' for all formats)"`
-	OutputSkipEmptyFile bool   `json:"output_skip_empty" ffuf:"or" section:"output" usage:"Don't create the output file if we don't have results"`
+	AuditLog            string   `json:"audit_log" ffuf:"audit-log" section:"output" usage:"Write audit log containing all requests, responses and config"`
+	AuditLogCompress    string   `json:"audit_log_compress" ffuf:"audit-log-compress" section:"output" usage:"Compress the audit log while writing it: gzip, zstd"`
+	AuditLogMaxAge      int      `json:"audit_log_max_age" ffuf:"audit-log-max-age" section:"output" usage:"Rotate the audit log after this many seconds"`
+	AuditLogMaxSize     string   `json:"audit_log_max_size" ffuf:"audit-log-max-size" section:"output" usage:"Rotate the audit log when it grows over this size, eg. 500M or 2G"`
+	AuditLogNoBody      bool     `json:"audit_log_no_body" ffuf:"audit-log-no-body" section:"output" usage:"Leave the request and response bodies out of the audit log"`
+	AuditLogOnly        []string `json:"audit_log_only" ffuf:"audit-log-only" kind:"csvreplace" section:"output" usage:"Only write the exchanges that match or fail to the audit log: matched, errored. Comma separated"`
+	Baseline            string   `json:"baseline" ffuf:"baseline" section:"output" usage:"JSON output file of an earlier run to compare to: only the new, changed and gone results are output"`
+	Cluster             bool     `json:"cluster" ffuf:"cluster" section:"output" usage:"Cluster near-identical results by status, size, word and line counts and body similarity, and show one result of each cluster with the number of results in it"`
+	DebugLog            string   `json:"debug_log" ffuf:"debug-log" section:"output" usage:"Write all of the internal logging to the specified file."`
+	OutputDirectory     string   `json:"output_directory" ffuf:"od" section:"output" usage:"Directory path to store matched results to."`
+	OutputFile          string   `json:"output_file" ffuf:"o" section:"output" usage:"Write output to file"`
+	OutputFormat        string   `json:"output_format" ffuf:"of" section:"output" usage:"Output file format. Available formats: json, ejson, html, md, csv, ecsv, har (or, 'all' for all formats)"`
+	OutputRequestDir    string   `json:"output_request_directory" ffuf:"odr" section:"output" usage:"Directory path to store the request of each match to, as a raw request file usable with -request."`
+	OutputSkipEmptyFile bool     `json:"output_skip_empty" ffuf:"or" section:"output" usage:"Don't create the output file if we don't have results"`
+	ResultsDB           string   `json:"results_db" ffuf:"results-db" section:"output" usage:"Store every result along with the run metadata in this results database file, to search across runs with \"ffuf results query\""`
 }
 
 type FilterOptions struct {
-	Mode   string `json:"mode" ffuf:"fmode" section:"filter" usage:"Filter set operator. Either of: and, or"`
-	Lines  string `json:"lines" ffuf:"fl" section:"filter" usage:"Filter by amount of lines in response. Comma separated list of line counts and ranges"`
-	Regexp string `json:"regexp" ffuf:"fr" section:"filter" usage:"Filter regexp"`
-	Size   string `json:"size" ffuf:"fs" section:"filter" usage:"Filter HTTP response size. Comma separated list of sizes and ranges"`
-	Status string `json:"status" ffuf:"fc" section:"filter" usage:"Filter HTTP status codes from response. Comma separated list of codes and ranges"`
-	Time   string `json:"time" ffuf:"ft" section:"filter" usage:"Filter by number of milliseconds to the first response byte, either greater or less than. EG: >100 or <100"`
-	Words  string `json:"words" ffuf:"fw" section:"filter" usage:"Filter by amount of words in response. Comma separated list of word counts and ranges"`
+	Mode           string `json:"mode" ffuf:"fmode" section:"filter" usage:"Filter set operator. Either of: and, or"`
+	Lines          string `json:"lines" ffuf:"fl" section:"filter" usage:"Filter by amount of lines in response. Comma separated list of line counts and ranges"`
+	Regexp         string `json:"regexp" ffuf:"fr" section:"filter" usage:"Filter regexp"`
+	Size           string `json:"size" ffuf:"fs" section:"filter" usage:"Filter HTTP response size. Comma separated list of sizes and ranges"`
+	Status         string `json:"status" ffuf:"fc" section:"filter" usage:"Filter HTTP status codes from response. Comma separated list of codes and ranges"`
+	Time           string `json:"time" ffuf:"ft" section:"filter" usage:"Filter by number of milliseconds to the first response byte, either greater or less than. EG: >100 or <100"`
+	Words          string `json:"words" ffuf:"fw" section:"filter" usage:"Filter by amount of words in response. Comma separated list of word counts and ranges"`
+	GraphQLErrors  string `json:"graphql_errors" ffuf:"fge" section:"filter" usage:"Filter GraphQL responses with errors (true) or without them (false)"`
+	GraphQLMessage string `json:"graphql_message" ffuf:"fgm" section:"filter" usage:"Filter GraphQL error message regexp"`
+	GraphQLData    string `json:"graphql_data" ffuf:"fgd" section:"filter" usage:"Filter GraphQL responses with a value at a data path, eg. \"user.email\". Comma separated list of paths"`
 }
 
 type MatcherOptions struct {
-	Mode   string `json:"mode" ffuf:"mmode" section:"matcher" usage:"Matcher set operator. Either of: and, or"`
-	Lines  string `json:"lines" ffuf:"ml" section:"matcher" usage:"Match amount of lines in response"`
-	Regexp string `json:"regexp" ffuf:"mr" section:"matcher" usage:"Match regexp"`
-	Size   string `json:"size" ffuf:"ms" section:"matcher" usage:"Match HTTP response size"`
-	Status string `json:"status" ffuf:"mc" section:"matcher" usage:"Match HTTP status codes, or \"all\" for everything."`
-	Time   string `json:"time" ffuf:"mt" section:"matcher" usage:"Match how many milliseconds to the first response byte, either greater or less than. EG: >100 or <100"`
-	Words  string `json:"words" ffuf:"mw" section:"matcher" usage:"Match amount of words in response"`
+	Mode           string `json:"mode" ffuf:"mmode" section:"matcher" usage:"Matcher set operator. Either of: and, or"`
+	Lines          string `json:"lines" ffuf:"ml" section:"matcher" usage:"Match amount of lines in response"`
+	Regexp         string `json:"regexp" ffuf:"mr" section:"matcher" usage:"Match regexp"`
+	Size           string `json:"size" ffuf:"ms" section:"matcher" usage:"Match HTTP response size"`
+	Status         string `json:"status" ffuf:"mc" section:"matcher" usage:"Match HTTP status codes, or \"all\" for everything."`
+	Time           string `json:"time" ffuf:"mt" section:"matcher" usage:"Match how many milliseconds to the first response byte, either greater or less than. EG: >100 or <100"`
+	Words          string `json:"words" ffuf:"mw" section:"matcher" usage:"Match amount of words in response"`
+	GraphQLErrors  string `json:"graphql_errors" ffuf:"mge" section:"matcher" usage:"Match GraphQL responses with errors (true) or without them (false)"`
+	GraphQLMessage string `json:"graphql_message" ffuf:"mgm" section:"matcher" usage:"Match GraphQL error message regexp"`
+	GraphQLData    string `json:"graphql_data" ffuf:"mgd" section:"matcher" usage:"Match GraphQL responses with a value at a data path, eg. \"user.email\". Comma separated list of paths"`
 }
 
 // NewConfigOptions returns a newly created ConfigOptions struct with default values
 func NewConfigOptions() *ConfigOptions {
 	c := &ConfigOptions{}
 	c.Filter.Mode = "or"
+	c.Filter.GraphQLErrors = ""
+	c.Filter.GraphQLMessage = ""
+	c.Filter.GraphQLData = ""
 	c.Filter.Lines = ""
 	c.Filter.Regexp = ""
 	c.Filter.Size = ""
 	c.Filter.Status = ""
 	c.Filter.Time = ""
 	c.Filter.Words = ""
+	c.General.Api = ""
+	c.General.ApiToken = ""
 	c.General.AutoCalibration = false
 	c.General.AutoCalibrationKeyword = "FUZZ"
 	c.General.AutoCalibrationStrategies = []string{"basic"}
 	c.General.Colors = false
 	c.General.Delay = ""
+	c.General.Hooks = []string{}
+	c.General.HookConcurrency = 4
+	c.General.HookTimeout = 10
+	c.General.Plugins = make([]PluginConfig, 0)
 	c.General.Json = false
 	c.General.MaxTime = 0
 	c.General.MaxTimeJob = 0
+	c.General.Metrics = ""
 	c.General.Noninteractive = false
 	c.General.Quiet = false
 	c.General.Rate = 0
@@ -172,19 +229,36 @@ func NewConfigOptions() *ConfigOptions {
 	c.HTTP.Raw = false
 	c.HTTP.Recursion = false
 	c.HTTP.RecursionDepth = 0
+	c.HTTP.RecursionOrder = "breadth"
+	c.HTTP.RecursionStatus = "200,401,403"
 	c.HTTP.RecursionStrategy = "default"
 	c.HTTP.ReplayProxyURL = ""
 	c.HTTP.Timeout = 10
 	c.HTTP.SNI = ""
 	c.HTTP.URL = ""
 	c.HTTP.Http2 = false
+	c.HTTP.Resolve = []string{}
+	c.HTTP.DNSResolver = ""
+	c.HTTP.UnixSocket = ""
+	c.HTTP.WSTimeout = 1000
+	c.HTTP.WSFrames = 0
+	c.HTTP.GraphQL = ""
+	c.HTTP.GraphQLVariables = ""
+	c.HTTP.GraphQLBatch = 1
+	c.HTTP.Multipart = []string{}
 	c.Input.DirSearchCompat = false
 	c.Input.Encoders = []string{}
 	c.Input.Extensions = ""
+	c.Input.GraphQLHarvest = ""
 	c.Input.IgnoreWordlistComments = false
 	c.Input.InputMode = "clusterbomb"
 	c.Input.InputNum = 100
+	c.Input.ParamBatch = 256
+	c.Input.ParamMine = ""
 	c.Input.Request = ""
+	c.Input.RequestEntry = ""
+	c.Input.RequestFormat = ""
+	c.Input.RequestFuzz = []string{}
 	c.Input.RequestProto = "https"
 	c.Matcher.Mode = "or"
 	c.Matcher.Lines = ""
@@ -193,12 +267,24 @@ func NewConfigOptions() *ConfigOptions {
 	c.Matcher.Status = "200-299,301,302,307,401,403,405,500"
 	c.Matcher.Time = ""
 	c.Matcher.Words = ""
+	c.Matcher.GraphQLErrors = ""
+	c.Matcher.GraphQLMessage = ""
+	c.Matcher.GraphQLData = ""
 	c.Output.AuditLog = ""
+	c.Output.AuditLogCompress = ""
+	c.Output.AuditLogMaxAge = 0
+	c.Output.AuditLogMaxSize = ""
+	c.Output.AuditLogNoBody = false
+	c.Output.AuditLogOnly = []string{}
+	c.Output.Baseline = ""
+	c.Output.Cluster = false
 	c.Output.DebugLog = ""
 	c.Output.OutputDirectory = ""
 	c.Output.OutputFile = ""
 	c.Output.OutputFormat = "json"
+	c.Output.OutputRequestDir = ""
 	c.Output.OutputSkipEmptyFile = false
+	c.Output.ResultsDB = ""
 	return c
 }
 
@@ -257,7 +343,7 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	conf.InputMode = parseOpts.Input.InputMode
 
 	validmode := false
-	for _, mode := range []string{"clusterbomb", "pitchfork", "sniper"} {
+	for _, mode := range []string{"clusterbomb", "pitchfork", "sniper", "auto"} {
 		if conf.InputMode == mode {
 			validmode = true
 		}
@@ -267,16 +353,18 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	}
 
 	template := ""
-	// sniper mode needs some additional checking
-	if conf.InputMode == "sniper" {
-		template = "§"
+	// sniper and auto modes need some additional checking
+	if conf.InputMode == "sniper" || conf.InputMode == "auto" {
+		if conf.InputMode == "sniper" {
+			template = "§"
+		}
 
 		if len(parseOpts.Input.Wordlists) > 1 {
-			errs.Add(fmt.Errorf("sniper mode only supports one wordlist"))
+			errs.Add(fmt.Errorf("%s mode only supports one wordlist", conf.InputMode))
 		}
 
 		if len(parseOpts.Input.Inputcommands) > 1 {
-			errs.Add(fmt.Errorf("sniper mode only supports one input command"))
+			errs.Add(fmt.Errorf("%s mode only supports one input command", conf.InputMode))
 		}
 	}
 	tmpEncoders := make(map[string]string)
@@ -323,8 +411,8 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 			wl[0] = fullpath
 		}
 		if len(wl) == 2 {
-			if conf.InputMode == "sniper" {
-				errs.Add(fmt.Errorf("sniper mode does not support wordlist keywords"))
+			if conf.InputMode == "sniper" || conf.InputMode == "auto" {
+				errs.Add(fmt.Errorf("%s mode does not support wordlist keywords", conf.InputMode))
 			} else {
 				newp := InputProviderConfig{
 					Name:    "wordlist",
@@ -359,8 +447,8 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	for _, v := range parseOpts.Input.Inputcommands {
 		ic := strings.SplitN(v, ":", 2)
 		if len(ic) == 2 {
-			if conf.InputMode == "sniper" {
-				errs.Add(fmt.Errorf("sniper mode does not support command keywords"))
+			if conf.InputMode == "sniper" || conf.InputMode == "auto" {
+				errs.Add(fmt.Errorf("%s mode does not support command keywords", conf.InputMode))
 			} else {
 				newp := InputProviderConfig{
 					Name:    "command",
@@ -390,6 +478,30 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		}
 	}
 
+	conf.Plugins = clonePlugins(parseOpts.General.Plugins)
+	for _, err := range validatePlugins(conf.Plugins) {
+		errs.Add(err)
+	}
+	for _, plugin := range conf.Plugins {
+		if !plugin.HasRole(PluginInput) {
+			continue
+		}
+		if conf.InputMode == "sniper" || conf.InputMode == "auto" {
+			errs.Add(fmt.Errorf("%s mode does not support input plugins", conf.InputMode))
+			continue
+		}
+		newp := InputProviderConfig{
+			Name:    "plugin",
+			Value:   plugin.Name,
+			Keyword: plugin.Keyword,
+		}
+		enc, ok := tmpEncoders[plugin.Keyword]
+		if ok {
+			newp.Encoders = enc
+		}
+		conf.InputProviders = append(conf.InputProviders, newp)
+	}
+
 	if len(conf.InputProviders) == 0 {
 		errs.Add(fmt.Errorf("Either -w or --input-cmd flag is required"))
 	}
@@ -408,6 +520,40 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		conf.Url = parseOpts.HTTP.URL
 	}
 
+	// Prepare Unix domain socket target
+	conf.UnixSocket = parseOpts.HTTP.UnixSocket
+	if strings.HasPrefix(conf.Url, "unix://") {
+		socket, rewritten := parseUnixURL(conf.Url)
+		if conf.UnixSocket != "" && conf.UnixSocket != socket {
+			errs.Add(fmt.Errorf("Unix socket defined by -unix-socket (%s) and the URL (%s) differ", conf.UnixSocket, socket))
+		}
+		conf.UnixSocket = socket
+		conf.Url = rewritten
+	}
+	if conf.UnixSocket != "" && (parseOpts.HTTP.ProxyURL != "" || parseOpts.HTTP.ReplayProxyURL != "") {
+		errs.Add(fmt.Errorf("Cannot use a proxy (-x) or a replay proxy (-replay-proxy) when connecting to a Unix socket"))
+	}
+
+	// Prepare WebSocket target
+	conf.WebSocket = IsWebSocketURL(conf.Url)
+	conf.WSTimeout = parseOpts.HTTP.WSTimeout
+	conf.WSFrames = parseOpts.HTTP.WSFrames
+	if conf.WSTimeout < 1 {
+		errs.Add(fmt.Errorf("-ws-timeout must be a positive number of milliseconds, got %d", conf.WSTimeout))
+	}
+	if conf.WSFrames < 0 {
+		errs.Add(fmt.Errorf("-ws-frames must not be negative, got %d", conf.WSFrames))
+	}
+	// The status matcher of the retained options (conf.Options), which the
+	// matchers are set up from, is adjusted for WebSocket and -param-mine below
+	// without changing the options of the caller.
+	matcherStatus := parseOpts.Matcher.Status
+	if conf.WebSocket && matcherStatus == NewConfigOptions().Matcher.Status {
+		// An accepted handshake is reported as 101 Switching Protocols, which the
+		// default status matcher would otherwise hide.
+		matcherStatus += ",101"
+	}
+
 	// Prepare SNI
 	if parseOpts.HTTP.SNI != "" {
 		conf.SNI = parseOpts.HTTP.SNI
@@ -421,6 +567,24 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		conf.ClientKey = parseOpts.HTTP.ClientKey
 	}
 
+	// Prepare the connection address overrides
+	for _, v := range parseOpts.HTTP.Resolve {
+		hostport, addr, err := parseResolveSpec(v)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		conf.Resolve[hostport] = addr
+	}
+	if parseOpts.HTTP.DNSResolver != "" {
+		resolver, err := normalizeDNSResolver(parseOpts.HTTP.DNSResolver)
+		if err != nil {
+			errs.Add(err)
+		} else {
+			conf.DNSResolver = resolver
+		}
+	}
+
 	//Prepare headers and make canonical
 	for _, v := range effectiveHeaders {
 		hs := strings.SplitN(v, ":", 2)
@@ -496,7 +660,7 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	//Check the output file format option
 	if parseOpts.Output.OutputFile != "" {
 		//No need to check / error out if output file isn't defined
-		outputFormats := []string{"all", "json", "ejson", "html", "md", "csv", "ecsv"}
+		outputFormats := []string{"all", "json", "ejson", "html", "md", "csv", "ecsv", "har"}
 		found := false
 		for _, f := range outputFormats {
 			if f == parseOpts.Output.OutputFormat {
@@ -509,6 +673,32 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		}
 	}
 
+	// Audit log rotation, compression and capture
+	if parseOpts.Output.AuditLogMaxSize != "" {
+		size, err := parseByteSize(parseOpts.Output.AuditLogMaxSize)
+		if err != nil {
+			errs.Add(fmt.Errorf("Bad -audit-log-max-size: %s", err))
+		}
+		conf.AuditLogMaxSize = size
+	}
+	if parseOpts.Output.AuditLogMaxAge < 0 {
+		errs.Add(fmt.Errorf("-audit-log-max-age can not be negative"))
+	}
+	conf.AuditLogMaxAge = parseOpts.Output.AuditLogMaxAge
+	switch parseOpts.Output.AuditLogCompress {
+	case "", "gzip", "zstd":
+		conf.AuditLogCompress = parseOpts.Output.AuditLogCompress
+	default:
+		errs.Add(fmt.Errorf("Unknown audit log compression (-audit-log-compress): %s", parseOpts.Output.AuditLogCompress))
+	}
+	conf.AuditLogNoBody = parseOpts.Output.AuditLogNoBody
+	for _, only := range parseOpts.Output.AuditLogOnly {
+		if only != "matched" && only != "errored" {
+			errs.Add(fmt.Errorf("Unknown -audit-log-only value: %s, expected matched or errored", only))
+		}
+	}
+	conf.AuditLogOnly = parseOpts.Output.AuditLogOnly
+
 	// Auto-calibration strings
 	if len(parseOpts.General.AutoCalibrationStrings) > 0 {
 		conf.AutoCalibrationStrings = parseOpts.General.AutoCalibrationStrings
@@ -553,6 +743,43 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		conf.Data = parseOpts.HTTP.Data
 	}
 
+	// Prepare multipart body
+	for _, spec := range parseOpts.HTTP.Multipart {
+		part, err := ParseMultipartPart(spec)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		conf.Multipart = append(conf.Multipart, part)
+	}
+	if len(conf.Multipart) > 0 && conf.Data != "" {
+		errs.Add(fmt.Errorf("Cannot use POST data (-d) with multipart parts (-F), the request body is built from the parts"))
+	}
+	if len(conf.Multipart) > 0 && conf.WebSocket {
+		errs.Add(fmt.Errorf("Cannot use multipart parts (-F) with WebSocket URLs, the message is sent from POST data (-d)"))
+	}
+
+	// Prepare GraphQL request
+	conf.GraphQLQuery = parseOpts.HTTP.GraphQL
+	conf.GraphQLVariables = parseOpts.HTTP.GraphQLVariables
+	conf.GraphQLBatch = parseOpts.HTTP.GraphQLBatch
+	conf.GraphQLHarvest = parseOpts.Input.GraphQLHarvest
+	if conf.GraphQLQuery == "" {
+		if conf.GraphQLVariables != "" || conf.GraphQLBatch > 1 || conf.GraphQLHarvest != "" {
+			errs.Add(fmt.Errorf("-graphql-vars, -graphql-batch and -graphql-harvest require a GraphQL query (-graphql)"))
+		}
+	} else {
+		if conf.Data != "" || len(conf.Multipart) > 0 {
+			errs.Add(fmt.Errorf("Cannot use POST data (-d) or multipart parts (-F) with -graphql, the request body is built from the query"))
+		}
+		if conf.WebSocket {
+			errs.Add(fmt.Errorf("-graphql is not supported with WebSocket URLs"))
+		}
+		if conf.GraphQLBatch < 1 {
+			errs.Add(fmt.Errorf("-graphql-batch must be at least 1, got %d", conf.GraphQLBatch))
+		}
+	}
+
 	// Common stuff
 	conf.IgnoreWordlistComments = parseOpts.Input.IgnoreWordlistComments
 	conf.DirSearchCompat = parseOpts.Input.DirSearchCompat
@@ -563,7 +790,11 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	conf.AuditLog = parseOpts.Output.AuditLog
 	conf.OutputFile = parseOpts.Output.OutputFile
 	conf.OutputDirectory = parseOpts.Output.OutputDirectory
+	conf.OutputRequestDir = parseOpts.Output.OutputRequestDir
 	conf.OutputSkipEmptyFile = parseOpts.Output.OutputSkipEmptyFile
+	conf.ResultsDB = parseOpts.Output.ResultsDB
+	conf.Baseline = parseOpts.Output.Baseline
+	conf.Cluster = parseOpts.Output.Cluster
 	conf.IgnoreBody = parseOpts.HTTP.IgnoreBody
 	conf.Quiet = parseOpts.General.Quiet
 	conf.ScraperFile = parseOpts.General.ScraperFile
@@ -575,6 +806,7 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	conf.Raw = parseOpts.HTTP.Raw
 	conf.Recursion = parseOpts.HTTP.Recursion
 	conf.RecursionDepth = parseOpts.HTTP.RecursionDepth
+	conf.RecursionOrder = parseOpts.HTTP.RecursionOrder
 	conf.RecursionStrategy = parseOpts.HTTP.RecursionStrategy
 	conf.AutoCalibration = parseOpts.General.AutoCalibration
 	conf.AutoCalibrationPerHost = parseOpts.General.AutoCalibrationPerHost
@@ -584,6 +816,36 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	conf.MaxTime = parseOpts.General.MaxTime
 	conf.MaxTimeJob = parseOpts.General.MaxTimeJob
 	conf.Noninteractive = parseOpts.General.Noninteractive
+	conf.Api = parseOpts.General.Api
+	conf.ApiToken = parseOpts.General.ApiToken
+	if conf.Api != "" {
+		if _, _, err := net.SplitHostPort(conf.Api); err != nil {
+			errs.Add(fmt.Errorf("Bad -api address: %s", err))
+		}
+	}
+	for _, spec := range parseOpts.General.Hooks {
+		hook, err := ParseHook(spec)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		conf.Hooks = append(conf.Hooks, hook)
+	}
+	conf.HookConcurrency = parseOpts.General.HookConcurrency
+	if conf.HookConcurrency < 1 {
+		errs.Add(fmt.Errorf("-hook-concurrency must be at least 1"))
+	}
+	conf.HookTimeout = parseOpts.General.HookTimeout
+	if conf.HookTimeout < 1 {
+		errs.Add(fmt.Errorf("-hook-timeout must be positive"))
+	}
+	conf.Script = parseOpts.General.Script
+	conf.Metrics = parseOpts.General.Metrics
+	if conf.Metrics != "" {
+		if _, _, err := net.SplitHostPort(conf.Metrics); err != nil {
+			errs.Add(fmt.Errorf("Bad -metrics address: %s", err))
+		}
+	}
 	conf.Verbose = parseOpts.General.Verbose
 	conf.Json = parseOpts.General.Json
 	conf.Http2 = parseOpts.HTTP.Http2
@@ -657,9 +919,44 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		conf.AutoCalibration = true
 	}
 
+	// Prepare parameter mining
+	conf.ParamMine = parseOpts.Input.ParamMine
+	conf.ParamBatch = parseOpts.Input.ParamBatch
+	if conf.ParamMine != "" {
+		if !StrInSlice(conf.ParamMine, []string{"query", "form", "json", "header"}) {
+			errs.Add(fmt.Errorf("-param-mine location %s not recognized, valid values are: query, form, json, header", conf.ParamMine))
+		}
+		if conf.ParamBatch < 1 {
+			errs.Add(fmt.Errorf("-param-batch must be at least 1, got %d", conf.ParamBatch))
+		}
+		if conf.InputMode != "clusterbomb" || len(conf.InputProviders) != 1 || conf.InputProviders[0].Keyword != "FUZZ" {
+			errs.Add(fmt.Errorf("-param-mine needs a single wordlist of candidate names for the FUZZ keyword"))
+		}
+		if conf.GraphQLQuery != "" || conf.WebSocket {
+			errs.Add(fmt.Errorf("-param-mine is not supported with -graphql or WebSocket URLs"))
+		}
+		if len(conf.Multipart) > 0 && (conf.ParamMine == "form" || conf.ParamMine == "json") {
+			errs.Add(fmt.Errorf("-param-mine %s can not add parameters to a multipart body (-F)", conf.ParamMine))
+		}
+		if conf.ParamMine == "json" && conf.Data != "" {
+			var body map[string]interface{}
+			if json.Unmarshal([]byte(conf.Data), &body) != nil {
+				errs.Add(fmt.Errorf("-param-mine json needs the request body to be a JSON object"))
+			}
+		}
+		// The candidates are compared to the baseline the autocalibration finds,
+		// so a change of the status code must not be hidden by the status matcher.
+		conf.AutoCalibration = true
+		if matcherStatus == NewConfigOptions().Matcher.Status {
+			matcherStatus = "all"
+		}
+	}
+
 	// Handle copy as curl situation where POST method is implied by --data flag. If method is set to anything but GET, NOOP
-	if len(conf.Data) > 0 &&
+	if (len(conf.Data) > 0 || len(conf.GraphQLQuery) > 0 || len(conf.Multipart) > 0) &&
 		conf.Method == "GET" &&
+		// a WebSocket message is sent after a GET handshake
+		!conf.WebSocket &&
 		//don't modify the method automatically if a request file is being used as input
 		len(parseOpts.Input.Request) == 0 {
 
@@ -677,6 +974,10 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 			} else {
 				newInputProviders = append(newInputProviders, provider)
 			}
+		} else if conf.InputMode == "auto" || conf.ParamMine != "" {
+			// The keyword is placed in the injection points found in the request, or
+			// its inputs are added to the request as parameter names
+			newInputProviders = append(newInputProviders, provider)
 		} else {
 			if !keywordPresent(provider.Keyword, &conf) {
 				errmsg := fmt.Sprintf("Keyword %s defined, but not found in headers, method, URL or POST data.", provider.Keyword)
@@ -688,19 +989,119 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	}
 	conf.InputProviders = newInputProviders
 
-	// If sniper mode, ensure there is no FUZZ keyword
-	if conf.InputMode == "sniper" {
+	if conf.GraphQLBatch > 1 {
+		// The inputs of a batch share a single request, so only the query can differ
+		for _, provider := range conf.InputProviders {
+			if requestKeywordPresent(provider.Keyword, &conf) || strings.Contains(conf.GraphQLVariables, provider.Keyword) {
+				errs.Add(fmt.Errorf("Keyword %s is used outside of the GraphQL query, which -graphql-batch does not support", provider.Keyword))
+			}
+		}
+	}
+	if conf.GraphQLHarvest != "" {
+		found := false
+		for i := range conf.InputProviders {
+			if conf.InputProviders[i].Keyword == conf.GraphQLHarvest && conf.InputProviders[i].Name == "wordlist" {
+				conf.InputProviders[i].Name = "harvest"
+				found = true
+			}
+		}
+		if !found {
+			errs.Add(fmt.Errorf("-graphql-harvest %s needs a wordlist (-w) for that keyword to start from", conf.GraphQLHarvest))
+		} else if len(conf.InputProviders) > 1 {
+			// The positions of the combinations with the other keywords would
+			// shift as the wordlist grows
+			errs.Add(fmt.Errorf("-graphql-harvest %s needs to be the only keyword", conf.GraphQLHarvest))
+		}
+	}
+
+	// If sniper or auto mode, ensure there is no FUZZ keyword
+	if conf.InputMode == "sniper" || conf.InputMode == "auto" {
 		if keywordPresent("FUZZ", &conf) {
-			errs.Add(fmt.Errorf("FUZZ keyword defined, but we are using sniper mode."))
+			errs.Add(fmt.Errorf("FUZZ keyword defined, but we are using %s mode.", conf.InputMode))
+		}
+	}
+	if conf.ParamMine != "" && keywordPresent("FUZZ", &conf) {
+		errs.Add(fmt.Errorf("FUZZ keyword defined, but -param-mine adds the candidate names to the request itself."))
+	}
+	if conf.InputMode == "auto" {
+		basereq := BaseRequest(&conf)
+		if len(AutoInjectionRequests(&basereq)) == 0 {
+			errs.Add(fmt.Errorf("auto mode found no query parameters or JSON, XML or form body values to fuzz"))
 		}
 	}
 
 	// Do checks for recursion mode
 	if parseOpts.HTTP.Recursion {
-		if !strings.HasSuffix(conf.Url, "FUZZ") {
+		for _, v := range parseOpts.HTTP.RecursionTemplate {
+			t, err := ParseRecursionTemplate(v)
+			if err != nil {
+				errs.Add(err)
+				continue
+			}
+			conf.RecursionTemplates = append(conf.RecursionTemplates, t)
+		}
+		basereq := BaseRequest(&conf)
+		for _, t := range conf.RecursionTemplates {
+			if !t.InRequest(&basereq) {
+				errs.Add(fmt.Errorf("-recursion-template keyword %s is not in the %s of the request", t.Keyword, t.Location))
+			}
+			// The default strategy recurses into the redirects to the URL with a
+			// slash appended, which only a keyword at the end of the URL is found by
+			if conf.RecursionStrategy == "default" && (t.Location != RecursionPath || !strings.HasSuffix(conf.Url, t.Keyword)) {
+				errs.Add(fmt.Errorf("-recursion-template %s:%s needs -recursion-strategy greedy or content, the default strategy only recurses into a path keyword at the end of the URL", t.Keyword, t.Location))
+			}
+		}
+		if len(parseOpts.HTTP.RecursionTemplate) == 0 && !strings.HasSuffix(conf.Url, "FUZZ") {
 			errmsg := "When using -recursion the URL (-u) must end with FUZZ keyword."
 			errs.Add(fmt.Errorf("%s", errmsg))
 		}
+		switch conf.RecursionStrategy {
+		case "default", "greedy", "content":
+		default:
+			errs.Add(fmt.Errorf("Unknown recursion strategy (-recursion-strategy): %s", conf.RecursionStrategy))
+		}
+	} else if len(parseOpts.Input.RecursionWordlists) > 0 || len(parseOpts.Input.RecursionExtensions) > 0 {
+		errs.Add(fmt.Errorf("-recursion-wordlist and -recursion-extensions need -recursion"))
+	} else if len(parseOpts.HTTP.RecursionTemplate) > 0 {
+		errs.Add(fmt.Errorf("-recursion-template needs -recursion"))
+	}
+	conf.RecursionStatus = parseOpts.HTTP.RecursionStatus
+	conf.RecursionMatch = cloneStrings(parseOpts.HTTP.RecursionMatch)
+	for _, m := range conf.RecursionMatch {
+		if name, value, ok := strings.Cut(m, ":"); !ok || name == "" || value == "" {
+			errs.Add(fmt.Errorf("-recursion-match value %q must be in the format \"NAME:VALUE\"", m))
+		}
+	}
+	conf.RecursionWordlists = make(map[int]string)
+	for _, v := range parseOpts.Input.RecursionWordlists {
+		depth, path, err := parseDepthValue("-recursion-wordlist", v)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		if !FileExists(path) {
+			errs.Add(fmt.Errorf("-recursion-wordlist %s does not exist", path))
+		}
+		conf.RecursionWordlists[depth] = path
+	}
+	conf.RecursionExtensions = make(map[int][]string)
+	for _, v := range parseOpts.Input.RecursionExtensions {
+		depth, extensions, err := parseDepthValue("-recursion-extensions", v)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		// An empty list turns the extensions off from the depth on
+		conf.RecursionExtensions[depth] = make([]string, 0)
+		if extensions != "" {
+			conf.RecursionExtensions[depth] = strings.Split(extensions, ",")
+		}
+	}
+	// The history entries of older versions have no recursion order
+	switch conf.RecursionOrder {
+	case "", "breadth", "depth", "score":
+	default:
+		errs.Add(fmt.Errorf("Unknown recursion order (-recursion-order): %s", conf.RecursionOrder))
 	}
 
 	// Make verbose mutually exclusive with json
@@ -715,13 +1116,27 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	optsCopy := *parseOpts
 	optsCopy.HTTP.Headers = effectiveHeaders
 	optsCopy.HTTP.Cookies = cloneStrings(parseOpts.HTTP.Cookies)
+	optsCopy.HTTP.Resolve = cloneStrings(parseOpts.HTTP.Resolve)
+	// A unix:// URL is rewritten to a plain http one above, so keep the socket it
+	// named; history reconstructs the request from the rewritten URL.
+	optsCopy.HTTP.UnixSocket = conf.UnixSocket
+	optsCopy.Matcher.Status = matcherStatus
 	optsCopy.Input.Wordlists = cloneStrings(parseOpts.Input.Wordlists)
 	optsCopy.Input.Encoders = cloneStrings(parseOpts.Input.Encoders)
 	optsCopy.Input.Inputcommands = cloneStrings(parseOpts.Input.Inputcommands)
+	optsCopy.Input.RequestFuzz = cloneStrings(parseOpts.Input.RequestFuzz)
+	optsCopy.Input.RecursionWordlists = cloneStrings(parseOpts.Input.RecursionWordlists)
+	optsCopy.Input.RecursionExtensions = cloneStrings(parseOpts.Input.RecursionExtensions)
+	optsCopy.HTTP.RecursionMatch = cloneStrings(parseOpts.HTTP.RecursionMatch)
+	optsCopy.HTTP.RecursionTemplate = cloneStrings(parseOpts.HTTP.RecursionTemplate)
+	optsCopy.HTTP.Multipart = cloneStrings(parseOpts.HTTP.Multipart)
+	optsCopy.Output.AuditLogOnly = cloneStrings(parseOpts.Output.AuditLogOnly)
+	optsCopy.General.Hooks = cloneStrings(parseOpts.General.Hooks)
 	optsCopy.General.AutoCalibrationStrings = cloneStrings(parseOpts.General.AutoCalibrationStrings)
 	optsCopy.General.AutoCalibrationStrategies = cloneStrings(parseOpts.General.AutoCalibrationStrategies)
 	optsCopy.HTTP.Preflights = clonePreflights(parseOpts.HTTP.Preflights)
 	optsCopy.HTTP.Postflights = clonePreflights(parseOpts.HTTP.Postflights)
+	optsCopy.General.Plugins = clonePlugins(parseOpts.General.Plugins)
 	conf.Options = &optsCopy
 	return &conf, errs.ErrorOrNil()
 }
@@ -729,77 +1144,135 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 func parseRawRequest(parseOpts *ConfigOptions, conf *Config) error {
 	conf.RequestFile = parseOpts.Input.Request
 	conf.RequestProto = parseOpts.Input.RequestProto
-	file, err := os.Open(parseOpts.Input.Request)
+	data, err := os.ReadFile(parseOpts.Input.Request)
 	if err != nil {
 		return fmt.Errorf("could not open request file: %s", err)
 	}
-	defer file.Close()
-
-	r := bufio.NewReader(file)
 
-	s, err := r.ReadString('\n')
+	format := parseOpts.Input.RequestFormat
+	if format == "" {
+		format = DetectRequestFormat(data)
+	}
+	reqs, err := ImportRequests(data, format, parseOpts.Input.RequestProto)
 	if err != nil {
-		return fmt.Errorf("could not read request: %s", err)
+		return err
 	}
-	parts := strings.Split(s, " ")
-	if len(parts) < 3 {
-		return fmt.Errorf("malformed request supplied")
+	req, err := SelectRequest(reqs, parseOpts.Input.RequestEntry)
+	if err != nil {
+		return err
+	}
+	if len(parseOpts.Input.RequestFuzz) > 0 {
+		if missing := MarkFuzzParameters(&req, parseOpts.Input.RequestFuzz); len(missing) > 0 {
+			return fmt.Errorf("parameters to fuzz not found in the request: %s", strings.Join(missing, ", "))
+		}
 	}
-	// Set the request Method
-	conf.Method = parts[0]
 
-	for {
-		line, err := r.ReadString('\n')
-		line = strings.TrimSpace(line)
+	conf.Method = req.Method
+	conf.Url = req.Url
+	for h, v := range req.Headers {
+		conf.Headers[h] = v
+	}
+	conf.Data = string(req.Data)
+	return nil
+}
 
-		if err != nil || line == "" {
-			break
+// parseByteSize parses a size in bytes, optionally with a K, M, G or T suffix
+// for the powers of 1024
+func parseByteSize(size string) (int64, error) {
+	s := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(size)), "B")
+	s = strings.TrimSuffix(s, "I")
+	multiplier := int64(1)
+	for i, unit := range "KMGT" {
+		if strings.HasSuffix(s, string(unit)) {
+			s = strings.TrimSuffix(s, string(unit))
+			multiplier = int64(1) << (10 * (i + 1))
 		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a size, eg. 500M or 2G", size)
+	}
+	return n * multiplier, nil
+}
 
-		p := strings.SplitN(line, ":", 2)
-		if len(p) != 2 {
-			continue
-		}
+// parseDepthValue splits a "DEPTH:VALUE" value of flag, like those of
+// -recursion-wordlist, into the recursion depth from 1 up and the value
+func parseDepthValue(flag string, spec string) (int, string, error) {
+	d, value, ok := strings.Cut(spec, ":")
+	depth, err := strconv.Atoi(d)
+	if !ok || err != nil || depth < 1 {
+		return 0, "", fmt.Errorf("%s value %q must be in the format \"DEPTH:VALUE\" with a depth from 1 up", flag, spec)
+	}
+	return depth, value, nil
+}
 
-		if strings.EqualFold(p[0], "content-length") {
-			continue
+// parseResolveSpec parses a curl style -resolve value "host:port:addr" into the
+// "host:port" key the dialer looks up and the address to connect to instead. The
+// address may be a bracketed IPv6 literal, and the port may be "*" to match any.
+func parseResolveSpec(spec string) (string, string, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) != 3 || parts[0] == "" {
+		return "", "", fmt.Errorf("-resolve value %q must be in the format \"host:port:addr\"", spec)
+	}
+	port := parts[1]
+	if port != "*" {
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			return "", "", fmt.Errorf("-resolve value %q has an invalid port %q", spec, port)
 		}
-
-		conf.Headers[strings.TrimSpace(p[0])] = strings.TrimSpace(p[1])
 	}
+	addr := strings.TrimSuffix(strings.TrimPrefix(parts[2], "["), "]")
+	if net.ParseIP(addr) == nil {
+		return "", "", fmt.Errorf("-resolve value %q has an invalid IP address %q", spec, parts[2])
+	}
+	return net.JoinHostPort(strings.ToLower(parts[0]), port), addr, nil
+}
 
-	// Handle case with the full http url in path. In that case,
-	// ignore any host header that we encounter and use the path as request URL
-	if strings.HasPrefix(parts[1], "http") {
-		parsed, err := url.Parse(parts[1])
-		if err != nil {
-			return fmt.Errorf("could not parse request URL: %s", err)
+// parseUnixURL splits a "unix:///path/to.sock:/request/path" URL into the socket
+// path and an equivalent http URL for the request itself. The socket path ends at
+// the first colon; without one the whole value is the socket and the path is "/".
+// The host is "localhost", as in curl, and can be overridden with a Host header.
+func parseUnixURL(rawurl string) (string, string) {
+	rest := strings.TrimPrefix(rawurl, "unix://")
+	socket, path := rest, "/"
+	if idx := strings.Index(rest, ":"); idx != -1 {
+		socket, path = rest[:idx], rest[idx+1:]
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
 		}
-		conf.Url = parts[1]
-		conf.Headers["Host"] = parsed.Host
-	} else {
-		// Build the request URL from the request
-		conf.Url = parseOpts.Input.RequestProto + "://" + conf.Headers["Host"] + parts[1]
 	}
+	return socket, "http://localhost" + path
+}
 
-	// Set the request body
-	b, err := io.ReadAll(r)
+// IsWebSocketURL reports whether rawurl targets a WebSocket endpoint (ws:// or wss://).
+func IsWebSocketURL(rawurl string) bool {
+	lower := strings.ToLower(rawurl)
+	return strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://")
+}
+
+// normalizeDNSResolver validates a -dns-resolver address, defaulting the port to 53.
+func normalizeDNSResolver(resolver string) (string, error) {
+	host, port, err := net.SplitHostPort(resolver)
 	if err != nil {
-		return fmt.Errorf("could not read request body: %s", err)
+		// No port given (or a bare IPv6 address), use the default DNS port
+		host, port = strings.TrimSuffix(strings.TrimPrefix(resolver, "["), "]"), "53"
 	}
-	conf.Data = string(b)
-
-	// Remove newline (typically added by the editor) at the end of the file
-	//nolint:gosimple // we specifically want to remove just a single newline, not all of them
-	if strings.HasSuffix(conf.Data, "\r\n") {
-		conf.Data = conf.Data[:len(conf.Data)-2]
-	} else if strings.HasSuffix(conf.Data, "\n") {
-		conf.Data = conf.Data[:len(conf.Data)-1]
+	if host == "" {
+		return "", fmt.Errorf("-dns-resolver value %q is missing the host", resolver)
 	}
-	return nil
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("-dns-resolver value %q has an invalid port %q", resolver, port)
+	}
+	return net.JoinHostPort(host, port), nil
 }
 
 func keywordPresent(keyword string, conf *Config) bool {
+	if requestKeywordPresent(keyword, conf) {
+		return true
+	}
+	return BodyTemplateContainsKeyword(conf, keyword)
+}
+
+func requestKeywordPresent(keyword string, conf *Config) bool {
 	//Search for keyword from HTTP method, URL and POST data too
 	if strings.Contains(conf.Method, keyword) {
 		return true
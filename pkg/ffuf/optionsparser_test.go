@@ -1,8 +1,14 @@
 package ffuf
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/pelletier/go-toml"
 )
 
 func TestTemplatePresent(t *testing.T) {
@@ -178,3 +184,565 @@ func TestReplayProxyParsing(t *testing.T) {
 		t.Errorf("Expected proxy string with unsupported protocol to fail")
 	}
 }
+
+func TestParseResolveSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		hostport string
+		addr     string
+		wantErr  bool
+	}{
+		{spec: "example.org:443:10.0.0.1", hostport: "example.org:443", addr: "10.0.0.1"},
+		{spec: "Example.ORG:*:10.0.0.1", hostport: "example.org:*", addr: "10.0.0.1"},
+		{spec: "example.org:80:[::1]", hostport: "example.org:80", addr: "::1"},
+		{spec: "example.org:80:2001:db8::1", hostport: "example.org:80", addr: "2001:db8::1"},
+		{spec: "example.org:443", wantErr: true},
+		{spec: "example.org:http:10.0.0.1", wantErr: true},
+		{spec: "example.org:443:not-an-ip", wantErr: true},
+		{spec: ":443:10.0.0.1", wantErr: true},
+	}
+	for _, tc := range tests {
+		hostport, addr, err := parseResolveSpec(tc.spec)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseResolveSpec(%q): expected an error", tc.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseResolveSpec(%q): unexpected error: %s", tc.spec, err)
+			continue
+		}
+		if hostport != tc.hostport || addr != tc.addr {
+			t.Errorf("parseResolveSpec(%q) = %q, %q, want %q, %q", tc.spec, hostport, addr, tc.hostport, tc.addr)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]int64{
+		"1024":  1024,
+		"10K":   10 << 10,
+		"500M":  500 << 20,
+		"500mb": 500 << 20,
+		"2GiB":  2 << 30,
+		"1T":    1 << 40,
+	}
+	for size, want := range tests {
+		got, err := parseByteSize(size)
+		if err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v, want %d", size, got, err, want)
+		}
+	}
+	for _, size := range []string{"", "M", "-1K", "1.5G", "10X"} {
+		if _, err := parseByteSize(size); err == nil {
+			t.Errorf("parseByteSize(%q): expected an error", size)
+		}
+	}
+}
+
+func TestConfigFromOptions_AuditLog(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/FUZZ"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.Output.AuditLog = "audit.log"
+	opts.Output.AuditLogMaxSize = "100M"
+	opts.Output.AuditLogMaxAge = 3600
+	opts.Output.AuditLogCompress = "zstd"
+	opts.Output.AuditLogOnly = []string{"matched", "errored"}
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.AuditLogMaxSize != 100<<20 || conf.AuditLogMaxAge != 3600 || conf.AuditLogCompress != "zstd" || len(conf.AuditLogOnly) != 2 {
+		t.Errorf("unexpected audit log config %d %d %q %v", conf.AuditLogMaxSize, conf.AuditLogMaxAge, conf.AuditLogCompress, conf.AuditLogOnly)
+	}
+
+	for _, set := range []func(o *ConfigOptions){
+		func(o *ConfigOptions) { o.Output.AuditLogMaxSize = "lots" },
+		func(o *ConfigOptions) { o.Output.AuditLogMaxAge = -1 },
+		func(o *ConfigOptions) { o.Output.AuditLogCompress = "bzip2" },
+		func(o *ConfigOptions) { o.Output.AuditLogOnly = []string{"filtered"} },
+	} {
+		bad := NewConfigOptions()
+		bad.HTTP.URL = opts.HTTP.URL
+		bad.Input.Wordlists = opts.Input.Wordlists
+		set(bad)
+		if _, err := ConfigFromOptions(bad, context.Background(), func() {}); err == nil {
+			t.Errorf("expected an error for %+v", bad.Output)
+		}
+	}
+}
+
+func TestParseHook(t *testing.T) {
+	hook, err := ParseHook("match,error:https://hooks.example.org/ffuf")
+	if err != nil || hook.Url != "https://hooks.example.org/ffuf" || hook.Command != "" || strings.Join(hook.Events, ",") != "match,error" {
+		t.Errorf("unexpected webhook %+v, %v", hook, err)
+	}
+	hook, err = ParseHook("all:notify-send ffuf \"$FFUF_EVENT\"")
+	if err != nil || hook.Command != "notify-send ffuf \"$FFUF_EVENT\"" || len(hook.Events) != len(hookEvents) {
+		t.Errorf("unexpected command hook %+v, %v", hook, err)
+	}
+	if !hook.Runs(HookJobFinished) {
+		t.Errorf("an all hook does not run on %s", HookJobFinished)
+	}
+	for _, spec := range []string{"match", "match:", "matched:true", ":true"} {
+		if _, err := ParseHook(spec); err == nil {
+			t.Errorf("ParseHook(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestNormalizeDNSResolver(t *testing.T) {
+	tests := map[string]string{
+		"1.1.1.1":       "1.1.1.1:53",
+		"1.1.1.1:5353":  "1.1.1.1:5353",
+		"[::1]:53":      "[::1]:53",
+		"::1":           "[::1]:53",
+		"dns.local":     "dns.local:53",
+		"dns.local:853": "dns.local:853",
+	}
+	for in, want := range tests {
+		got, err := normalizeDNSResolver(in)
+		if err != nil {
+			t.Errorf("normalizeDNSResolver(%q): unexpected error: %s", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("normalizeDNSResolver(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if _, err := normalizeDNSResolver("1.1.1.1:dns"); err == nil {
+		t.Errorf("normalizeDNSResolver with a non-numeric port: expected an error")
+	}
+}
+
+func TestParseUnixURL(t *testing.T) {
+	tests := []struct {
+		in     string
+		socket string
+		url    string
+	}{
+		{in: "unix:///var/run/docker.sock:/containers/FUZZ/json", socket: "/var/run/docker.sock", url: "http://localhost/containers/FUZZ/json"},
+		{in: "unix:///tmp/app.sock:FUZZ", socket: "/tmp/app.sock", url: "http://localhost/FUZZ"},
+		{in: "unix:///tmp/app.sock", socket: "/tmp/app.sock", url: "http://localhost/"},
+	}
+	for _, tc := range tests {
+		socket, u := parseUnixURL(tc.in)
+		if socket != tc.socket || u != tc.url {
+			t.Errorf("parseUnixURL(%q) = %q, %q, want %q, %q", tc.in, socket, u, tc.socket, tc.url)
+		}
+	}
+}
+
+func TestConfigFromOptions_UnixURL(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "unix:///var/run/docker.sock:/containers/FUZZ"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.UnixSocket != "/var/run/docker.sock" {
+		t.Errorf("UnixSocket = %q, want /var/run/docker.sock", conf.UnixSocket)
+	}
+	if conf.Url != "http://localhost/containers/FUZZ" {
+		t.Errorf("Url = %q, want http://localhost/containers/FUZZ", conf.Url)
+	}
+	if conf.Options.HTTP.UnixSocket != "/var/run/docker.sock" {
+		t.Errorf("retained options lost the socket: %q", conf.Options.HTTP.UnixSocket)
+	}
+
+	opts.HTTP.ProxyURL = "http://127.0.0.1:8080"
+	if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+		t.Errorf("expected an error when combining a Unix socket with a proxy")
+	}
+
+	// The replayed requests would go through the proxy to the localhost of
+	// the URL instead of the socket
+	opts.HTTP.ProxyURL = ""
+	opts.HTTP.ReplayProxyURL = "http://127.0.0.1:8080"
+	if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+		t.Errorf("expected an error when combining a Unix socket with a replay proxy")
+	}
+}
+
+func TestConfigFromOptions_WebSocket(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "wss://example.org/socket"
+	opts.HTTP.Data = `{"q":"FUZZ"}`
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if !conf.WebSocket {
+		t.Errorf("expected a wss:// URL to select the WebSocket runner")
+	}
+	if conf.Method != "GET" {
+		t.Errorf("Method = %q, the handshake must stay a GET with -d", conf.Method)
+	}
+	if conf.Options.Matcher.Status != NewConfigOptions().Matcher.Status+",101" {
+		t.Errorf("default status matcher %q does not match an accepted handshake", conf.Options.Matcher.Status)
+	}
+	if opts.Matcher.Status != NewConfigOptions().Matcher.Status {
+		t.Errorf("the status matcher of the options was changed to %q", opts.Matcher.Status)
+	}
+	// Options reused for another scan, as pkg/scan and -batch do, are adjusted once
+	conf, err = ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.Options.Matcher.Status != NewConfigOptions().Matcher.Status+",101" {
+		t.Errorf("reusing the options changed the status matcher to %q", conf.Options.Matcher.Status)
+	}
+
+	opts = NewConfigOptions()
+	opts.HTTP.URL = "wss://example.org/socket?q=FUZZ"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.Matcher.Status = "200"
+	opts.HTTP.WSTimeout = 0
+	if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+		t.Errorf("expected an error for -ws-timeout 0")
+	}
+	if opts.Matcher.Status != "200" {
+		t.Errorf("an explicit status matcher was changed to %q", opts.Matcher.Status)
+	}
+}
+
+func TestConfigFromOptions_GraphQL(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/graphql"
+	opts.HTTP.GraphQL = `{ FUZZ }`
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.Input.GraphQLHarvest = "FUZZ"
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.Method != "POST" {
+		t.Errorf("Method = %q, want POST for a GraphQL query", conf.Method)
+	}
+	if len(conf.InputProviders) != 1 || conf.InputProviders[0].Name != "harvest" {
+		t.Errorf("expected the FUZZ wordlist to become a harvest input, got %+v", conf.InputProviders)
+	}
+
+	for name, configure := range map[string]func(*ConfigOptions){
+		"batch without query": func(o *ConfigOptions) { o.HTTP.GraphQL = ""; o.HTTP.URL += "?q=FUZZ"; o.HTTP.GraphQLBatch = 2 },
+		"query with -d":       func(o *ConfigOptions) { o.HTTP.Data = "x" },
+		"zero batch":          func(o *ConfigOptions) { o.HTTP.GraphQLBatch = 0 },
+		"keyword outside":     func(o *ConfigOptions) { o.HTTP.GraphQLBatch = 2; o.HTTP.URL += "?q=FUZZ" },
+		"harvest unknown":     func(o *ConfigOptions) { o.Input.GraphQLHarvest = "NOPE" },
+		"harvest with others": func(o *ConfigOptions) {
+			o.Input.GraphQLHarvest = "FUZZ"
+			o.Input.Wordlists = append(o.Input.Wordlists, "/tmp/wl.txt:ID")
+			o.HTTP.GraphQL = `{ user(id: "ID") { FUZZ } }`
+		},
+	} {
+		opts := NewConfigOptions()
+		opts.HTTP.URL = "https://example.org/graphql"
+		opts.HTTP.GraphQL = `{ user(name: "FUZZ") { id } }`
+		opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+		configure(opts)
+		if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestConfigFromOptions_AutoMode(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/api?id=1"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.Input.InputMode = "auto"
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if len(conf.InputProviders) != 1 || conf.InputProviders[0].Keyword != "FUZZ" {
+		t.Errorf("expected the wordlist to be kept for the injection points, got %+v", conf.InputProviders)
+	}
+
+	for name, configure := range map[string]func(*ConfigOptions){
+		"no injection points": func(o *ConfigOptions) { o.HTTP.URL = "https://example.org/api" },
+		"FUZZ keyword":        func(o *ConfigOptions) { o.HTTP.URL += "&x=FUZZ" },
+		"keyword wordlist":    func(o *ConfigOptions) { o.Input.Wordlists = []string{"/tmp/wl.txt:W"} },
+	} {
+		opts := NewConfigOptions()
+		opts.HTTP.URL = "https://example.org/api?id=1"
+		opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+		opts.Input.InputMode = "auto"
+		configure(opts)
+		if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestConfigFromOptions_ParamMine(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/api"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.Input.ParamMine = "query"
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if !conf.AutoCalibration {
+		t.Errorf("expected -param-mine to enable autocalibration for the baseline")
+	}
+	if conf.Options.Matcher.Status != "all" {
+		t.Errorf("default status matcher %q would hide parameters that change the status", conf.Options.Matcher.Status)
+	}
+	if opts.Matcher.Status != NewConfigOptions().Matcher.Status {
+		t.Errorf("the status matcher of the options was changed to %q", opts.Matcher.Status)
+	}
+	if len(conf.InputProviders) != 1 {
+		t.Errorf("expected the candidate wordlist to be kept, got %+v", conf.InputProviders)
+	}
+
+	for name, configure := range map[string]func(*ConfigOptions){
+		"unknown location": func(o *ConfigOptions) { o.Input.ParamMine = "cookie" },
+		"zero batch":       func(o *ConfigOptions) { o.Input.ParamBatch = 0 },
+		"FUZZ keyword":     func(o *ConfigOptions) { o.HTTP.URL += "/FUZZ" },
+		"keyword wordlist": func(o *ConfigOptions) { o.Input.Wordlists = []string{"/tmp/wl.txt:NAME"} },
+		"json array body":  func(o *ConfigOptions) { o.Input.ParamMine = "json"; o.HTTP.Data = "[1]" },
+	} {
+		opts := NewConfigOptions()
+		opts.HTTP.URL = "https://example.org/api"
+		opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+		opts.Input.ParamMine = "query"
+		configure(opts)
+		if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestConfigFromOptions_Multipart(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/upload"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.HTTP.Multipart = []string{"token=abc", "file=@/tmp/shell.php;filename=shell.FUZZ;type=image/png"}
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.Method != "POST" {
+		t.Errorf("expected -F to imply POST, got %s", conf.Method)
+	}
+	if len(conf.Multipart) != 2 || conf.Multipart[1].Filename != "shell.FUZZ" {
+		t.Errorf("unexpected parts %+v", conf.Multipart)
+	}
+	if len(conf.InputProviders) != 1 {
+		t.Errorf("expected FUZZ in a multipart part to keep the wordlist, got %+v", conf.InputProviders)
+	}
+
+	opts.HTTP.Data = "a=b"
+	if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+		t.Errorf("expected an error for -F together with -d")
+	}
+
+	opts.HTTP.Data = ""
+	opts.HTTP.URL = "wss://example.org/socket"
+	if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+		t.Errorf("expected an error for -F with a WebSocket URL")
+	}
+}
+
+func TestConfigFromOptions_ImportedRequest(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "requests.sh")
+	curl := "curl 'https://example.org/' -H 'Accept: */*'\ncurl 'https://example.org/api?id=1' -b 'session=abc' --data-raw 'user=bob'\n"
+	if err := os.WriteFile(file, []byte(curl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := NewConfigOptions()
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.Input.Request = file
+	opts.Input.RequestEntry = "/api"
+	opts.Input.RequestFuzz = []string{"id", "session"}
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.Method != "POST" || conf.Url != "https://example.org/api?id=FUZZ" || conf.Data != "user=bob" {
+		t.Errorf("unexpected request %s %s %q", conf.Method, conf.Url, conf.Data)
+	}
+	if conf.Headers["Cookie"] != "session=FUZZ" {
+		t.Errorf("unexpected headers %v", conf.Headers)
+	}
+	if len(conf.InputProviders) != 1 {
+		t.Errorf("expected the FUZZ wordlist to be kept, got %+v", conf.InputProviders)
+	}
+
+	opts.Input.RequestFuzz = []string{"missing"}
+	if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil {
+		t.Errorf("expected an error for a parameter that is not in the request")
+	}
+}
+
+func TestConfigFromOptions_Plugins(t *testing.T) {
+	data := `
+[general]
+[[general.plugins]]
+name = "words"
+command = "/usr/local/bin/words"
+args = ["--top", "100"]
+roles = ["input", "mutator"]
+`
+	opts := NewConfigOptions()
+	if err := toml.Unmarshal([]byte(data), opts); err != nil {
+		t.Fatalf("toml unmarshal: %s", err)
+	}
+	opts.HTTP.URL = "https://example.org/FUZZ"
+
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if len(conf.Plugins) != 1 || conf.Plugins[0].Keyword != "FUZZ" || conf.Plugins[0].Instances != 4 || len(conf.Plugins[0].Args) != 2 {
+		t.Errorf("unexpected plugins %+v", conf.Plugins)
+	}
+	if opts.General.Plugins[0].Keyword != "" {
+		t.Errorf("the defaults of the plugins were filled in the options")
+	}
+	if len(conf.InputProviders) != 1 || conf.InputProviders[0].Name != "plugin" || conf.InputProviders[0].Value != "words" {
+		t.Errorf("expected an input of the plugin, got %+v", conf.InputProviders)
+	}
+
+	opts.General.Plugins = append(opts.General.Plugins, PluginConfig{Name: "words", Roles: []string{"reporter"}})
+	_, err = ConfigFromOptions(opts, context.Background(), func() {})
+	for _, want := range []string{"declared more than once", "needs a command", `unknown role "reporter"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestConfigFromOptions_RecursionOrder(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/FUZZ"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.RecursionOrder != "breadth" {
+		t.Errorf("the default recursion order is %q, want breadth", conf.RecursionOrder)
+	}
+
+	opts.HTTP.RecursionOrder = "random"
+	if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err == nil || !strings.Contains(err.Error(), "-recursion-order") {
+		t.Errorf("expected an error for an unknown recursion order, got %v", err)
+	}
+}
+
+func TestConfigFromOptions_RecursionContent(t *testing.T) {
+	wordlist := filepath.Join(t.TempDir(), "small.txt")
+	if err := os.WriteFile(wordlist, []byte("a\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/FUZZ"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.HTTP.Recursion = true
+	opts.HTTP.RecursionStrategy = "content"
+	opts.HTTP.RecursionMatch = []string{"regexp:<a href"}
+	opts.Input.RecursionWordlists = []string{"2:" + wordlist}
+	opts.Input.RecursionExtensions = []string{"1:.php,.bak", "3:"}
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.RecursionStatus != "200,401,403" || len(conf.RecursionMatch) != 1 {
+		t.Errorf("unexpected content recursion config %q %v", conf.RecursionStatus, conf.RecursionMatch)
+	}
+	if conf.RecursionWordlists[2] != wordlist || len(conf.RecursionWordlists) != 1 {
+		t.Errorf("unexpected recursion wordlists %v", conf.RecursionWordlists)
+	}
+	if strings.Join(conf.RecursionExtensions[1], " ") != ".php .bak" || conf.RecursionExtensions[3] == nil || len(conf.RecursionExtensions[3]) != 0 {
+		t.Errorf("unexpected recursion extensions %v", conf.RecursionExtensions)
+	}
+
+	for _, set := range []func(o *ConfigOptions){
+		func(o *ConfigOptions) { o.HTTP.RecursionStrategy = "smart" },
+		func(o *ConfigOptions) { o.HTTP.RecursionMatch = []string{"regexp"} },
+		func(o *ConfigOptions) { o.Input.RecursionWordlists = []string{"0:" + wordlist} },
+		func(o *ConfigOptions) { o.Input.RecursionWordlists = []string{"1:/does/not/exist"} },
+		func(o *ConfigOptions) { o.Input.RecursionExtensions = []string{".php"} },
+		func(o *ConfigOptions) { o.HTTP.Recursion = false },
+	} {
+		bad := NewConfigOptions()
+		bad.HTTP.URL = opts.HTTP.URL
+		bad.Input.Wordlists = opts.Input.Wordlists
+		bad.HTTP.Recursion = true
+		bad.Input.RecursionWordlists = opts.Input.RecursionWordlists
+		set(bad)
+		if _, err := ConfigFromOptions(bad, context.Background(), func() {}); err == nil {
+			t.Errorf("expected an error for %+v %+v", bad.HTTP, bad.Input)
+		}
+	}
+}
+
+func TestConfigFromOptions_RecursionTemplate(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/api/FUZZ/users"
+	opts.HTTP.Headers = []string{"Host: VHOST.example.org"}
+	opts.Input.Wordlists = []string{"/tmp/wl.txt:FUZZ", "/tmp/wl.txt:VHOST"}
+	opts.HTTP.Recursion = true
+	opts.HTTP.RecursionStrategy = "greedy"
+	opts.HTTP.RecursionTemplate = []string{"FUZZ:path", "VHOST:host:-"}
+	conf, err := ConfigFromOptions(opts, context.Background(), func() {})
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	want := []RecursionTemplate{{"FUZZ", RecursionPath, "/"}, {"VHOST", RecursionHost, "-"}}
+	if !reflect.DeepEqual(conf.RecursionTemplates, want) {
+		t.Errorf("got recursion templates %+v, want %+v", conf.RecursionTemplates, want)
+	}
+
+	for _, set := range []func(o *ConfigOptions){
+		func(o *ConfigOptions) { o.HTTP.RecursionTemplate = []string{"FUZZ"} },
+		func(o *ConfigOptions) { o.HTTP.RecursionTemplate = []string{"FUZZ:cookie"} },
+		func(o *ConfigOptions) { o.HTTP.RecursionTemplate = []string{"FUZZ:param"} },
+		func(o *ConfigOptions) { o.HTTP.RecursionTemplate = []string{"VHOST:path"} },
+		func(o *ConfigOptions) { o.HTTP.RecursionTemplate = nil },
+		func(o *ConfigOptions) { o.HTTP.Recursion = false },
+		func(o *ConfigOptions) { o.HTTP.RecursionStrategy = "default" },
+		func(o *ConfigOptions) {
+			o.HTTP.RecursionStrategy = "default"
+			o.HTTP.RecursionTemplate = []string{"VHOST:host"}
+		},
+	} {
+		bad := NewConfigOptions()
+		bad.HTTP.URL = opts.HTTP.URL
+		bad.HTTP.Headers = opts.HTTP.Headers
+		bad.Input.Wordlists = opts.Input.Wordlists
+		bad.HTTP.Recursion = true
+		bad.HTTP.RecursionStrategy = opts.HTTP.RecursionStrategy
+		bad.HTTP.RecursionTemplate = opts.HTTP.RecursionTemplate
+		set(bad)
+		if _, err := ConfigFromOptions(bad, context.Background(), func() {}); err == nil {
+			t.Errorf("expected an error for %+v", bad.HTTP)
+		}
+	}
+}
+
+func TestConfigFromOptions_RecursionTemplateDefaultStrategy(t *testing.T) {
+	opts := NewConfigOptions()
+	opts.HTTP.URL = "https://example.org/api/FUZZ"
+	opts.Input.Wordlists = []string{"/tmp/wl.txt"}
+	opts.HTTP.Recursion = true
+	opts.HTTP.RecursionTemplate = []string{"FUZZ:path"}
+	if _, err := ConfigFromOptions(opts, context.Background(), func() {}); err != nil {
+		t.Errorf("a path keyword at the end of the URL was rejected for the default strategy: %v", err)
+	}
+}
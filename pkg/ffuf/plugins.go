@@ -0,0 +1,90 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The roles a plugin can have
+const (
+	PluginFilter  = "filter"
+	PluginMatcher = "matcher"
+	PluginMutator = "mutator"
+	PluginInput   = "input"
+)
+
+var pluginRoles = []string{PluginFilter, PluginMatcher, PluginMutator, PluginInput}
+
+// PluginConfig is an out-of-process plugin declared in the config file: an
+// executable speaking the line-delimited JSON plugin protocol (see pkg/plugin)
+// on its stdin and stdout. Instances of it are started for the scan and used
+// concurrently, the input role only uses one of them. An instance that does not
+// reply within Timeout seconds, 10 if it is 0, is killed and replaced.
+type PluginConfig struct {
+	Name      string   `json:"name" toml:"name"`
+	Command   string   `json:"command" toml:"command"`
+	Args      []string `json:"args" toml:"args"`
+	Roles     []string `json:"roles" toml:"roles"`
+	Keyword   string   `json:"keyword" toml:"keyword"`
+	Instances int      `json:"instances" toml:"instances"`
+	Timeout   int      `json:"timeout" toml:"timeout"`
+}
+
+// HasRole reports whether the plugin has a role
+func (p PluginConfig) HasRole(role string) bool {
+	return StrInSlice(role, p.Roles)
+}
+
+// validatePlugins checks the plugins of the config file and fills in the
+// default keyword and number of instances
+func validatePlugins(plugins []PluginConfig) []error {
+	errs := make([]error, 0)
+	names := make(map[string]bool)
+	for i := range plugins {
+		p := &plugins[i]
+		if p.Name == "" || strings.ContainsAny(p.Name, " ,:") {
+			errs = append(errs, fmt.Errorf("plugin %q needs a name without spaces, commas or colons", p.Name))
+		} else if names[p.Name] {
+			errs = append(errs, fmt.Errorf("plugin %s is declared more than once", p.Name))
+		}
+		names[p.Name] = true
+		if p.Command == "" {
+			errs = append(errs, fmt.Errorf("plugin %s needs a command", p.Name))
+		}
+		if len(p.Roles) == 0 {
+			errs = append(errs, fmt.Errorf("plugin %s needs a role: %s", p.Name, strings.Join(pluginRoles, ", ")))
+		}
+		for _, role := range p.Roles {
+			if !StrInSlice(role, pluginRoles) {
+				errs = append(errs, fmt.Errorf("unknown role %q of plugin %s, expected any of: %s", role, p.Name, strings.Join(pluginRoles, ", ")))
+			}
+		}
+		if p.HasRole(PluginInput) && p.Keyword == "" {
+			p.Keyword = "FUZZ"
+		}
+		if p.Instances == 0 {
+			p.Instances = 4
+		} else if p.Instances < 0 {
+			errs = append(errs, fmt.Errorf("plugin %s needs at least one instance", p.Name))
+		}
+		if p.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("plugin %s needs a positive timeout", p.Name))
+		}
+	}
+	return errs
+}
+
+// clonePlugins deep-copies the plugins, so that filling in their defaults does
+// not change the options they were parsed from
+func clonePlugins(in []PluginConfig) []PluginConfig {
+	if in == nil {
+		return nil
+	}
+	out := make([]PluginConfig, len(in))
+	for i, p := range in {
+		out[i] = p
+		out[i].Args = cloneStrings(p.Args)
+		out[i].Roles = cloneStrings(p.Roles)
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The locations of the request a -recursion-template puts the value in
+const (
+	RecursionPath  = "path"
+	RecursionHost  = "host"
+	RecursionParam = "param"
+)
+
+var recursionLocations = []string{RecursionPath, RecursionHost, RecursionParam}
+
+// RecursionTemplate is a -recursion-template: where a recursion job puts the
+// value of Keyword it was found with. In the URL path and in the parameters
+// the value and the separator go before the keyword, so that /FUZZ becomes
+// /admin/FUZZ, and in the host name the separator and the value go after it,
+// so that FUZZ.example.org becomes FUZZ.dev.example.org.
+type RecursionTemplate struct {
+	Keyword   string `json:"keyword"`
+	Location  string `json:"location"`
+	Separator string `json:"separator"`
+}
+
+// ParseRecursionTemplate parses a -recursion-template of the form
+// KEYWORD:LOCATION[:SEPARATOR]. The separator is / by default, and . for the
+// host name.
+func ParseRecursionTemplate(spec string) (RecursionTemplate, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) < 2 || parts[0] == "" {
+		return RecursionTemplate{}, fmt.Errorf("-recursion-template %q is not of the form KEYWORD:LOCATION[:SEPARATOR]", spec)
+	}
+	t := RecursionTemplate{Keyword: parts[0], Location: parts[1], Separator: "/"}
+	if !StrInSlice(t.Location, recursionLocations) {
+		return t, fmt.Errorf("unknown -recursion-template location %q, expected any of: %s", t.Location, strings.Join(recursionLocations, ", "))
+	}
+	if t.Location == RecursionHost {
+		t.Separator = "."
+	}
+	if len(parts) == 3 {
+		if parts[2] == "" {
+			return t, fmt.Errorf("-recursion-template %q has an empty separator", spec)
+		}
+		t.Separator = parts[2]
+	}
+	return t, nil
+}
+
+// InRequest reports whether the keyword of the template is in its location of
+// req
+func (t RecursionTemplate) InRequest(req *Request) bool {
+	found := false
+	r := CopyRequest(req)
+	t.rewrite(&r, func(s string) string {
+		found = found || strings.Contains(s, t.Keyword)
+		return s
+	})
+	return found
+}
+
+// Apply puts value in place of the keyword of the template in its location of
+// req, with the keyword after or before it to be fuzzed again
+func (t RecursionTemplate) Apply(req *Request, value string) {
+	replacement := value + t.Separator + t.Keyword
+	if t.Location == RecursionHost {
+		replacement = t.Keyword + t.Separator + value
+	}
+	t.rewrite(req, func(s string) string {
+		return strings.ReplaceAll(s, t.Keyword, replacement)
+	})
+}
+
+// rewrite runs f on the parts of req in the location of the template, and puts
+// back what it returns
+func (t RecursionTemplate) rewrite(req *Request, f func(string) string) {
+	host, path, query := splitTemplateUrl(req.Url)
+	switch t.Location {
+	case RecursionPath:
+		path = f(path)
+	case RecursionHost:
+		host = f(host)
+		headers := make(map[string]string, len(req.Headers))
+		for name, value := range req.Headers {
+			if strings.EqualFold(name, "Host") {
+				value = f(value)
+			}
+			headers[name] = value
+		}
+		req.Headers = headers
+	case RecursionParam:
+		query = f(query)
+		req.Data = []byte(f(string(req.Data)))
+	}
+	req.Url = host + path + query
+}
+
+// splitTemplateUrl splits a URL with keywords in it, which may not parse as a
+// URL yet, into the scheme and host, the path and the query string with the
+// fragment
+func splitTemplateUrl(rawurl string) (string, string, string) {
+	start := 0
+	if i := strings.Index(rawurl, "://"); i != -1 {
+		start = i + len("://")
+	}
+	hostEnd := len(rawurl)
+	if i := strings.IndexAny(rawurl[start:], "/?#"); i != -1 {
+		hostEnd = start + i
+	}
+	pathEnd := len(rawurl)
+	if i := strings.IndexAny(rawurl[hostEnd:], "?#"); i != -1 {
+		pathEnd = hostEnd + i
+	}
+	return rawurl[:hostEnd], rawurl[hostEnd:pathEnd], rawurl[pathEnd:]
+}
@@ -0,0 +1,91 @@
+package ffuf
+
+import (
+	"testing"
+)
+
+func TestParseRecursionTemplate(t *testing.T) {
+	tests := map[string]RecursionTemplate{
+		"FUZZ:path":     {"FUZZ", RecursionPath, "/"},
+		"VHOST:host":    {"VHOST", RecursionHost, "."},
+		"ID:param:,":    {"ID", RecursionParam, ","},
+		"FUZZ:path:a:b": {"FUZZ", RecursionPath, "a:b"},
+	}
+	for spec, want := range tests {
+		got, err := ParseRecursionTemplate(spec)
+		if err != nil || got != want {
+			t.Errorf("ParseRecursionTemplate(%q) = %+v, %v, want %+v", spec, got, err, want)
+		}
+	}
+	for _, spec := range []string{"FUZZ", ":path", "FUZZ:query", "FUZZ:path:"} {
+		if _, err := ParseRecursionTemplate(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestRecursionTemplateApply(t *testing.T) {
+	tests := []struct {
+		template RecursionTemplate
+		req      Request
+		want     Request
+	}{
+		{
+			RecursionTemplate{"FUZZ", RecursionPath, "/"},
+			Request{Url: "http://example.org/api/FUZZ/users?q=FUZZ"},
+			Request{Url: "http://example.org/api/v1/FUZZ/users?q=FUZZ"},
+		},
+		{
+			RecursionTemplate{"FUZZ", RecursionHost, "."},
+			Request{Url: "https://FUZZ.example.org/FUZZ", Headers: map[string]string{"host": "FUZZ.example.org", "X": "FUZZ"}},
+			Request{Url: "https://FUZZ.v1.example.org/FUZZ", Headers: map[string]string{"host": "FUZZ.v1.example.org", "X": "FUZZ"}},
+		},
+		{
+			RecursionTemplate{"FUZZ", RecursionParam, "/"},
+			Request{Url: "http://example.org/FUZZ?dir=FUZZ#FUZZ", Data: []byte("dir=FUZZ")},
+			Request{Url: "http://example.org/FUZZ?dir=v1/FUZZ#v1/FUZZ", Data: []byte("dir=v1/FUZZ")},
+		},
+	}
+	for _, tc := range tests {
+		req := CopyRequest(&tc.req)
+		if !tc.template.InRequest(&req) {
+			t.Errorf("%+v: the keyword was not found in %+v", tc.template, req)
+		}
+		tc.template.Apply(&req, "v1")
+		if req.Url != tc.want.Url || string(req.Data) != string(tc.want.Data) {
+			t.Errorf("%+v: got %s %q, want %s %q", tc.template, req.Url, req.Data, tc.want.Url, tc.want.Data)
+		}
+		for name, value := range tc.want.Headers {
+			if req.Headers[name] != value {
+				t.Errorf("%+v: got header %s: %s, want %s", tc.template, name, req.Headers[name], value)
+			}
+		}
+	}
+
+	if (RecursionTemplate{"FUZZ", RecursionPath, "/"}).InRequest(&Request{Url: "http://FUZZ/?a=FUZZ"}) {
+		t.Errorf("found the keyword of the path template outside of the path")
+	}
+}
+
+func TestRecursionRequest(t *testing.T) {
+	conf := &Config{Method: "GET", Url: "http://example.org/FUZZ", Headers: map[string]string{}}
+	matched := Request{Url: "http://example.org/admin", Input: map[string][]byte{"FUZZ": []byte("admin")}}
+	basereq := BaseRequest(conf)
+	if r := RecursionRequest(conf, &basereq, &matched); r.Url != "http://example.org/admin/FUZZ" {
+		t.Errorf("got %s without templates, want the URL with FUZZ appended", r.Url)
+	}
+	if name := RecursionName(conf, &matched); name != "admin" {
+		t.Errorf("got name %q, want admin", name)
+	}
+
+	conf.RecursionTemplates = []RecursionTemplate{{"FUZZ", RecursionParam, "/"}}
+	basereq = Request{Method: "POST", Url: "http://example.org/list", Data: []byte("dir=FUZZ")}
+	matched = Request{Url: "http://example.org/list", Data: []byte("dir=a.b"), Input: map[string][]byte{"FUZZ": []byte("a.b")}}
+	r := RecursionRequest(conf, &basereq, &matched)
+	if r.Method != "POST" || r.Url != "http://example.org/list" || string(r.Data) != "dir=a.b/FUZZ" || r.Input != nil {
+		t.Errorf("unexpected recursion request %+v", r)
+	}
+	if name := RecursionName(conf, &matched); name != "a.b" {
+		t.Errorf("got name %q, want the value of FUZZ", name)
+	}
+}
@@ -17,6 +17,9 @@ type Request struct {
 	Raw       string
 	Error     string
 	Timestamp time.Time
+	// Encoding is the type of the injection point FUZZ was placed in by -mode auto
+	// (json, xml, form or query), the input is encoded to fit it
+	Encoding string
 }
 
 func NewRequest(conf *Config) Request {
@@ -35,13 +38,38 @@ func BaseRequest(conf *Config) Request {
 	return req
 }
 
-// RecursionRequest returns a base request for a recursion target
-func RecursionRequest(conf *Config, path string) Request {
-	r := BaseRequest(conf)
-	r.Url = path
+// RecursionRequest returns the base request of a recursion job into req, a
+// request made from basereq. Without -recursion-template the job fuzzes FUZZ
+// at the end of the URL of req, and with them the value of each templated
+// keyword in req is put into basereq in front of the keyword.
+func RecursionRequest(conf *Config, basereq *Request, req *Request) Request {
+	if len(conf.RecursionTemplates) == 0 {
+		r := BaseRequest(conf)
+		r.Url = req.Url + "/FUZZ"
+		return r
+	}
+	r := CopyRequest(basereq)
+	r.Input = nil
+	for _, t := range conf.RecursionTemplates {
+		if value, ok := req.Input[t.Keyword]; ok {
+			t.Apply(&r, string(value))
+		}
+	}
 	return r
 }
 
+// RecursionName returns the name of what req found for a recursion job: the
+// value of the first templated keyword of -recursion-template, or the last
+// segment of the URL
+func RecursionName(conf *Config, req *Request) string {
+	for _, t := range conf.RecursionTemplates {
+		if value, ok := req.Input[t.Keyword]; ok {
+			return string(value)
+		}
+	}
+	return req.Url[strings.LastIndex(req.Url, "/")+1:]
+}
+
 // CopyRequest performs a deep copy of a request and returns a new struct
 func CopyRequest(basereq *Request) Request {
 	var req Request
@@ -66,6 +94,7 @@ func CopyRequest(basereq *Request) Request {
 
 	req.Position = basereq.Position
 	req.Raw = basereq.Raw
+	req.Encoding = basereq.Encoding
 
 	return req
 }
@@ -22,6 +22,9 @@ type Response struct {
 	ScraperData   map[string][]string
 	Duration      time.Duration
 	Timestamp     time.Time
+	// RemoteAddr is the address the connection was actually made to, which may
+	// differ from the URL host when -resolve or -dns-resolver is in use.
+	RemoteAddr string
 }
 
 // GetRedirectLocation returns the redirect location for a 3xx redirect HTTP response
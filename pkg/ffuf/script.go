@@ -0,0 +1,13 @@
+package ffuf
+
+// ScriptVerdict is what the on_response hook of a -script decided on a response
+type ScriptVerdict int
+
+const (
+	// ScriptUndecided leaves the response to the matchers and filters
+	ScriptUndecided ScriptVerdict = iota
+	// ScriptMatch matches the response, whatever the matchers and filters say
+	ScriptMatch
+	// ScriptFilter filters the response out, whatever the matchers say
+	ScriptFilter
+)
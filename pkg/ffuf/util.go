@@ -48,6 +48,21 @@ func FileExists(path string) bool {
 	return !md.IsDir()
 }
 
+// BodyTemplateContainsKeyword checks if a keyword is present in the -graphql
+// query and variables or the -F multipart parts, which the request body is only
+// built from by the runner
+func BodyTemplateContainsKeyword(conf *Config, kw string) bool {
+	if strings.Contains(conf.GraphQLQuery, kw) || strings.Contains(conf.GraphQLVariables, kw) {
+		return true
+	}
+	for _, p := range conf.Multipart {
+		if p.ContainsKeyword(kw) {
+			return true
+		}
+	}
+	return false
+}
+
 // RequestContainsKeyword checks if a keyword is present in any field of a request
 func RequestContainsKeyword(req Request, kw string) bool {
 	if strings.Contains(req.Host, kw) {
@@ -97,6 +97,15 @@ func NewFilterByName(name string, value string) (ffuf.FilterProvider, error) {
 	if name == "time" {
 		return NewTimeFilter(value)
 	}
+	if name == "gqlerrors" {
+		return NewGraphQLErrorsFilter(value)
+	}
+	if name == "gqlmessage" {
+		return NewGraphQLMessageFilter(value)
+	}
+	if name == "gqldata" {
+		return NewGraphQLDataFilter(value)
+	}
 	return nil, fmt.Errorf("Could not create filter with name %s", name)
 }
 
@@ -174,6 +183,22 @@ func (f *MatcherManager) AddMatcher(name string, option string) error {
 	return err
 }
 
+// AddFilterProvider adds a filter created elsewhere, replacing the filter of
+// the same name
+func (f *MatcherManager) AddFilterProvider(name string, filter ffuf.FilterProvider) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Filters[name] = filter
+}
+
+// AddMatcherProvider adds a matcher created elsewhere, replacing the matcher
+// of the same name
+func (f *MatcherManager) AddMatcherProvider(name string, matcher ffuf.FilterProvider) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Matchers[name] = matcher
+}
+
 func (f *MatcherManager) GetFilters() map[string]ffuf.FilterProvider {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
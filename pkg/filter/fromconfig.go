@@ -49,6 +49,21 @@ func FromConfig(opts *ffuf.ConfigOptions, addDefaultStatusMatcher bool) (ffuf.Ma
 			errs.Add(err)
 		}
 	}
+	if opts.Filter.GraphQLErrors != "" {
+		if err := mm.AddFilter("gqlerrors", opts.Filter.GraphQLErrors, false); err != nil {
+			errs.Add(err)
+		}
+	}
+	if opts.Filter.GraphQLMessage != "" {
+		if err := mm.AddFilter("gqlmessage", opts.Filter.GraphQLMessage, false); err != nil {
+			errs.Add(err)
+		}
+	}
+	if opts.Filter.GraphQLData != "" {
+		if err := mm.AddFilter("gqldata", opts.Filter.GraphQLData, false); err != nil {
+			errs.Add(err)
+		}
+	}
 
 	if opts.Matcher.Size != "" {
 		if err := mm.AddMatcher("size", opts.Matcher.Size); err != nil {
@@ -75,6 +90,32 @@ func FromConfig(opts *ffuf.ConfigOptions, addDefaultStatusMatcher bool) (ffuf.Ma
 			errs.Add(err)
 		}
 	}
+	if opts.Matcher.GraphQLErrors != "" {
+		if err := mm.AddMatcher("gqlerrors", opts.Matcher.GraphQLErrors); err != nil {
+			errs.Add(err)
+		}
+	}
+	if opts.Matcher.GraphQLMessage != "" {
+		if err := mm.AddMatcher("gqlmessage", opts.Matcher.GraphQLMessage); err != nil {
+			errs.Add(err)
+		}
+	}
+	if opts.Matcher.GraphQLData != "" {
+		if err := mm.AddMatcher("gqldata", opts.Matcher.GraphQLData); err != nil {
+			errs.Add(err)
+		}
+	}
 
 	return mm, errs.ErrorOrNil()
 }
+
+// DefaultStatusMatcher reports whether the status codes are matched when there
+// are no command line flags to tell, like for the options of the ffufrc alone.
+// Like in the ffuf command, they are unless other matchers are set and the
+// status codes are left at their default.
+func DefaultStatusMatcher(opts *ffuf.ConfigOptions) bool {
+	m := opts.Matcher
+	matcherSet := m.Lines != "" || m.Regexp != "" || m.Size != "" || m.Time != "" || m.Words != "" ||
+		m.GraphQLErrors != "" || m.GraphQLMessage != "" || m.GraphQLData != ""
+	return !matcherSet || m.Status != ffuf.NewConfigOptions().Matcher.Status
+}
@@ -0,0 +1,142 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// GraphQLErrorsFilter matches GraphQL responses by whether they carry errors.
+// A GraphQL server answers most errors with a 200, so this replaces the status
+// code as the primary signal.
+type GraphQLErrorsFilter struct {
+	Value    bool
+	valueRaw string
+}
+
+func NewGraphQLErrorsFilter(value string) (ffuf.FilterProvider, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return &GraphQLErrorsFilter{}, fmt.Errorf("GraphQL errors filter or matcher (-fge / -mge): invalid value: %s, expected true or false", value)
+	}
+	return &GraphQLErrorsFilter{Value: b, valueRaw: value}, nil
+}
+
+func (f *GraphQLErrorsFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.valueRaw,
+	})
+}
+
+func (f *GraphQLErrorsFilter) Filter(response *ffuf.Response) (bool, error) {
+	gql, ok := ffuf.ParseGraphQLResponse(response.Data)
+	if !ok {
+		return false, nil
+	}
+	return (len(gql.Errors) > 0) == f.Value, nil
+}
+
+func (f *GraphQLErrorsFilter) Repr() string {
+	return f.valueRaw
+}
+
+func (f *GraphQLErrorsFilter) ReprVerbose() string {
+	return fmt.Sprintf("GraphQL errors: %s", f.valueRaw)
+}
+
+// GraphQLMessageFilter matches a regexp against the GraphQL error messages.
+type GraphQLMessageFilter struct {
+	Value    *regexp.Regexp
+	valueRaw string
+}
+
+func NewGraphQLMessageFilter(value string) (ffuf.FilterProvider, error) {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return &GraphQLMessageFilter{}, fmt.Errorf("GraphQL error message filter or matcher (-fgm / -mgm): invalid value: %s", value)
+	}
+	return &GraphQLMessageFilter{Value: re, valueRaw: value}, nil
+}
+
+func (f *GraphQLMessageFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.valueRaw,
+	})
+}
+
+func (f *GraphQLMessageFilter) Filter(response *ffuf.Response) (bool, error) {
+	gql, ok := ffuf.ParseGraphQLResponse(response.Data)
+	if !ok {
+		return false, nil
+	}
+	for _, e := range gql.Errors {
+		if f.Value.MatchString(e.Message) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *GraphQLMessageFilter) Repr() string {
+	return f.valueRaw
+}
+
+func (f *GraphQLMessageFilter) ReprVerbose() string {
+	return fmt.Sprintf("GraphQL error message: %s", f.valueRaw)
+}
+
+// GraphQLDataFilter matches GraphQL responses that have a non-null value at any
+// of the configured data paths.
+type GraphQLDataFilter struct {
+	Value    []string
+	valueRaw string
+}
+
+func NewGraphQLDataFilter(value string) (ffuf.FilterProvider, error) {
+	var paths []string
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, ".") || strings.HasSuffix(p, ".") {
+			return &GraphQLDataFilter{}, fmt.Errorf("GraphQL data filter or matcher (-fgd / -mgd): invalid value: %s", value)
+		}
+		paths = append(paths, p)
+	}
+	return &GraphQLDataFilter{Value: paths, valueRaw: value}, nil
+}
+
+func (f *GraphQLDataFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.valueRaw,
+	})
+}
+
+func (f *GraphQLDataFilter) Filter(response *ffuf.Response) (bool, error) {
+	gql, ok := ffuf.ParseGraphQLResponse(response.Data)
+	if !ok {
+		return false, nil
+	}
+	for _, p := range f.Value {
+		if gql.HasPath(p) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *GraphQLDataFilter) Repr() string {
+	return f.valueRaw
+}
+
+func (f *GraphQLDataFilter) ReprVerbose() string {
+	return fmt.Sprintf("GraphQL data path: %s", f.valueRaw)
+}
@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func TestNewGraphQLFiltersError(t *testing.T) {
+	if _, err := NewGraphQLErrorsFilter("maybe"); err == nil {
+		t.Errorf("Was expecting an error from errenous input data")
+	}
+	if _, err := NewGraphQLMessageFilter("r(("); err == nil {
+		t.Errorf("Was expecting an error from errenous input data")
+	}
+	for _, value := range []string{"", "user.", ".user", "user,,id"} {
+		if _, err := NewGraphQLDataFilter(value); err == nil {
+			t.Errorf("Was expecting an error from errenous input data %q", value)
+		}
+	}
+}
+
+func TestGraphQLFiltering(t *testing.T) {
+	errorsTrue, _ := NewGraphQLErrorsFilter("true")
+	errorsFalse, _ := NewGraphQLErrorsFilter("false")
+	message, _ := NewGraphQLMessageFilter("(?i)not authorized")
+	data, _ := NewGraphQLDataFilter("user.email, users.0.id")
+
+	for i, test := range []struct {
+		filter ffuf.FilterProvider
+		body   string
+		output bool
+	}{
+		{errorsTrue, `{"data":null,"errors":[{"message":"boom"}]}`, true},
+		{errorsTrue, `{"data":{"user":null}}`, false},
+		{errorsTrue, `not json`, false},
+		{errorsFalse, `{"data":{"user":null}}`, true},
+		{errorsFalse, `{"data":null,"errors":[{"message":"boom"}]}`, false},
+		{errorsFalse, `{"other":true}`, false},
+		{message, `{"errors":[{"message":"x"},{"message":"Not Authorized"}]}`, true},
+		{message, `{"errors":[{"message":"field not found"}]}`, false},
+		{data, `{"data":{"user":{"email":"a@b"}}}`, true},
+		{data, `{"data":{"user":{"email":null}}}`, false},
+		{data, `{"data":{"users":[{"id":1}]}}`, true},
+		{data, `{"data":{"users":[]}}`, false},
+	} {
+		resp := ffuf.Response{
+			Data:    []byte(test.body),
+			Request: &ffuf.Request{Input: map[string][]byte{}},
+		}
+		filterReturn, _ := test.filter.Filter(&resp)
+		if filterReturn != test.output {
+			t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package input
+
+import (
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// DepthInput is the FUZZ wordlist of a recursive scan with -recursion-wordlist
+// or -recursion-extensions. It reads a wordlist for every depth they change it
+// at, stdin (-) only once for all the depths it is used at, and uses the one of
+// the depth of the current queue job. The engine switches it between the queue
+// jobs while the progress and the -metrics read it, so the wordlist in use is
+// guarded by a mutex.
+type DepthInput struct {
+	mu      sync.Mutex
+	active  bool
+	keyword string
+	levels  []depthLevel
+	current *WordlistInput
+}
+
+// depthLevel is the wordlist of the recursion jobs from depth on
+type depthLevel struct {
+	depth    int
+	wordlist *WordlistInput
+}
+
+func NewDepthInput(keyword string, value string, conf *ffuf.Config) (*DepthInput, error) {
+	depths := []int{0}
+	for depth := range conf.RecursionWordlists {
+		depths = append(depths, depth)
+	}
+	for depth := range conf.RecursionExtensions {
+		if _, ok := conf.RecursionWordlists[depth]; !ok {
+			depths = append(depths, depth)
+		}
+	}
+	sort.Ints(depths)
+
+	d := &DepthInput{active: true, keyword: keyword}
+	path, extensions := value, conf.Extensions
+	var stdin []byte
+	stdinRead := false
+	for _, depth := range depths {
+		if p, ok := conf.RecursionWordlists[depth]; ok {
+			path = p
+		}
+		if e, ok := conf.RecursionExtensions[depth]; ok {
+			extensions = e
+		}
+		var wl *WordlistInput
+		var err error
+		if path == "-" {
+			if !stdinRead {
+				stdin, err = io.ReadAll(os.Stdin)
+				if err != nil {
+					return d, err
+				}
+				stdinRead = true
+			}
+			wl, err = newWordlistInputData(keyword, stdin, conf, extensions)
+		} else {
+			wl, err = newWordlistInput(keyword, path, conf, extensions)
+		}
+		if err != nil {
+			return d, err
+		}
+		d.levels = append(d.levels, depthLevel{depth: depth, wordlist: wl})
+	}
+	d.current = d.levels[0].wordlist
+	return d, nil
+}
+
+// SetDepth switches to the wordlist of depth, from its beginning
+func (d *DepthInput) SetDepth(depth int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, level := range d.levels {
+		if level.depth <= depth {
+			d.current = level.wordlist
+		}
+	}
+	d.current.ResetPosition()
+}
+
+// Position will return the current position in the input list
+func (d *DepthInput) Position() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.current.Position()
+}
+
+// SetPosition sets the current position of the inputprovider
+func (d *DepthInput) SetPosition(pos int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.current.SetPosition(pos)
+}
+
+// ResetPosition resets the position back to beginning of the wordlist.
+func (d *DepthInput) ResetPosition() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.current.ResetPosition()
+}
+
+// Keyword returns the keyword assigned to this InternalInputProvider
+func (d *DepthInput) Keyword() string {
+	return d.keyword
+}
+
+// Next will return a boolean telling if there's words left in the list
+func (d *DepthInput) Next() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.current.Next()
+}
+
+// IncrementPosition will increment the current position in the inputprovider data slice
+func (d *DepthInput) IncrementPosition() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.current.IncrementPosition()
+}
+
+// Value returns the value from wordlist at current cursor position
+func (d *DepthInput) Value() []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.current.Value()
+}
+
+// Total returns the size of the wordlist of the current depth
+func (d *DepthInput) Total() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.current.Total()
+}
+
+// Active returns boolean if the inputprovider is active
+func (d *DepthInput) Active() bool {
+	return d.active
+}
+
+// Enable sets the inputprovider as active
+func (d *DepthInput) Enable() {
+	d.active = true
+}
+
+// Disable disables the inputprovider
+func (d *DepthInput) Disable() {
+	d.active = false
+}
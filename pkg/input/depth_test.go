@@ -0,0 +1,80 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func writeWords(t *testing.T, words ...string) string {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(words, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// values returns the words of the provider from its current position on
+func values(p ffuf.InternalInputProvider) string {
+	words := make([]string, 0)
+	for p.Next() {
+		words = append(words, string(p.Value()))
+		p.IncrementPosition()
+	}
+	return strings.Join(words, ",")
+}
+
+func TestDepthInput(t *testing.T) {
+	conf := &ffuf.Config{
+		Extensions:          []string{".php"},
+		RecursionWordlists:  map[int]string{2: writeWords(t, "small")},
+		RecursionExtensions: map[int][]string{1: {".bak"}, 3: {}},
+	}
+	d, err := NewDepthInput("FUZZ", writeWords(t, "a", "b"), conf)
+	if err != nil {
+		t.Fatalf("NewDepthInput: %s", err)
+	}
+	for depth, want := range []string{
+		"a,a.php,b,b.php",
+		"a,a.bak,b,b.bak",
+		"small,small.bak",
+		"small",
+		"small",
+	} {
+		d.SetDepth(depth)
+		if got := values(d); got != want {
+			t.Errorf("depth %d has the words %s, want %s", depth, got, want)
+		}
+	}
+	d.SetDepth(0)
+	if d.Total() != 4 || d.Position() != 0 {
+		t.Errorf("the words of depth 0 did not start over: %d of %d", d.Position(), d.Total())
+	}
+}
+
+func TestDepthInput_StdinReadOnce(t *testing.T) {
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	var err error
+	if os.Stdin, err = os.Open(writeWords(t, "a", "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &ffuf.Config{
+		RecursionWordlists:  map[int]string{2: "-"},
+		RecursionExtensions: map[int][]string{1: {".bak"}},
+	}
+	d, err := NewDepthInput("FUZZ", "-", conf)
+	if err != nil {
+		t.Fatalf("NewDepthInput: %s", err)
+	}
+	for depth, want := range []string{"a,b", "a,a.bak,b,b.bak", "a,a.bak,b,b.bak"} {
+		d.SetDepth(depth)
+		if got := values(d); got != want {
+			t.Errorf("depth %d has the words %s of stdin, want %s", depth, got, want)
+		}
+	}
+}
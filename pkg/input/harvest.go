@@ -0,0 +1,135 @@
+package input
+
+import (
+	"sync"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// HarvestInput is a wordlist that grows while the job runs. It starts out with
+// the words of a regular wordlist, and names harvested from the responses (the
+// suggestions in GraphQL error messages, -graphql-harvest) are appended to it,
+// each only once, so they are fuzzed after the words already queued.
+//
+// Add is called from the request goroutines while the main loop iterates, so
+// every access goes through mu.
+type HarvestInput struct {
+	mu       sync.Mutex
+	active   bool
+	data     [][]byte
+	seen     map[string]bool
+	position int
+	keyword  string
+}
+
+func NewHarvestInput(keyword string, value string, conf *ffuf.Config) (*HarvestInput, error) {
+	wl, err := NewWordlistInput(keyword, value, conf)
+	if err != nil {
+		return nil, err
+	}
+	h := &HarvestInput{
+		active:  true,
+		keyword: keyword,
+		seen:    make(map[string]bool),
+	}
+	for _, word := range wl.data {
+		if !h.seen[string(word)] {
+			h.seen[string(word)] = true
+			h.data = append(h.data, word)
+		}
+	}
+	return h, nil
+}
+
+// Add appends the names that have not been seen yet
+func (h *HarvestInput) Add(names []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, name := range names {
+		if !h.seen[name] {
+			h.seen[name] = true
+			h.data = append(h.data, []byte(name))
+		}
+	}
+}
+
+// Keyword returns the keyword assigned to this InternalInputProvider
+func (h *HarvestInput) Keyword() string {
+	return h.keyword
+}
+
+// Position will return the current position in the input list
+func (h *HarvestInput) Position() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.position
+}
+
+// SetPosition sets the current position of the inputprovider
+func (h *HarvestInput) SetPosition(pos int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.position = pos
+}
+
+// ResetPosition resets the position back to the beginning of the list
+func (h *HarvestInput) ResetPosition() {
+	h.SetPosition(0)
+}
+
+// IncrementPosition increments the current position in the list
+func (h *HarvestInput) IncrementPosition() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.position++
+}
+
+// Next will return a boolean telling if there's words left in the list
+func (h *HarvestInput) Next() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.position < len(h.data)
+}
+
+// Value returns the value at the current cursor position
+func (h *HarvestInput) Value() []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.data[h.position]
+}
+
+// Total returns the number of words, harvested ones included
+func (h *HarvestInput) Total() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.data)
+}
+
+// Active returns boolean if the inputprovider is active
+func (h *HarvestInput) Active() bool {
+	return h.active
+}
+
+// Enable sets the inputprovider as active
+func (h *HarvestInput) Enable() {
+	h.active = true
+}
+
+// Disable disables the inputprovider
+func (h *HarvestInput) Disable() {
+	h.active = false
+}
+
+// Harvester returns the HarvestInput of ip for keyword, or nil if there is none.
+func Harvester(ip ffuf.InputProvider, keyword string) *HarvestInput {
+	mainip, ok := ip.(*MainInputProvider)
+	if !ok {
+		return nil
+	}
+	for _, p := range mainip.Providers {
+		if h, ok := p.(*HarvestInput); ok && h.Keyword() == keyword {
+			return h
+		}
+	}
+	return nil
+}
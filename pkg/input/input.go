@@ -19,7 +19,7 @@ type MainInputProvider struct {
 func NewInputProvider(conf *ffuf.Config) (ffuf.InputProvider, ffuf.Multierror) {
 	validmode := false
 	errs := ffuf.NewMultierror()
-	for _, mode := range []string{"clusterbomb", "pitchfork", "sniper"} {
+	for _, mode := range []string{"clusterbomb", "pitchfork", "sniper", "auto"} {
 		if conf.InputMode == mode {
 			validmode = true
 		}
@@ -43,6 +43,20 @@ func (i *MainInputProvider) AddProvider(provider ffuf.InputProviderConfig) error
 	if provider.Name == "command" {
 		newcomm, _ := NewCommandInput(provider.Keyword, provider.Value, i.Config)
 		i.Providers = append(i.Providers, newcomm)
+	} else if provider.Name == "plugin" {
+		i.Providers = append(i.Providers, NewPluginInput(provider.Keyword, provider.Value, i.Config))
+	} else if provider.Name == "harvest" {
+		newhv, err := NewHarvestInput(provider.Keyword, provider.Value, i.Config)
+		if err != nil {
+			return err
+		}
+		i.Providers = append(i.Providers, newhv)
+	} else if provider.Keyword == "FUZZ" && (len(i.Config.RecursionWordlists) > 0 || len(i.Config.RecursionExtensions) > 0) {
+		newdl, err := NewDepthInput(provider.Keyword, provider.Value, i.Config)
+		if err != nil {
+			return err
+		}
+		i.Providers = append(i.Providers, newdl)
 	} else {
 		// Default to wordlist
 		newwl, err := NewWordlistInput(provider.Keyword, provider.Value, i.Config)
@@ -73,6 +87,16 @@ func (i *MainInputProvider) ActivateKeywords(kws []string) {
 	}
 }
 
+// SetDepth switches the inputs that depend on the recursion depth to those of
+// depth
+func (i *MainInputProvider) SetDepth(depth int) {
+	for _, p := range i.Providers {
+		if dp, ok := p.(*DepthInput); ok {
+			dp.SetDepth(depth)
+		}
+	}
+}
+
 // Position will return the current position of progress
 func (i *MainInputProvider) Position() int {
 	return i.position
@@ -80,7 +104,7 @@ func (i *MainInputProvider) Position() int {
 
 // SetPosition will reset the MainInputProvider to a specific position
 func (i *MainInputProvider) SetPosition(pos int) {
-	if i.Config.InputMode == "clusterbomb" || i.Config.InputMode == "sniper" {
+	if i.Config.InputMode == "clusterbomb" || i.Config.InputMode == "sniper" || i.Config.InputMode == "auto" {
 		i.setclusterbombPosition(pos)
 	} else {
 		i.setpitchforkPosition(pos)
@@ -108,7 +132,7 @@ func (i *MainInputProvider) Next() bool {
 // Value returns a map of inputs for keywords
 func (i *MainInputProvider) Value() map[string][]byte {
 	retval := make(map[string][]byte)
-	if i.Config.InputMode == "clusterbomb" || i.Config.InputMode == "sniper" {
+	if i.Config.InputMode == "clusterbomb" || i.Config.InputMode == "sniper" || i.Config.InputMode == "auto" {
 		retval = i.clusterbombValue()
 	}
 	if i.Config.InputMode == "pitchfork" {
@@ -242,7 +266,7 @@ func (i *MainInputProvider) Total() int {
 			}
 		}
 	}
-	if i.Config.InputMode == "clusterbomb" || i.Config.InputMode == "sniper" {
+	if i.Config.InputMode == "clusterbomb" || i.Config.InputMode == "sniper" || i.Config.InputMode == "auto" {
 		count = 1
 		for _, p := range i.Providers {
 			if !p.Active() {
@@ -0,0 +1,44 @@
+package input
+
+import (
+	"fmt"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// PluginInput is the wordlist of an input plugin. It is empty until the plugin
+// is running and its values are set with SetPluginValues.
+type PluginInput struct {
+	WordlistInput
+	plugin string
+}
+
+func NewPluginInput(keyword string, plugin string, conf *ffuf.Config) *PluginInput {
+	return &PluginInput{
+		WordlistInput: WordlistInput{active: true, keyword: keyword, config: conf},
+		plugin:        plugin,
+	}
+}
+
+// SetPluginValues sets the values of the inputs of a plugin in ip. It is called
+// once the plugin is running, before the job starts.
+func SetPluginValues(ip ffuf.InputProvider, plugin string, values []string) error {
+	mainip, ok := ip.(*MainInputProvider)
+	if !ok {
+		return fmt.Errorf("no input of plugin %s", plugin)
+	}
+	found := false
+	for _, p := range mainip.Providers {
+		if pi, ok := p.(*PluginInput); ok && pi.plugin == plugin {
+			pi.data = make([][]byte, 0, len(values))
+			for _, v := range values {
+				pi.data = append(pi.data, []byte(v))
+			}
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no input of plugin %s", plugin)
+	}
+	return nil
+}
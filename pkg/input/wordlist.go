@@ -2,6 +2,8 @@ package input
 
 import (
 	"bufio"
+	"bytes"
+	"io"
 	"os"
 	"regexp"
 	"strings"
@@ -10,19 +12,27 @@ import (
 )
 
 type WordlistInput struct {
-	active   bool
-	config   *ffuf.Config
-	data     [][]byte
-	position int
-	keyword  string
+	active     bool
+	config     *ffuf.Config
+	data       [][]byte
+	position   int
+	keyword    string
+	extensions []string
 }
 
 func NewWordlistInput(keyword string, value string, conf *ffuf.Config) (*WordlistInput, error) {
+	return newWordlistInput(keyword, value, conf, conf.Extensions)
+}
+
+// newWordlistInput reads a wordlist like NewWordlistInput, with the extensions
+// given instead of those of conf
+func newWordlistInput(keyword string, value string, conf *ffuf.Config, extensions []string) (*WordlistInput, error) {
 	var wl WordlistInput
 	wl.active = true
 	wl.keyword = keyword
 	wl.config = conf
 	wl.position = 0
+	wl.extensions = extensions
 	var valid bool
 	var err error
 	// stdin?
@@ -42,6 +52,13 @@ func NewWordlistInput(keyword string, value string, conf *ffuf.Config) (*Wordlis
 	return &wl, err
 }
 
+// newWordlistInputData makes a wordlist like newWordlistInput of the contents
+// read already, like those of stdin that can be read only once
+func newWordlistInputData(keyword string, contents []byte, conf *ffuf.Config, extensions []string) (*WordlistInput, error) {
+	wl := &WordlistInput{active: true, keyword: keyword, config: conf, extensions: extensions}
+	return wl, wl.readWords(bytes.NewReader(contents))
+}
+
 // Position will return the current position in the input list
 func (w *WordlistInput) Position() int {
 	return w.position
@@ -124,16 +141,20 @@ func (w *WordlistInput) readFile(path string) error {
 		}
 	}
 	defer file.Close()
+	return w.readWords(file)
+}
 
+// readWords reads the words of the wordlist line by line from r
+func (w *WordlistInput) readWords(r io.Reader) error {
 	var data [][]byte
 	var ok bool
-	reader := bufio.NewScanner(file)
+	reader := bufio.NewScanner(r)
 	re := regexp.MustCompile(`(?i)%ext%`)
 	for reader.Scan() {
-		if w.config.DirSearchCompat && len(w.config.Extensions) > 0 {
+		if w.config.DirSearchCompat && len(w.extensions) > 0 {
 			text := []byte(reader.Text())
 			if re.Match(text) {
-				for _, ext := range w.config.Extensions {
+				for _, ext := range w.extensions {
 					contnt := re.ReplaceAll(text, []byte(ext))
 					data = append(data, []byte(contnt))
 				}
@@ -158,8 +179,8 @@ func (w *WordlistInput) readFile(path string) error {
 				}
 			}
 			data = append(data, []byte(text))
-			if w.keyword == "FUZZ" && len(w.config.Extensions) > 0 {
-				for _, ext := range w.config.Extensions {
+			if w.keyword == "FUZZ" && len(w.extensions) > 0 {
+				for _, ext := range w.extensions {
 					data = append(data, []byte(text+ext))
 				}
 			}
@@ -0,0 +1,441 @@
+package interactive
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/engine"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/output"
+)
+
+// APIServer serves the -api control API of a job. It offers the operations of
+// the interactive console as JSON endpoints, and streams the results and the
+// progress of the job as server-sent events:
+//
+//	GET    /status                 paused state, progress, rate, filters and queue
+//	POST   /pause, /resume         pause or resume the job
+//	POST   /restart                restart the current job
+//	GET    /results                results of the current job
+//	GET    /filters                active filters
+//	PUT    /filters/NAME           (re)configure a filter, {"value": "..."}
+//	POST   /filters/NAME           append to a filter, {"value": "..."}
+//	DELETE /filters/NAME           remove a filter
+//	GET    /queue                  job queue
+//	DELETE /queue/INDEX            delete a queued job
+//	PUT    /queue/INDEX            set the priority of a queued job, {"priority": 10}
+//	POST   /queue/skip             advance to the next queued job
+//	PUT    /rate                   adjust the rate, {"rate": 100}
+//	POST   /save                   save the results, {"filename": "...", "format": "json"}
+//	GET    /events                 result, progress and finished events
+//
+// Every request authenticates with the token as an "Authorization: Bearer"
+// header, or as a token query parameter for EventSource clients.
+type APIServer struct {
+	Token    string
+	job      *engine.Job
+	listener net.Listener
+	server   *http.Server
+}
+
+// ServeAPI starts serving the control API of a job at addr. The job output is
+// wrapped to publish the events, so ServeAPI is called before the job starts.
+// A random token is generated if token is empty.
+func ServeAPI(job *engine.Job, addr, token string) (*APIServer, error) {
+	if token == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		token = hex.EncodeToString(b)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not start the -api: %s", err)
+	}
+	events := &eventOutput{OutputProvider: job.Output, subscribers: make(map[chan apiEvent]bool)}
+	// The result events are those the output records, with their -od files
+	if observer, ok := job.Output.(ffuf.ResultObserver); ok {
+		observer.SetResultObserver(events.publishResult)
+		events.observed = true
+	}
+	job.Output = events
+	s := &APIServer{Token: token, job: job, listener: listener}
+	s.server = &http.Server{Handler: s.handler(events), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return s, nil
+}
+
+// Addr returns the address the API listens at
+func (s *APIServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the API. The event streams end with the finished event of the
+// job, Close waits for them to be written for a while.
+func (s *APIServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		return s.server.Close()
+	}
+	return nil
+}
+
+func (s *APIServer) handler(events *eventOutput) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.methods(map[string]http.HandlerFunc{"GET": func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.status(events))
+	}}))
+	mux.HandleFunc("/pause", s.methods(map[string]http.HandlerFunc{"POST": func(w http.ResponseWriter, r *http.Request) {
+		s.job.Output.SetPaused(true)
+		s.job.Pause()
+		writeJSON(w, http.StatusOK, s.status(events))
+	}}))
+	mux.HandleFunc("/resume", s.methods(map[string]http.HandlerFunc{"POST": func(w http.ResponseWriter, r *http.Request) {
+		s.job.Output.SetPaused(false)
+		s.job.Resume()
+		writeJSON(w, http.StatusOK, s.status(events))
+	}}))
+	mux.HandleFunc("/restart", s.methods(map[string]http.HandlerFunc{"POST": func(w http.ResponseWriter, r *http.Request) {
+		s.job.Reset(false)
+		s.job.Output.SetPaused(false)
+		s.job.Output.Info("Restarting the current ffuf job!")
+		s.job.Resume()
+		writeJSON(w, http.StatusOK, s.status(events))
+	}}))
+	mux.HandleFunc("/results", s.methods(map[string]http.HandlerFunc{"GET": func(w http.ResponseWriter, r *http.Request) {
+		results := make([]output.JsonResult, 0)
+		for _, res := range s.job.Output.GetCurrentResults() {
+			results = append(results, output.ToJsonResult(res))
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+	}}))
+	mux.HandleFunc("/filters", s.methods(map[string]http.HandlerFunc{"GET": func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"filters": s.filters()})
+	}}))
+	mux.HandleFunc("/filters/", s.handleFilter)
+	mux.HandleFunc("/queue", s.methods(map[string]http.HandlerFunc{"GET": func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"queue": s.queue()})
+	}}))
+	mux.HandleFunc("/queue/skip", s.methods(map[string]http.HandlerFunc{"POST": func(w http.ResponseWriter, r *http.Request) {
+		s.job.SkipQueue()
+		s.job.Output.Info("Skipping to the next queued job")
+		writeJSON(w, http.StatusOK, map[string]interface{}{"queue": s.queue()})
+	}}))
+	mux.HandleFunc("/queue/", s.methods(map[string]http.HandlerFunc{
+		"DELETE": func(w http.ResponseWriter, r *http.Request) {
+			index, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/queue/"))
+			if err != nil {
+				writeError(w, http.StatusNotFound, fmt.Errorf("Not a number: %s", strings.TrimPrefix(r.URL.Path, "/queue/")))
+				return
+			}
+			if err = deleteQueueJob(s.job, index); err != nil {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"queue": s.queue()})
+		},
+		"PUT": func(w http.ResponseWriter, r *http.Request) {
+			index, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/queue/"))
+			if err != nil {
+				writeError(w, http.StatusNotFound, fmt.Errorf("Not a number: %s", strings.TrimPrefix(r.URL.Path, "/queue/")))
+				return
+			}
+			var body struct {
+				Priority *int `json:"priority"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Priority == nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("expected a body like {\"priority\": 10}"))
+				return
+			}
+			if err = prioritizeQueueJob(s.job, index, *body.Priority); err != nil {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"queue": s.queue()})
+		},
+	}))
+	mux.HandleFunc("/rate", s.methods(map[string]http.HandlerFunc{"PUT": func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Rate *int `json:"rate"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Rate == nil || *body.Rate < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("expected a body like {\"rate\": 100}"))
+			return
+		}
+		s.job.Rate.ChangeRate(*body.Rate)
+		writeJSON(w, http.StatusOK, s.status(events))
+	}}))
+	mux.HandleFunc("/save", s.methods(map[string]http.HandlerFunc{"POST": func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Filename string `json:"filename"`
+			Format   string `json:"format"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Filename == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("expected a body like {\"filename\": \"results.json\", \"format\": \"json\"}"))
+			return
+		}
+		if body.Format == "" {
+			body.Format = "json"
+		}
+		if !output.ValidFormat(body.Format) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Unknown output file format: %s", body.Format))
+			return
+		}
+		if err := s.job.Output.SaveFile(body.Filename, body.Format); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"saved": body.Filename})
+	}}))
+	mux.HandleFunc("/events", s.methods(map[string]http.HandlerFunc{"GET": events.serve}))
+	return s.authenticate(mux)
+}
+
+func (s *APIServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or wrong token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// methods routes the requests to a path by their method
+func (s *APIServer) methods(handlers map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[r.Method]
+		if !ok {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *APIServer) handleFilter(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/filters/")
+	var err error
+	switch r.Method {
+	case "PUT", "POST":
+		var body struct {
+			Value string `json:"value"`
+		}
+		if err = json.NewDecoder(r.Body).Decode(&body); err != nil || body.Value == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("expected a body like {\"value\": \"404,500\"}"))
+			return
+		}
+		err = updateFilter(s.job, name, body.Value, r.Method == "PUT")
+	case "DELETE":
+		err = updateFilter(s.job, name, "none", true)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"filters": s.filters()})
+}
+
+type apiQueueJob struct {
+	Index    int    `json:"index"`
+	Url      string `json:"url"`
+	Host     string `json:"host,omitempty"`
+	Active   bool   `json:"active"`
+	Score    int    `json:"score"`
+	Priority int    `json:"priority"`
+}
+
+func (s *APIServer) queue() []apiQueueJob {
+	queue := make([]apiQueueJob, 0)
+	for index, job := range s.job.QueuedJobs() {
+		queue = append(queue, apiQueueJob{Index: index, Url: job.Url, Host: job.Host(), Active: index == 0, Score: job.Score(), Priority: job.Priority})
+	}
+	return queue
+}
+
+func (s *APIServer) filters() map[string]string {
+	filters := make(map[string]string)
+	for name, filter := range s.job.Config.MatcherManager.GetFilters() {
+		filters[name] = filter.Repr()
+	}
+	return filters
+}
+
+type apiStatus struct {
+	Paused   bool              `json:"paused"`
+	Rate     int64             `json:"rate"`
+	Results  int               `json:"results"`
+	Pending  int               `json:"pending"`
+	Progress *apiProgress      `json:"progress"`
+	Filters  map[string]string `json:"filters"`
+	Queue    []apiQueueJob     `json:"queue"`
+}
+
+func (s *APIServer) status(events *eventOutput) apiStatus {
+	return apiStatus{
+		Paused:   s.job.Paused(),
+		Rate:     s.job.Rate.CurrentConfiguredRate(),
+		Results:  len(s.job.Output.GetCurrentResults()),
+		Pending:  s.job.Output.PendingResults(),
+		Progress: events.lastProgress(),
+		Filters:  s.filters(),
+		Queue:    s.queue(),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+type apiProgress struct {
+	StartedAt  time.Time `json:"started_at"`
+	Requests   int       `json:"requests"`
+	Total      int       `json:"total"`
+	ReqSec     int64     `json:"req_sec"`
+	QueuePos   int       `json:"queue_pos"`
+	QueueTotal int       `json:"queue_total"`
+	Errors     int       `json:"errors"`
+}
+
+type apiEvent struct {
+	name string
+	data []byte
+}
+
+// eventOutput publishes the results and the progress of a job to the event
+// stream subscribers, and passes everything on to the output it wraps. A
+// subscriber that does not keep up misses events instead of slowing down the
+// job.
+type eventOutput struct {
+	ffuf.OutputProvider
+	progress    *apiProgress
+	subscribers map[chan apiEvent]bool
+	mutex       sync.Mutex
+	// observed is set when the wrapped output reports the results it records,
+	// otherwise the result events are built from the responses
+	observed bool
+}
+
+func (e *eventOutput) Result(resp ffuf.Response) {
+	e.OutputProvider.Result(resp)
+	if !e.observed {
+		e.publishResult(output.NewResult(resp))
+	}
+}
+
+func (e *eventOutput) publishResult(res ffuf.Result) {
+	e.publish("result", output.ToJsonResult(res))
+}
+
+func (e *eventOutput) Progress(status ffuf.Progress) {
+	e.OutputProvider.Progress(status)
+	progress := &apiProgress{
+		StartedAt:  status.StartedAt,
+		Requests:   status.ReqCount,
+		Total:      status.ReqTotal,
+		ReqSec:     status.ReqSec,
+		QueuePos:   status.QueuePos,
+		QueueTotal: status.QueueTotal,
+		Errors:     status.ErrorCount,
+	}
+	e.mutex.Lock()
+	e.progress = progress
+	e.mutex.Unlock()
+	e.publish("progress", progress)
+}
+
+func (e *eventOutput) Finalize() error {
+	err := e.OutputProvider.Finalize()
+	e.publish("finished", map[string]int{"results": len(e.OutputProvider.GetCurrentResults())})
+	return err
+}
+
+// SetRun passes the run of a job on to the wrapped output
+func (e *eventOutput) SetRun(hash string) {
+	if recorder, ok := e.OutputProvider.(ffuf.RunRecorder); ok {
+		recorder.SetRun(hash)
+	}
+}
+
+func (e *eventOutput) lastProgress() *apiProgress {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.progress
+}
+
+func (e *eventOutput) publish(name string, data interface{}) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for ch := range e.subscribers {
+		select {
+		case ch <- apiEvent{name: name, data: b}:
+		default:
+		}
+	}
+}
+
+// serve streams the events to a client as server-sent events
+func (e *eventOutput) serve(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+	ch := make(chan apiEvent, 256)
+	e.mutex.Lock()
+	e.subscribers[ch] = true
+	e.mutex.Unlock()
+	defer func() {
+		e.mutex.Lock()
+		delete(e.subscribers, ch)
+		e.mutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case event := <-ch:
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, event.data)
+			flusher.Flush()
+			if event.name == "finished" {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
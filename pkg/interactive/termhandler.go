@@ -178,6 +178,14 @@ func (i *interactive) handleInput(in []byte) {
 			} else {
 				i.deleteQueue(args[1])
 			}
+		case "queueprio":
+			if len(args) < 3 {
+				i.Job.Output.Error("Please define the index of a queued job and its new priority. Use \"queueshow\" for listing of jobs.")
+			} else if len(args) > 3 {
+				i.Job.Output.Error("Too many arguments for \"queueprio\"")
+			} else {
+				i.prioritizeQueue(args[1], args[2])
+			}
 		case "queueskip":
 			i.Job.SkipQueue()
 			i.Job.Output.Info("Skipping to the next queued job")
@@ -222,14 +230,15 @@ func resultProbe(res ffuf.Result) *ffuf.Response {
 	}
 }
 
-func (i *interactive) refreshResults() {
-	filters := i.Job.Config.MatcherManager.GetFilters()
+// refreshResults drops the results of a job that the active filters filter out
+func refreshResults(job *engine.Job) {
+	filters := job.Config.MatcherManager.GetFilters()
 	// Keep a result only if it survives every active filter. The filtering runs
 	// under the output lock via FilterCurrentResults, so a match arriving mid-
 	// refresh is not dropped (the previous GetCurrentResults/SetCurrentResults
 	// pair had a read-modify-write gap that lost it, and it double-counted a
 	// result once per filter it passed).
-	i.Job.Output.FilterCurrentResults(func(res ffuf.Result) bool {
+	job.Output.FilterCurrentResults(func(res ffuf.Result) bool {
 		for _, filter := range filters {
 			filterOut, _ := filter.Filter(resultProbe(res))
 			if filterOut {
@@ -240,13 +249,45 @@ func (i *interactive) refreshResults() {
 	})
 }
 
-func (i *interactive) updateFilter(name, value string, replace bool) {
+// updateFilter sets, appends to or with "none" removes a filter of a job, and
+// drops the results it filters out
+func updateFilter(job *engine.Job, name, value string, replace bool) error {
+	var err error
 	if value == "none" {
-		i.Job.Config.MatcherManager.RemoveFilter(name)
+		job.Config.MatcherManager.RemoveFilter(name)
 	} else {
-		_ = i.Job.Config.MatcherManager.AddFilter(name, value, replace)
+		err = job.Config.MatcherManager.AddFilter(name, value, replace)
+	}
+	refreshResults(job)
+	return err
+}
+
+// deleteQueueJob removes a queued job, but not the running one
+func deleteQueueJob(job *engine.Job, index int) error {
+	if index < 0 || index > len(job.QueuedJobs())-1 {
+		return fmt.Errorf("No such queued job. Use \"queueshow\" to list the jobs in queue")
 	}
-	i.refreshResults()
+	if index == 0 {
+		return fmt.Errorf("Cannot delete the currently running job. Use \"queueskip\" to advance to the next one")
+	}
+	job.DeleteQueueItem(index)
+	return nil
+}
+
+// prioritizeQueueJob sets the priority of a queued job, but not of the running one
+func prioritizeQueueJob(job *engine.Job, index int, priority int) error {
+	if index < 0 || index > len(job.QueuedJobs())-1 {
+		return fmt.Errorf("No such queued job. Use \"queueshow\" to list the jobs in queue")
+	}
+	if index == 0 {
+		return fmt.Errorf("Cannot reprioritize the currently running job")
+	}
+	job.SetQueuePriority(index, priority)
+	return nil
+}
+
+func (i *interactive) updateFilter(name, value string, replace bool) {
+	_ = updateFilter(i.Job, name, value, replace)
 }
 
 func (i *interactive) appendFilter(name, value string) {
@@ -261,26 +302,43 @@ func (i *interactive) printQueue() {
 			if index == 0 {
 				postfix = " (active job)"
 			}
-			i.Job.Output.Raw(fmt.Sprintf(" [%d] : %s%s\n", index, job.Url, postfix))
+			if i.Job.Config.RecursionOrder == "score" {
+				postfix += fmt.Sprintf(" (score: %d)", job.Score())
+			}
+			if job.Priority != 0 {
+				postfix += fmt.Sprintf(" (priority: %d)", job.Priority)
+			}
+			i.Job.Output.Raw(fmt.Sprintf(" [%d] : %s%s\n", index, job.Target(), postfix))
 		}
 	} else {
 		i.Job.Output.Info("Job queue is empty")
 	}
 }
 
+func (i *interactive) prioritizeQueue(in string, prio string) {
+	index, err := strconv.Atoi(in)
+	if err != nil {
+		i.Job.Output.Warning(fmt.Sprintf("Not a number: %s", in))
+		return
+	}
+	priority, err := strconv.Atoi(prio)
+	if err != nil {
+		i.Job.Output.Warning(fmt.Sprintf("Not a number: %s", prio))
+	} else if err = prioritizeQueueJob(i.Job, index, priority); err != nil {
+		i.Job.Output.Warning(err.Error())
+	} else {
+		i.Job.Output.Info("Job priority successfully set!")
+	}
+}
+
 func (i *interactive) deleteQueue(in string) {
 	index, err := strconv.Atoi(in)
 	if err != nil {
 		i.Job.Output.Warning(fmt.Sprintf("Not a number: %s", in))
+	} else if err = deleteQueueJob(i.Job, index); err != nil {
+		i.Job.Output.Warning(err.Error())
 	} else {
-		if index < 0 || index > len(i.Job.QueuedJobs())-1 {
-			i.Job.Output.Warning("No such queued job. Use \"queueshow\" to list the jobs in queue")
-		} else if index == 0 {
-			i.Job.Output.Warning("Cannot delete the currently running job. Use \"queueskip\" to advance to the next one")
-		} else {
-			i.Job.DeleteQueueItem(index)
-			i.Job.Output.Info("Job successfully deleted!")
-		}
+		i.Job.Output.Info("Job successfully deleted!")
 	}
 }
 
@@ -328,25 +386,26 @@ func (i *interactive) printHelp() {
 	rate := fmt.Sprintf("(active: %d)", i.Job.Rate.CurrentConfiguredRate())
 	help := `
 available commands:
- afc  [value]             - append to status code filter %s
- fc   [value]             - (re)configure status code filter %s
- afl  [value]             - append to line count filter %s
- fl   [value]             - (re)configure line count filter %s
- afw  [value]             - append to word count filter %s
- fw   [value]             - (re)configure word count filter %s
- afs  [value]             - append to size filter %s
- fs   [value]             - (re)configure size filter %s
- aft  [value]             - append to time filter %s
- ft   [value]             - (re)configure time filter %s
- rate [value]             - adjust rate of requests per second %s
- queueshow                - show job queue
- queuedel [number]        - delete a job in the queue
- queueskip                - advance to the next queued job
- restart                  - restart and resume the current ffuf job
- resume                   - resume current ffuf job (or: ENTER) 
- show                     - show results for the current job
- savejson [filename]      - save current matches to a file
- help                     - you are looking at it
+ afc  [value]              - append to status code filter %s
+ fc   [value]              - (re)configure status code filter %s
+ afl  [value]              - append to line count filter %s
+ fl   [value]              - (re)configure line count filter %s
+ afw  [value]              - append to word count filter %s
+ fw   [value]              - (re)configure word count filter %s
+ afs  [value]              - append to size filter %s
+ fs   [value]              - (re)configure size filter %s
+ aft  [value]              - append to time filter %s
+ ft   [value]              - (re)configure time filter %s
+ rate [value]              - adjust rate of requests per second %s
+ queueshow                 - show job queue
+ queuedel [number]         - delete a job in the queue
+ queueprio [number] [prio] - set the priority of a queued job, higher runs first
+ queueskip                 - advance to the next queued job
+ restart                   - restart and resume the current ffuf job
+ resume                    - resume current ffuf job (or: ENTER) 
+ show                      - show results for the current job
+ savejson [filename]       - save current matches to a file
+ help                      - you are looking at it
 `
 	i.Job.Output.Raw(fmt.Sprintf(help, fc, fc, fl, fl, fw, fw, fs, fs, ft, ft, rate))
 }
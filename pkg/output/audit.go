@@ -1,36 +1,200 @@
 package output
 
 import (
+	"bufio"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+
+	"github.com/klauspost/compress/zstd"
 )
 
+// AuditLogOptions control the rotation, the compression and the contents of an
+// audit log.
+type AuditLogOptions struct {
+	// MaxSize is the size in bytes at which the log is rotated, 0 for no limit
+	MaxSize int64
+	// MaxAge is the time after which the log is rotated, 0 for no limit
+	MaxAge time.Duration
+	// Compress is the compression of the log: "gzip", "zstd" or "" for none
+	Compress string
+	// OmitBodies leaves the bodies of the requests and responses out
+	OmitBodies bool
+}
+
+// compressor is implemented by the gzip and zstd writers
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
 type AuditLogger struct {
-	file *os.File
-	lock sync.Mutex
+	filename   string
+	options    AuditLogOptions
+	file       *os.File
+	compressor compressor
+	size       int64
+	opened     time.Time
+	entries    int
+	closed     bool
+	// config and header are the last config and header lines written, they are
+	// repeated at the top of every rotated file to keep it self-contained
+	config []byte
+	header []byte
+	lock   sync.Mutex
 }
 
 func NewAuditLogger(filename string) (*AuditLogger, error) {
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
+	return NewAuditLoggerWithOptions(filename, AuditLogOptions{})
+}
+
+// NewAuditLoggerWithOptions opens the audit log for appending. A compressed log
+// gets the .gz or .zst extension if its name does not have it already.
+func NewAuditLoggerWithOptions(filename string, options AuditLogOptions) (*AuditLogger, error) {
+	ext := ""
+	switch options.Compress {
+	case "":
+	case "gzip":
+		ext = ".gz"
+	case "zstd":
+		ext = ".zst"
+	default:
+		return nil, fmt.Errorf("unknown audit log compression: %s", options.Compress)
+	}
+	if !strings.HasSuffix(filename, ext) {
+		filename += ext
+	}
+	auditLogger := &AuditLogger{filename: filename, options: options}
+	if err := auditLogger.open(); err != nil {
 		return nil, err
 	}
-	auditLogger := &AuditLogger{file: f}
-
 	return auditLogger, nil
 }
 
+func (logger *AuditLogger) open() error {
+	f, err := os.OpenFile(logger.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	logger.file = f
+	logger.size = info.Size()
+	logger.opened = time.Now()
+	logger.entries = 0
+	logger.compressor = nil
+	// Appending to a compressed log starts a new gzip member or zstd frame, the
+	// readers of both formats continue over them
+	switch logger.options.Compress {
+	case "gzip":
+		logger.compressor = gzip.NewWriter(countingWriter{logger})
+	case "zstd":
+		logger.compressor, err = zstd.NewWriter(countingWriter{logger})
+	}
+	return err
+}
+
+// countingWriter writes to the file of the logger and keeps track of its size
+type countingWriter struct {
+	logger *AuditLogger
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	n, err := w.logger.file.Write(p)
+	w.logger.size += int64(n)
+	return n, err
+}
+
 func (logger *AuditLogger) Close() {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+	if logger.closed {
+		return
+	}
+	logger.closed = true
+	logger.closeFile()
+}
+
+func (logger *AuditLogger) closeFile() {
+	if logger.compressor != nil {
+		logger.compressor.Close()
+	}
 	logger.file.Close()
 }
 
+// rotate moves the current file aside with the time of the rotation added to
+// its name, and starts a new one with the last config and header lines.
+func (logger *AuditLogger) rotate() error {
+	logger.closeFile()
+	ext := filepath.Ext(logger.filename)
+	if logger.options.Compress == "" {
+		ext = ""
+	}
+	base := strings.TrimSuffix(logger.filename, ext) + "." + time.Now().Format("2006-01-02T15-04-05")
+	rotated := base + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			break
+		}
+		rotated = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	if err := os.Rename(logger.filename, rotated); err != nil {
+		return fmt.Errorf("could not rotate audit log: %s", err)
+	}
+	if err := logger.open(); err != nil {
+		return fmt.Errorf("could not rotate audit log: %s", err)
+	}
+	for _, line := range [][]byte{logger.config, logger.header} {
+		if line != nil {
+			if err := logger.writeLine(line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (logger *AuditLogger) writeLine(line []byte) error {
+	var w io.Writer = countingWriter{logger}
+	if logger.compressor != nil {
+		w = logger.compressor
+	}
+	if _, err := w.Write(line); err != nil {
+		return fmt.Errorf("could not write json data to audit log: %s", err)
+	}
+	if _, err := w.Write([]byte("\n")); err != nil {
+		return fmt.Errorf("could not write newline to underlying io.Writer: %w", err)
+	}
+	if logger.compressor != nil {
+		// Flushed per line to keep the size accurate and the log readable while
+		// it is being written
+		if err := logger.compressor.Flush(); err != nil {
+			return fmt.Errorf("could not write json data to audit log: %s", err)
+		}
+	}
+	return nil
+}
+
 func (logger *AuditLogger) Write(data interface{}) error {
 	logger.lock.Lock()
 	defer logger.lock.Unlock()
 
+	if logger.closed {
+		return fmt.Errorf("could not write json data to audit log: the log is closed")
+	}
+
 	d := struct {
 		Type string
 		Data interface{}
@@ -38,21 +202,163 @@ func (logger *AuditLogger) Write(data interface{}) error {
 		reflect.TypeOf(data).String(),
 		data,
 	}
+	if logger.options.OmitBodies {
+		d.Data = withoutBodies(data)
+	}
 
 	j, err := json.Marshal(d)
 	if err != nil {
 		return fmt.Errorf("could not marshal json data: %s", err)
 	}
 
-	_, err = logger.file.Write(j)
-	if err != nil {
-		return fmt.Errorf("could not write json data to audit log: %s", err)
+	if logger.entries > 0 &&
+		((logger.options.MaxSize > 0 && logger.size >= logger.options.MaxSize) ||
+			(logger.options.MaxAge > 0 && time.Since(logger.opened) >= logger.options.MaxAge)) {
+		if err = logger.rotate(); err != nil {
+			return err
+		}
 	}
 
-	_, err = logger.file.Write([]byte("\n"))
-	if err != nil {
-		return fmt.Errorf("could not write newline to underlying io.Writer: %w", err)
+	switch strings.TrimPrefix(d.Type, "*") {
+	case "ffuf.Config":
+		logger.config = j
+	case "ffuf.AuditHeader":
+		logger.header = j
+	default:
+		logger.entries++
+	}
+	return logger.writeLine(j)
+}
+
+// withoutBodies returns a copy of a request or response with the bodies left
+// out of it, and of its raw dump
+func withoutBodies(data interface{}) interface{} {
+	switch v := data.(type) {
+	case *ffuf.Request:
+		return withoutRequestBody(*v)
+	case ffuf.Request:
+		return withoutRequestBody(v)
+	case *ffuf.Response:
+		return withoutResponseBody(*v)
+	case ffuf.Response:
+		return withoutResponseBody(v)
 	}
+	return data
+}
 
-	return nil
+func withoutRequestBody(req ffuf.Request) *ffuf.Request {
+	req.Data = nil
+	req.Raw = rawHead(req.Raw)
+	return &req
+}
+
+func withoutResponseBody(resp ffuf.Response) *ffuf.Response {
+	resp.Data = nil
+	resp.Raw = rawHead(resp.Raw)
+	if resp.Request != nil {
+		resp.Request = withoutRequestBody(*resp.Request)
+	}
+	return &resp
+}
+
+// rawHead returns the request line or status line and the headers of a raw
+// HTTP message
+func rawHead(raw string) string {
+	if i := strings.Index(raw, "\r\n\r\n"); i >= 0 {
+		return raw[:i+4]
+	}
+	return raw
+}
+
+// AuditEntry is a line of an audit log read back by ReadAuditLog. Exactly one
+// of Config, Header, Request and Response is set.
+type AuditEntry struct {
+	Config   *ffuf.Config
+	Header   *ffuf.AuditHeader
+	Request  *ffuf.Request
+	Response *ffuf.Response
+}
+
+// ReadAuditLog reads the audit log written by AuditLogger from r and calls
+// handle for every entry in order. A gzip or zstd compressed log is detected
+// and decompressed. Entries of an unknown type are skipped. The matchers of a
+// logged config can not be restored, they are left out.
+func ReadAuditLog(r io.Reader, handle func(AuditEntry) error) error {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	switch {
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	case len(magic) == 4 && string(magic) == "\x28\xb5\x2f\xfd":
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+	for lineno := 1; ; lineno++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(strings.TrimSpace(string(line))) > 0 {
+			entry, perr := parseAuditLine(line)
+			if perr != nil {
+				return fmt.Errorf("audit log line %d: %s", lineno, perr)
+			}
+			if entry != nil {
+				if herr := handle(*entry); herr != nil {
+					return herr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+func parseAuditLine(line []byte) (*AuditEntry, error) {
+	var d struct {
+		Type string
+		Data json.RawMessage
+	}
+	if err := json.Unmarshal(line, &d); err != nil {
+		return nil, fmt.Errorf("could not unmarshal json data: %s", err)
+	}
+	var entry AuditEntry
+	var err error
+	switch strings.TrimPrefix(d.Type, "*") {
+	case "ffuf.Config":
+		// The matchers are an interface value, which can not be unmarshaled
+		var fields map[string]json.RawMessage
+		if err = json.Unmarshal(d.Data, &fields); err != nil {
+			break
+		}
+		delete(fields, "matchers")
+		data, _ := json.Marshal(fields)
+		entry.Config = &ffuf.Config{}
+		err = json.Unmarshal(data, entry.Config)
+	case "ffuf.AuditHeader":
+		entry.Header = &ffuf.AuditHeader{}
+		err = json.Unmarshal(d.Data, entry.Header)
+	case "ffuf.Request":
+		entry.Request = &ffuf.Request{}
+		err = json.Unmarshal(d.Data, entry.Request)
+	case "ffuf.Response":
+		entry.Response = &ffuf.Response{}
+		err = json.Unmarshal(d.Data, entry.Response)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal %s: %s", d.Type, err)
+	}
+	return &entry, nil
 }
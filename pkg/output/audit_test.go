@@ -1,7 +1,10 @@
 package output
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ffuf/ffuf/v2/pkg/ffuf"
@@ -51,8 +54,8 @@ func TestAuditLogger(t *testing.T) {
 }
 
 func TestAuditLogWrite(t *testing.T) {
-	expected := `{"Type":"ffuf.Config","Data":{"auditlog":"","autocalibration":false,"autocalibration_keyword":"","autocalibration_perhost":false,"autocalibration_strategies":null,"autocalibration_strings":null,"colors":false,"cmdline":"","configfile":"","postdata":"{\"quote\":\"I'll still be here tomorrow to high five you yesterday, my friend. Peace.\"}","debuglog":"","delay":{"Min":0,"Max":0,"IsRange":false,"HasDelay":false},"dirsearch_compatibility":false,"encoders":null,"extensions":null,"fmode":"","follow_redirects":false,"headers":{"Content-Type":"application/json","baz":"wibble","foo":"bar"},"ignorebody":false,"ignore_wordlist_comments":false,"inputmode":"","cmd_inputnum":0,"inputproviders":null,"inputshell":"","json":false,"matchers":null,"mmode":"","maxtime":0,"maxtime_job":0,"method":"POST","noninteractive":false,"outputdirectory":"","outputfile":"","outputformat":"","OutputSkipEmptyFile":false,"proxyurl":"","quiet":false,"rate":0,"raw":false,"recursion":false,"recursion_depth":0,"recursion_strategy":"","replayproxyurl":"","requestfile":"","requestproto":"","scraperfile":"","scrapers":"","sni":"","stop_403":false,"stop_all":false,"stop_errors":false,"threads":0,"timeout":0,"url":"http://example.com/aaaa","verbose":false,"wordlists":null,"http2":false,"client-cert":"","client-key":"","preflights":null,"postflights":null,"preflight_mode":"","preflight_error":""}}
-{"Type":"ffuf.Request","Data":{"Method":"POST","Host":"","Url":"http://example.com/aaaa","Headers":{"Content-Type":"application/json","baz":"wibble","foo":"bar"},"Data":"eyJxdW90ZSI6IkknbGwgc3RpbGwgYmUgaGVyZSB0b21vcnJvdyB0byBoaWdoIGZpdmUgeW91IHllc3RlcmRheSwgbXkgZnJpZW5kLiBQZWFjZS4ifQ==","Input":null,"Position":0,"Raw":"","Error":"","Timestamp":"0001-01-01T00:00:00Z"}}
+	expected := `{"Type":"ffuf.Config","Data":{"auditlog":"","autocalibration":false,"autocalibration_keyword":"","autocalibration_perhost":false,"autocalibration_strategies":null,"autocalibration_strings":null,"colors":false,"cmdline":"","configfile":"","postdata":"{\"quote\":\"I'll still be here tomorrow to high five you yesterday, my friend. Peace.\"}","debuglog":"","delay":{"Min":0,"Max":0,"IsRange":false,"HasDelay":false},"dirsearch_compatibility":false,"encoders":null,"extensions":null,"fmode":"","follow_redirects":false,"headers":{"Content-Type":"application/json","baz":"wibble","foo":"bar"},"ignorebody":false,"ignore_wordlist_comments":false,"inputmode":"","cmd_inputnum":0,"inputproviders":null,"inputshell":"","json":false,"matchers":null,"mmode":"","maxtime":0,"maxtime_job":0,"method":"POST","noninteractive":false,"outputdirectory":"","outputfile":"","outputformat":"","outputrequestdirectory":"","OutputSkipEmptyFile":false,"proxyurl":"","quiet":false,"rate":0,"raw":false,"recursion":false,"recursion_depth":0,"recursion_strategy":"","replayproxyurl":"","requestfile":"","requestproto":"","scraperfile":"","scrapers":"","sni":"","stop_403":false,"stop_all":false,"stop_errors":false,"threads":0,"timeout":0,"url":"http://example.com/aaaa","verbose":false,"wordlists":null,"http2":false,"client-cert":"","client-key":"","preflights":null,"postflights":null,"preflight_mode":"","preflight_error":"","resolve":null,"dns_resolver":"","unix_socket":"","websocket":false,"ws_timeout":0,"ws_frames":0,"graphql_query":"","graphql_variables":"","graphql_batch":0,"graphql_harvest":"","param_mine":"","param_batch":0,"multipart":null,"auditlog_max_size":0,"auditlog_max_age":0,"auditlog_compress":"","auditlog_no_body":false,"auditlog_only":null,"resultsdb":"","baseline":"","cluster":false,"api":"","metrics":"","hooks":null,"hook_concurrency":0,"hook_timeout":0,"plugins":null,"script":"","recursion_order":"","recursion_status":"","recursion_match":null,"recursion_wordlists":null,"recursion_extensions":null,"recursion_templates":null}}
+{"Type":"ffuf.Request","Data":{"Method":"POST","Host":"","Url":"http://example.com/aaaa","Headers":{"Content-Type":"application/json","baz":"wibble","foo":"bar"},"Data":"eyJxdW90ZSI6IkknbGwgc3RpbGwgYmUgaGVyZSB0b21vcnJvdyB0byBoaWdoIGZpdmUgeW91IHllc3RlcmRheSwgbXkgZnJpZW5kLiBQZWFjZS4ifQ==","Input":null,"Position":0,"Raw":"","Error":"","Timestamp":"0001-01-01T00:00:00Z","Encoding":""}}
 `
 
 	headers := make(map[string]string)
@@ -117,3 +120,154 @@ func TestAuditLogWrite(t *testing.T) {
 		t.Errorf("Error was nil, expected non-nil")
 	}
 }
+
+func TestReadAuditLog(t *testing.T) {
+	req := ffuf.Request{Method: "GET", Url: "http://example.com/FUZZ", Headers: map[string]string{"foo": "bar"}}
+	log := `{"Type":"*ffuf.Config","Data":{"url":"http://example.com/FUZZ","method":"GET","matchers":{"IsCalibrated":false}}}
+{"Type":"*ffuf.Request","Data":{"Method":"GET","Url":"http://example.com/FUZZ","Headers":{"foo":"bar"}}}
+
+{"Type":"*ffuf.Response","Data":{"StatusCode":200,"Data":"aGVsbG8=","Request":{"Method":"GET","Url":"http://example.com/FUZZ","Headers":{"foo":"bar"}}}}
+{"Type":"*ffuf.Unknown","Data":{}}`
+
+	var entries []AuditEntry
+	err := ReadAuditLog(strings.NewReader(log), func(e AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error reading audit log: %s", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Read %d entries, expected 3", len(entries))
+	}
+	if entries[0].Config == nil || entries[0].Config.Url != req.Url {
+		t.Errorf("First entry was not the config: %+v", entries[0])
+	}
+	if entries[1].Request == nil || entries[1].Request.Headers["foo"] != "bar" {
+		t.Errorf("Second entry was not the request: %+v", entries[1])
+	}
+	resp := entries[2].Response
+	if resp == nil || resp.StatusCode != 200 || string(resp.Data) != "hello" || resp.Request.Url != req.Url {
+		t.Errorf("Third entry was not the response: %+v", entries[2])
+	}
+
+	err = ReadAuditLog(strings.NewReader(log+"\n{not json"), func(AuditEntry) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "line 6") {
+		t.Errorf("Expected an error on line 6, got %v", err)
+	}
+}
+
+// readAuditFile reads an audit log file back with ReadAuditLog
+func readAuditFile(t *testing.T, filename string) []AuditEntry {
+	t.Helper()
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatalf("Error opening audit log: %s", err)
+	}
+	defer f.Close()
+	var entries []AuditEntry
+	err = ReadAuditLog(f, func(e AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error reading audit log %s: %s", filename, err)
+	}
+	return entries
+}
+
+func TestAuditLogCompression(t *testing.T) {
+	for compress, ext := range map[string]string{"gzip": ".gz", "zstd": ".zst"} {
+		filename := filepath.Join(t.TempDir(), "audit.log")
+		// Opened twice to append to an existing compressed log
+		for i := 0; i < 2; i++ {
+			audit, err := NewAuditLoggerWithOptions(filename, AuditLogOptions{Compress: compress})
+			if err != nil {
+				t.Fatalf("Error creating audit logger: %s", err)
+			}
+			if err = audit.Write(&ffuf.Request{Method: "GET", Url: "http://example.com/" + compress}); err != nil {
+				t.Errorf("Error writing audit log: %s", err)
+			}
+			audit.Close()
+		}
+		raw, err := os.ReadFile(filename + ext)
+		if err != nil {
+			t.Fatalf("Expected the %s log to be written with the %s extension: %s", compress, ext, err)
+		}
+		if strings.Contains(string(raw), "example.com") {
+			t.Errorf("The %s log was not compressed", compress)
+		}
+		entries := readAuditFile(t, filename+ext)
+		if len(entries) != 2 || entries[1].Request.Url != "http://example.com/"+compress {
+			t.Errorf("Unexpected %s log entries: %+v", compress, entries)
+		}
+	}
+	if _, err := NewAuditLoggerWithOptions(filepath.Join(t.TempDir(), "audit.log"), AuditLogOptions{Compress: "bzip2"}); err == nil {
+		t.Errorf("Expected an error for an unknown compression")
+	}
+}
+
+func TestAuditLogRotation(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "audit.log")
+	audit, err := NewAuditLoggerWithOptions(filename, AuditLogOptions{MaxSize: 300})
+	if err != nil {
+		t.Fatalf("Error creating audit logger: %s", err)
+	}
+	_ = audit.Write(&ffuf.Config{Url: "http://example.com/FUZZ"})
+	_ = audit.Write(&ffuf.AuditHeader{ConfigHash: "abcdef"})
+	for i := 0; i < 10; i++ {
+		if err = audit.Write(&ffuf.Request{Method: "GET", Url: fmt.Sprintf("http://example.com/%d", i)}); err != nil {
+			t.Fatalf("Error writing audit log: %s", err)
+		}
+	}
+	audit.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "audit.log*"))
+	if len(files) < 3 {
+		t.Fatalf("Expected the log to be rotated several times, got %v", files)
+	}
+	requests := 0
+	for _, file := range files {
+		entries := readAuditFile(t, file)
+		if len(entries) < 3 || entries[0].Config == nil || entries[1].Header == nil || entries[1].Header.ConfigHash != "abcdef" {
+			t.Errorf("Expected %s to start with the config and the header, got %+v", file, entries)
+		}
+		for _, e := range entries {
+			if e.Request != nil {
+				requests++
+			}
+		}
+	}
+	if requests != 10 {
+		t.Errorf("Expected the 10 requests over the rotated files, got %d", requests)
+	}
+}
+
+func TestAuditLogOmitBodies(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := NewAuditLoggerWithOptions(filename, AuditLogOptions{OmitBodies: true})
+	if err != nil {
+		t.Fatalf("Error creating audit logger: %s", err)
+	}
+	req := ffuf.Request{Method: "POST", Url: "http://example.com/", Data: []byte("secret=1"), Raw: "POST / HTTP/1.1\r\nHost: example.com\r\n\r\nsecret=1"}
+	resp := ffuf.Response{StatusCode: 200, Data: []byte("hello"), ContentLength: 5, Request: &req, Raw: "HTTP/1.1 200 OK\r\n\r\nhello"}
+	_ = audit.Write(&req)
+	_ = audit.Write(&resp)
+	audit.Close()
+
+	entries := readAuditFile(t, filename)
+	if len(entries) != 2 {
+		t.Fatalf("Read %d entries, expected 2", len(entries))
+	}
+	if len(entries[0].Request.Data) != 0 || strings.Contains(entries[0].Request.Raw, "secret") || !strings.HasPrefix(entries[0].Request.Raw, "POST / HTTP/1.1") {
+		t.Errorf("Expected the request body to be left out: %+v", entries[0].Request)
+	}
+	logged := entries[1].Response
+	if len(logged.Data) != 0 || logged.Raw != "HTTP/1.1 200 OK\r\n\r\n" || logged.ContentLength != 5 || len(logged.Request.Data) != 0 {
+		t.Errorf("Expected the response body to be left out: %+v", logged)
+	}
+	if string(req.Data) != "secret=1" || string(resp.Data) != "hello" {
+		t.Errorf("Writing the audit log modified the logged request or response")
+	}
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"math/bits"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+const (
+	// Sizes of the results in a cluster differ by at most this fraction of the
+	// size, or by clusterMinSizeDelta bytes
+	clusterSizeTolerance = 0.05
+	clusterMinSizeDelta  = 16
+	// Word counts differ by at most this fraction, or by clusterMinWordsDelta
+	clusterWordsTolerance = 0.05
+	clusterMinWordsDelta  = 2
+	// The simhashes of the bodies differ by at most this many bits
+	clusterSimhashDistance = 6
+)
+
+// ClusterResults groups near-identical results: those with the same status
+// code and line count, a size and word count close to each other and similar
+// bodies. Every cluster is a list of indexes to results, the first result of
+// a cluster is its representative. The clusters are in the order of their
+// representatives.
+func ClusterResults(results []ffuf.Result) [][]int {
+	clusters := make([][]int, 0)
+	// Only the clusters with the same status code and line count can match
+	candidates := make(map[[2]int64][]int)
+	for i, res := range results {
+		key := [2]int64{res.StatusCode, res.ContentLines}
+		found := false
+		for _, c := range candidates[key] {
+			if similarResults(results[clusters[c][0]], res) {
+				clusters[c] = append(clusters[c], i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			candidates[key] = append(candidates[key], len(clusters))
+			clusters = append(clusters, []int{i})
+		}
+	}
+	return clusters
+}
+
+func similarResults(a, b ffuf.Result) bool {
+	if a.StatusCode != b.StatusCode || a.ContentLines != b.ContentLines {
+		return false
+	}
+	if !closeTo(a.ContentLength, b.ContentLength, clusterSizeTolerance, clusterMinSizeDelta) ||
+		!closeTo(a.ContentWords, b.ContentWords, clusterWordsTolerance, clusterMinWordsDelta) {
+		return false
+	}
+	// Results read back from an output file have no simhash to compare
+	if a.Simhash != 0 && b.Simhash != 0 && bits.OnesCount64(a.Simhash^b.Simhash) > clusterSimhashDistance {
+		return false
+	}
+	return true
+}
+
+func closeTo(a, b int64, tolerance float64, minDelta int64) bool {
+	delta := a - b
+	if delta < 0 {
+		delta = -delta
+	}
+	allowed := int64(tolerance * float64(a))
+	if allowed < minDelta {
+		allowed = minDelta
+	}
+	return delta <= allowed
+}
+
+// simhashShingle is the length of the overlapping byte sequences of a body
+// hashed into its simhash
+const simhashShingle = 4
+
+// Simhash returns the 64 bit simhash of the overlapping 4 byte sequences of a
+// body. Similar bodies have simhashes that differ in few bits.
+func Simhash(data []byte) uint64 {
+	if len(data) < simhashShingle {
+		return shingleHash(data)
+	}
+	var weights [64]int
+	for i := 0; i+simhashShingle <= len(data); i++ {
+		sum := shingleHash(data[i : i+simhashShingle])
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var simhash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			simhash |= 1 << bit
+		}
+	}
+	return simhash
+}
+
+// shingleHash is the 64 bit FNV-1a hash of a shingle
+func shingleHash(shingle []byte) uint64 {
+	sum := uint64(14695981039346656037)
+	for _, b := range shingle {
+		sum ^= uint64(b)
+		sum *= 1099511628211
+	}
+	return sum
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"math/bits"
+	"reflect"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func clusterTestResult(status, size, words, lines int64, body string) ffuf.Result {
+	res := ffuf.Result{StatusCode: status, ContentLength: size, ContentWords: words, ContentLines: lines}
+	if body != "" {
+		res.Simhash = Simhash([]byte(body))
+	}
+	return res
+}
+
+func TestClusterResults(t *testing.T) {
+	page := "<html><head><title>Not found</title></head><body>The page you requested was not found on this server, please go back to the front page</body></html>"
+	other := "<html><head><title>Admin</title></head><body>Welcome to the administration interface, log in with your account to manage the site settings</body></html>"
+	results := []ffuf.Result{
+		clusterTestResult(200, 1000, 100, 10, page),
+		clusterTestResult(200, 1030, 101, 10, page+" /a"),
+		clusterTestResult(404, 1000, 100, 10, page),
+		clusterTestResult(200, 1100, 100, 10, page),
+		clusterTestResult(200, 1000, 100, 11, page),
+		clusterTestResult(200, 1010, 100, 10, other),
+		clusterTestResult(200, 1020, 100, 10, ""),
+		clusterTestResult(200, 1000, 110, 10, page),
+	}
+	want := [][]int{{0, 1, 6}, {2}, {3}, {4}, {5}, {7}}
+	if got := ClusterResults(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected clusters %v, got %v", want, got)
+	}
+}
+
+func TestSimhash(t *testing.T) {
+	base := "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. " +
+		"Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. " +
+		"Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. " +
+		"Excepteur sint occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim id est laborum. "
+	a := Simhash([]byte(base + "first"))
+	b := Simhash([]byte(base + "second"))
+	c := Simhash([]byte("a completely different body with other words in it"))
+	if d := bits.OnesCount64(a ^ b); d > clusterSimhashDistance {
+		t.Errorf("Expected similar bodies to be at most %d bits apart, got %d", clusterSimhashDistance, d)
+	}
+	if d := bits.OnesCount64(a ^ c); d <= clusterSimhashDistance {
+		t.Errorf("Expected different bodies to be more than %d bits apart, got %d", clusterSimhashDistance, d)
+	}
+}
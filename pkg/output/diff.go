@@ -0,0 +1,161 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// ResultDiffer compares results to the ones of a baseline run. Results are
+// matched on their URL and inputs, so the order they were found in does not
+// matter. It is safe for concurrent use.
+type ResultDiffer struct {
+	baseline map[string]ffuf.Result
+	order    []string
+	seen     map[string]bool
+	mutex    sync.Mutex
+}
+
+func NewResultDiffer(baseline []ffuf.Result) *ResultDiffer {
+	d := &ResultDiffer{
+		baseline: make(map[string]ffuf.Result, len(baseline)),
+		seen:     make(map[string]bool),
+	}
+	for _, res := range baseline {
+		key := resultKey(res)
+		if _, ok := d.baseline[key]; !ok {
+			d.baseline[key] = res
+			d.order = append(d.order, key)
+		}
+	}
+	return d
+}
+
+// Compare classifies a result as new or changed, and reports whether it differs
+// from its baseline result at all.
+func (d *ResultDiffer) Compare(res ffuf.Result) (ffuf.Result, bool) {
+	key := resultKey(res)
+	d.mutex.Lock()
+	old, ok := d.baseline[key]
+	d.seen[key] = true
+	d.mutex.Unlock()
+	if !ok {
+		res.Diff = &ffuf.ResultDiff{Change: "new"}
+		return res, true
+	}
+	if old.StatusCode == res.StatusCode && old.ContentLength == res.ContentLength && old.ContentWords == res.ContentWords {
+		return res, false
+	}
+	res.Diff = &ffuf.ResultDiff{
+		Change:                "changed",
+		BaselineStatusCode:    old.StatusCode,
+		BaselineContentLength: old.ContentLength,
+		BaselineContentWords:  old.ContentWords,
+	}
+	return res, true
+}
+
+// Gone returns the baseline results that were not compared to, in the order of
+// the baseline
+func (d *ResultDiffer) Gone() []ffuf.Result {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	gone := make([]ffuf.Result, 0)
+	for _, key := range d.order {
+		if !d.seen[key] {
+			res := d.baseline[key]
+			res.Diff = &ffuf.ResultDiff{Change: "gone"}
+			gone = append(gone, res)
+		}
+	}
+	return gone
+}
+
+// DiffResults returns the new and changed results of a run compared to the
+// results of an earlier one, followed by the results that are gone.
+func DiffResults(old, new []ffuf.Result) []ffuf.Result {
+	d := NewResultDiffer(old)
+	diff := make([]ffuf.Result, 0)
+	for _, res := range new {
+		if res, changed := d.Compare(res); changed {
+			diff = append(diff, res)
+		}
+	}
+	return append(diff, d.Gone()...)
+}
+
+// resultKey identifies a result across runs by its URL and inputs
+func resultKey(res ffuf.Result) string {
+	keys := make([]string, 0, len(res.Input))
+	for k := range res.Input {
+		if k != "FFUFHASH" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(res.Url)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\x00%s=%s", k, res.Input[k])
+	}
+	return b.String()
+}
+
+// ReadResults reads the results of a JSON (-of json) or EJSON (-of ejson)
+// output file back.
+func ReadResults(filename string) ([]ffuf.Result, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Results []json.RawMessage `json:"results"`
+		Config  json.RawMessage   `json:"config"`
+	}
+	if err = json.Unmarshal(data, &file); err != nil || file.Results == nil {
+		return nil, fmt.Errorf("%s is not a ffuf JSON output file", filename)
+	}
+	results := make([]ffuf.Result, 0, len(file.Results))
+	for i, raw := range file.Results {
+		var res ffuf.Result
+		// The EJSON output has no config, and its inputs are base64 encoded
+		// like those of a ffuf.Result
+		if len(file.Config) == 0 || string(file.Config) == "null" {
+			err = json.Unmarshal(raw, &res)
+		} else {
+			var jres JsonResult
+			if err = json.Unmarshal(raw, &jres); err == nil {
+				res = ffuf.Result{
+					Input:            make(map[string][]byte, len(jres.Input)),
+					Position:         jres.Position,
+					StatusCode:       jres.StatusCode,
+					ContentLength:    jres.ContentLength,
+					ContentWords:     jres.ContentWords,
+					ContentLines:     jres.ContentLines,
+					ContentType:      jres.ContentType,
+					RedirectLocation: jres.RedirectLocation,
+					ScraperData:      jres.ScraperData,
+					Duration:         jres.Duration,
+					ResultFile:       jres.ResultFile,
+					Url:              jres.Url,
+					Host:             jres.Host,
+					RemoteAddr:       jres.RemoteAddr,
+					Diff:             jres.Diff,
+				}
+				for k, v := range jres.Input {
+					res.Input[k] = []byte(v)
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bad result %d in %s: %s", i+1, filename, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func diffTestResult(word string, status, size, words int64) ffuf.Result {
+	return ffuf.Result{
+		Url:           "https://example.org/" + word,
+		Input:         map[string][]byte{"FUZZ": []byte(word), "FFUFHASH": []byte("abcde" + word)},
+		StatusCode:    status,
+		ContentLength: size,
+		ContentWords:  words,
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	old := []ffuf.Result{
+		diffTestResult("same", 200, 10, 1),
+		diffTestResult("status", 200, 10, 1),
+		diffTestResult("size", 200, 10, 1),
+		diffTestResult("gone", 200, 10, 1),
+	}
+	// Reordered, and found by a run with other FFUFHASH values
+	current := []ffuf.Result{
+		diffTestResult("new", 200, 10, 1),
+		diffTestResult("size", 200, 15, 1),
+		diffTestResult("status", 403, 10, 1),
+		diffTestResult("same", 200, 10, 1),
+	}
+	for i := range current {
+		current[i].Input["FFUFHASH"] = []byte("fedcb")
+	}
+
+	got := make(map[string]*ffuf.ResultDiff)
+	for _, res := range DiffResults(old, current) {
+		got[string(res.Input["FUZZ"])] = res.Diff
+	}
+	if len(got) != 4 || got["same"] != nil {
+		t.Fatalf("Expected the 4 differences only, got %v", got)
+	}
+	if got["new"].Change != "new" || got["gone"].Change != "gone" {
+		t.Errorf("Expected a new and a gone result, got %+v and %+v", got["new"], got["gone"])
+	}
+	if d := got["status"]; d.Change != "changed" || d.BaselineStatusCode != 200 {
+		t.Errorf("Unexpected status change %+v", d)
+	}
+	if d := got["size"]; d.Change != "changed" || d.BaselineContentLength != 10 {
+		t.Errorf("Unexpected size change %+v", d)
+	}
+}
+
+func TestReadResults(t *testing.T) {
+	results := []ffuf.Result{diffTestResult("admin", 200, 10, 1), diffTestResult("login", 302, 0, 0)}
+	results[1].Diff = &ffuf.ResultDiff{Change: "new"}
+	dir := t.TempDir()
+	conf := &ffuf.Config{CommandLine: "ffuf"}
+	for format, write := range map[string]func(string, *ffuf.Config, []ffuf.Result) error{"json": writeJSON, "ejson": writeEJSON} {
+		filename := filepath.Join(dir, "out."+format)
+		if err := write(filename, conf, results); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadResults(filename)
+		if err != nil {
+			t.Fatalf("ReadResults(%s): %s", format, err)
+		}
+		if len(read) != 2 || string(read[0].Input["FUZZ"]) != "admin" || read[1].StatusCode != 302 || read[1].Diff == nil {
+			t.Errorf("Unexpected results read from %s: %+v", format, read)
+		}
+	}
+	if _, err := ReadResults(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,244 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// HAR 1.2, see http://www.softwareishard.com/blog/har-12-spec/
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	// Input is the ffuf input of the match, custom fields start with an underscore
+	Input map[string]string `json:"_input,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int64          `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harBodyLimit is the most of a response body kept for the HAR output. The
+// matched exchanges are held until the output is written, and the bodies of
+// large responses would add up.
+const harBodyLimit = 256 * 1024
+
+// harExchange returns the copy of resp kept for the HAR output, with the body
+// cut to harBodyLimit
+func harExchange(resp ffuf.Response) *ffuf.Response {
+	exchange := resp
+	if len(resp.Data) > harBodyLimit {
+		exchange.Data = append([]byte(nil), resp.Data[:harBodyLimit]...)
+	}
+	return &exchange
+}
+
+func writeHAR(filename string, config *ffuf.Config, res []ffuf.Result) error {
+	har := harFile{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "ffuf", Version: ffuf.Version()},
+		Entries: make([]harEntry, 0, len(res)),
+	}}
+	for _, r := range res {
+		har.Log.Entries = append(har.Log.Entries, harResultEntry(r))
+	}
+	outBytes, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, outBytes, 0644)
+}
+
+// harResultEntry builds the HAR entry of a result from the exchange it keeps,
+// or from the summary of the result alone if there is none.
+func harResultEntry(r ffuf.Result) harEntry {
+	entry := harEntry{
+		Time:    durationMillis(r.Duration),
+		Timings: harTimings{Wait: durationMillis(r.Duration)},
+		Request: harRequest{
+			Method: "GET", URL: r.Url, HTTPVersion: "HTTP/1.1",
+			Cookies: []harNameValue{}, Headers: []harNameValue{}, QueryString: harQueryString(r.Url),
+			HeadersSize: -1, BodySize: -1,
+		},
+		Response: harResponse{
+			Status: r.StatusCode, StatusText: http.StatusText(int(r.StatusCode)), HTTPVersion: "HTTP/1.1",
+			Cookies: []harNameValue{}, Headers: []harNameValue{},
+			Content:     harContent{Size: r.ContentLength, MimeType: r.ContentType},
+			RedirectURL: r.RedirectLocation, HeadersSize: -1, BodySize: r.ContentLength,
+		},
+		Input: make(map[string]string, len(r.Input)),
+	}
+	for k, v := range r.Input {
+		if k != "FFUFHASH" {
+			entry.Input[k] = string(v)
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		entry.ServerIPAddress = host
+	}
+
+	started := time.Now()
+	if resp := r.Response; resp != nil && resp.Request != nil {
+		req := resp.Request
+		if !req.Timestamp.IsZero() {
+			started = req.Timestamp
+		}
+		entry.Request.Method = req.Method
+		entry.Request.Headers = harHeaders(req.Headers)
+		entry.Request.Cookies = harCookies((&http.Request{Header: http.Header{"Cookie": {headerLookup(req.Headers, "Cookie")}}}).Cookies())
+		entry.Request.BodySize = len(req.Data)
+		if len(req.Data) > 0 {
+			entry.Request.PostData = &harPostData{MimeType: headerLookup(req.Headers, "Content-Type"), Text: string(req.Data)}
+		}
+
+		for k, values := range resp.Headers {
+			for _, v := range values {
+				entry.Response.Headers = append(entry.Response.Headers, harNameValue{Name: k, Value: v})
+			}
+		}
+		sort.SliceStable(entry.Response.Headers, func(i, j int) bool {
+			return entry.Response.Headers[i].Name < entry.Response.Headers[j].Name
+		})
+		entry.Response.Cookies = harCookies((&http.Response{Header: http.Header(resp.Headers)}).Cookies())
+		entry.Response.Content.Size = int64(len(resp.Data))
+		if len(resp.Data) == harBodyLimit && r.ContentLength > harBodyLimit {
+			entry.Response.Content.Size = r.ContentLength
+			entry.Response.Content.Comment = fmt.Sprintf("truncated to the first %d bytes", harBodyLimit)
+		}
+		if utf8.Valid(resp.Data) {
+			entry.Response.Content.Text = string(resp.Data)
+		} else {
+			entry.Response.Content.Text = base64.StdEncoding.EncodeToString(resp.Data)
+			entry.Response.Content.Encoding = "base64"
+		}
+	}
+	entry.StartedDateTime = started.Format("2006-01-02T15:04:05.000Z07:00")
+	return entry
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func harHeaders(headers map[string]string) []harNameValue {
+	list := make([]harNameValue, 0, len(headers))
+	for k, v := range headers {
+		list = append(list, harNameValue{Name: k, Value: v})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func harCookies(cookies []*http.Cookie) []harNameValue {
+	list := make([]harNameValue, 0, len(cookies))
+	for _, c := range cookies {
+		list = append(list, harNameValue{Name: c.Name, Value: c.Value})
+	}
+	return list
+}
+
+func harQueryString(rawurl string) []harNameValue {
+	list := []harNameValue{}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return list
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		list = append(list, harNameValue{Name: name, Value: value})
+	}
+	return list
+}
+
+// headerLookup returns the value of header name, matched case insensitively
+func headerLookup(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,9 +1,16 @@
 package output
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"html"
 	"html/template"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ffuf/ffuf/v2/pkg/ffuf"
@@ -25,149 +32,349 @@ type htmlResult struct {
 	Host             string
 	HTMLColor        string
 	FfufHash         string
+	// ClusterSize is the number of results in the -cluster of a representative
+	// result, Members are the other results in it
+	ClusterSize int
+	Members     []htmlResult
+	// GroupHost and GroupDir are what the report groups the result under
+	GroupHost string
+	GroupDir  string
+	// Preview is the start of the response saved to -od
+	Preview          string
+	PreviewTruncated bool
 }
 
 type htmlFileOutput struct {
 	CommandLine string
 	Time        string
+	Config      string
 	Keys        []string
 	Results     []htmlResult
+	Clustered   bool
+	Previews    bool
 }
 
 const (
-	htmlTemplate = `
-<!DOCTYPE html>
+	// htmlPreviewLimit is the number of bytes of a -od response shown in the
+	// report. Every row carries one, the full response stays in the -od file.
+	htmlPreviewLimit = 4 * 1024
+
+	htmlTemplate = `<!DOCTYPE html>
 <html>
   <head>
     <meta http-equiv="Content-Type" content="text/html; charset=UTF-8" />
-    <meta
-      name="viewport"
-      content="width=device-width, initial-scale=1, maximum-scale=1.0"
-    />
-    <title>FFUF Report - </title>
-
-    <!-- CSS  -->
-    <link
-      href="https://fonts.googleapis.com/icon?family=Material+Icons"
-      rel="stylesheet"
-    />
-    <link
-      rel="stylesheet"
-      href="https://cdnjs.cloudflare.com/ajax/libs/materialize/1.0.0/css/materialize.min.css"
-	/>
-	<link 
-	  rel="stylesheet" 
-	  type="text/css" 
-	  href="https://cdn.datatables.net/1.10.20/css/jquery.dataTables.css"
-	/>
-  
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+    <title>FFUF Report</title>
+    <style>
+      body { font-family: -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; margin: 0; color: #222; }
+      header { background: #26a69a; color: #fff; padding: 12px 24px; font-size: 24px; }
+      main { padding: 16px 24px; }
+      pre { white-space: pre-wrap; word-break: break-all; margin: 4px 0; }
+      .controls { margin: 16px 0; display: flex; gap: 16px; align-items: center; flex-wrap: wrap; }
+      .controls input, .controls select { padding: 4px 6px; }
+      table { border-collapse: collapse; width: 100%; font-size: 13px; }
+      th, td { border: 1px solid #ddd; padding: 4px 6px; text-align: left; vertical-align: top; }
+      th { background: #f2f2f2; cursor: pointer; user-select: none; white-space: nowrap; }
+      th.sorted-asc::after { content: " \25B2"; }
+      th.sorted-desc::after { content: " \25BC"; }
+      tr.filters th { cursor: default; background: #fafafa; }
+      tr.filters input { width: 100%; box-sizing: border-box; min-width: 40px; }
+      tr.group td { background: #555; color: #fff; cursor: pointer; font-weight: bold; }
+      td.preview pre { max-height: 400px; overflow: auto; background: #f8f8f8; padding: 6px; }
+      a { color: #0b57a4; }
+    </style>
   </head>
 
   <body>
-    <nav>
-      <div class="nav-wrapper">
-        <a href="#" class="brand-logo">FFUF</a>
-        <ul id="nav-mobile" class="right hide-on-med-and-down">
-        </ul>
-      </div>
-    </nav>
+    <header>FFUF Report</header>
+    <main>
+      <pre>{{ .CommandLine }}</pre>
+      <pre>{{ .Time }}</pre>
+      <details>
+        <summary>Configuration</summary>
+        <pre>{{ .Config }}</pre>
+      </details>
 
-    <main class="section no-pad-bot" id="index-banner">
-      <div class="container">
-        <br /><br />
-        <h1 class="header center ">FFUF Report</h1>
-        <div class="row center">
+      <div style="display:none">
+|result_raw|StatusCode{{ range $keyword := .Keys }}|{{ $keyword | printf "%s" }}{{ end }}|Url|RedirectLocation|Position|ContentLength|ContentWords|ContentLines|ContentType|Duration|Resultfile|ScraperData|FfufHash|
+{{ range $result := .Results }}|result_raw|{{ $result.StatusCode }}{{ range $keyword, $value := $result.Input }}|{{ $value | printf "%s" }}{{ end }}|{{ $result.Url }}|{{ $result.RedirectLocation }}|{{ $result.Position }}|{{ $result.ContentLength }}|{{ $result.ContentWords }}|{{ $result.ContentLines }}|{{ $result.ContentType }}|{{ $result.Duration }}|{{ $result.ResultFile }}|{{ $result.ScraperData }}|{{ $result.FfufHash }}|
+{{ range $member := $result.Members }}|result_raw|{{ $member.StatusCode }}{{ range $keyword, $value := $member.Input }}|{{ $value | printf "%s" }}{{ end }}|{{ $member.Url }}|{{ $member.RedirectLocation }}|{{ $member.Position }}|{{ $member.ContentLength }}|{{ $member.ContentWords }}|{{ $member.ContentLines }}|{{ $member.ContentType }}|{{ $member.Duration }}|{{ $member.ResultFile }}|{{ $member.ScraperData }}|{{ $member.FfufHash }}|
+{{ end }}{{ end }}      </div>
 
-		<pre>{{ .CommandLine }}</pre>
-		<pre>{{ .Time }}</pre>
+      <div class="controls">
+        <label>Search <input type="search" id="search" placeholder="Search all columns and previews" size="40" /></label>
+        <label>Group by
+          <select id="grouping">
+            <option value="">Nothing</option>
+            <option value="host">Host</option>
+            <option value="dir">Directory</option>
+          </select>
+        </label>
+        <span id="count"></span>
+      </div>
 
-   <table id="ffufreport">
+      <table id="ffufreport">
         <thead>
-        <div style="display:none">
-|result_raw|StatusCode{{ range $keyword := .Keys }}|{{ $keyword | printf "%s" }}{{ end }}|Url|RedirectLocation|Position|ContentLength|ContentWords|ContentLines|ContentType|Duration|Resultfile|ScraperData|FfufHash|
-        </div>
           <tr>
-              <th>Status</th>
-{{ range .Keys }}              <th>{{ . }}</th>{{ end }}
-			  <th>URL</th>
-			  <th>Redirect location</th>
-              <th>Position</th>
-              <th>Length</th>
-              <th>Words</th>
-			  <th>Lines</th>
-			  <th>Type</th>
-              <th>Duration</th>
-			  <th>Resultfile</th>
-              <th>Scraper data</th>
-              <th>Ffuf Hash</th>
-          </tr>
+            <th>Status</th>
+{{ range .Keys }}            <th>{{ . }}</th>
+{{ end }}            <th>URL</th>
+            <th>Redirect location</th>
+            <th>Position</th>
+            <th>Length</th>
+            <th>Words</th>
+            <th>Lines</th>
+            <th>Type</th>
+            <th>Duration</th>
+            <th>Resultfile</th>
+            <th>Scraper data</th>
+            <th>Ffuf Hash</th>
+{{ if .Clustered }}            <th>Cluster</th>
+{{ end }}{{ if .Previews }}            <th>Response</th>
+{{ end }}          </tr>
         </thead>
 
         <tbody>
-			{{range $result := .Results}}
-                <div style="display:none">
-|result_raw|{{ $result.StatusCode }}{{ range $keyword, $value := $result.Input }}|{{ $value | printf "%s" }}{{ end }}|{{ $result.Url }}|{{ $result.RedirectLocation }}|{{ $result.Position }}|{{ $result.ContentLength }}|{{ $result.ContentWords }}|{{ $result.ContentLines }}|{{ $result.ContentType }}|{{ $result.Duration }}|{{ $result.ResultFile }}|{{ $result.ScraperData }}|{{ $result.FfufHash }}|
-                </div>
-                <tr class="result-{{ $result.StatusCode }}" style="background-color: {{ $result.HTMLColor }};">
-                    <td><font color="black" class="status-code">{{ $result.StatusCode }}</font></td>
-                    {{ range $keyword, $value := $result.Input }}
-                        <td>{{ $value | printf "%s" }}</td>
-                    {{ end }}
-                    <td><a href="{{ $result.Url }}">{{ $result.Url }}</a></td>
-                    <td><a href="{{ $result.RedirectLocation }}">{{ $result.RedirectLocation }}</a></td>
-                    <td>{{ $result.Position }}</td>
-                    <td>{{ $result.ContentLength }}</td>
-                    <td>{{ $result.ContentWords }}</td>
-					<td>{{ $result.ContentLines }}</td>
-					<td>{{ $result.ContentType }}</td>
-					<td>{{ $result.Duration }}</td>
-                    <td>{{ $result.ResultFile }}</td>
-					<td>{{ $result.ScraperData }}</td>
-					<td>{{ $result.FfufHash }}</td>
-                </tr>
-            {{ end }}
-        </tbody>
+{{ range $result := .Results }}          <tr class="result result-{{ $result.StatusCode }}" style="background-color: {{ $result.HTMLColor }};" data-host="{{ $result.GroupHost }}" data-dir="{{ $result.GroupDir }}">
+            <td class="status-code">{{ $result.StatusCode }}</td>
+{{ range $keyword, $value := $result.Input }}            <td>{{ $value | printf "%s" }}</td>
+{{ end }}            <td><a href="{{ $result.Url }}">{{ $result.Url }}</a></td>
+            <td><a href="{{ $result.RedirectLocation }}">{{ $result.RedirectLocation }}</a></td>
+            <td>{{ $result.Position }}</td>
+            <td>{{ $result.ContentLength }}</td>
+            <td>{{ $result.ContentWords }}</td>
+            <td>{{ $result.ContentLines }}</td>
+            <td>{{ $result.ContentType }}</td>
+            <td data-value="{{ $result.Duration.Milliseconds }}">{{ $result.Duration }}</td>
+            <td>{{ $result.ResultFile }}</td>
+            <td>{{ $result.ScraperData }}</td>
+            <td>{{ $result.FfufHash }}</td>
+{{ if $.Clustered }}            <td data-value="{{ $result.ClusterSize }}">{{ if $result.Members }}
+              <details>
+                <summary>{{ $result.ClusterSize }} results</summary>
+{{ range $member := $result.Members }}                <p><a href="{{ $member.Url }}">{{ $member.Url }}</a><br />Status: {{ $member.StatusCode }}, Size: {{ $member.ContentLength }}, Words: {{ $member.ContentWords }}, Lines: {{ $member.ContentLines }}</p>
+{{ end }}              </details>
+            {{ end }}</td>
+{{ end }}{{ if $.Previews }}            <td class="preview">{{ if $result.Preview }}
+              <details>
+                <summary>Preview{{ if $result.PreviewTruncated }} (truncated){{ end }}</summary>
+                <pre>{{ $result.Preview }}</pre>
+              </details>
+            {{ end }}</td>
+{{ end }}          </tr>
+{{ end }}        </tbody>
       </table>
-
-        </div>
-        <br /><br />
-      </div>
     </main>
 
-    <!--JavaScript at end of body for optimized loading-->
-	<script src="https://code.jquery.com/jquery-3.4.1.min.js" integrity="sha256-CSXorXvZcTkaix6Yvo6HppcZGetbYMGWSFlBw8HfCJo=" crossorigin="anonymous"></script>
-    <script src="https://cdnjs.cloudflare.com/ajax/libs/materialize/1.0.0/js/materialize.min.js"></script>
-    <script type="text/javascript" charset="utf8" src="https://cdn.datatables.net/1.10.20/js/jquery.dataTables.js"></script>
     <script>
-    $(document).ready(function() {
-        $('#ffufreport').DataTable(
-            {
-                "aLengthMenu": [
-                    [250, 500, 1000, 2500, -1],
-                    [250, 500, 1000, 2500, "All"]
-                ]
+    (function() {
+      var table = document.getElementById("ffufreport");
+      var tbody = table.tBodies[0];
+      var headers = table.tHead.rows[0].cells;
+      var rows = Array.prototype.slice.call(tbody.rows);
+      var search = document.getElementById("search");
+      var grouping = document.getElementById("grouping");
+      var sortColumn = -1, sortAscending = true;
+      var collapsed = {};
+      var filters = [];
+
+      function cellValue(row, column) {
+        var cell = row.cells[column];
+        if (cell.hasAttribute("data-value")) {
+          return cell.getAttribute("data-value");
+        }
+        return cell.textContent.trim();
+      }
+
+      function isNumber(value) {
+        return value !== "" && !isNaN(Number(value));
+      }
+
+      // A filter is a case insensitive substring, or a comparison like ">100"
+      // for the numeric columns
+      function matchesFilter(value, filter) {
+        var m = /^(<=|>=|<|>|=)\s*(-?\d+(\.\d+)?)$/.exec(filter);
+        if (m) {
+          if (!isNumber(value)) {
+            return false;
+          }
+          var v = Number(value), n = Number(m[2]);
+          switch (m[1]) {
+            case "<": return v < n;
+            case "<=": return v <= n;
+            case ">": return v > n;
+            case ">=": return v >= n;
+            default: return v === n;
+          }
+        }
+        return value.toLowerCase().indexOf(filter.toLowerCase()) !== -1;
+      }
+
+      function compare(a, b) {
+        var x = cellValue(a, sortColumn), y = cellValue(b, sortColumn);
+        if (isNumber(x) && isNumber(y)) {
+          return Number(x) - Number(y);
+        }
+        return x.localeCompare(y);
+      }
+
+      function visible(row) {
+        var query = search.value.trim().toLowerCase();
+        if (query && row.textContent.toLowerCase().indexOf(query) === -1) {
+          return false;
+        }
+        for (var i = 0; i < filters.length; i++) {
+          var filter = filters[i].value.trim();
+          if (filter && !matchesFilter(cellValue(row, i), filter)) {
+            return false;
+          }
+        }
+        return true;
+      }
+
+      function render() {
+        var ordered = rows.slice();
+        if (sortColumn >= 0) {
+          ordered.sort(function(a, b) { return sortAscending ? compare(a, b) : compare(b, a); });
+        }
+        var group = grouping.value;
+        if (group) {
+          // Array.prototype.sort is stable, the rows stay sorted within a group
+          ordered.sort(function(a, b) {
+            return a.getAttribute("data-" + group).localeCompare(b.getAttribute("data-" + group));
+          });
+        }
+        Array.prototype.slice.call(tbody.querySelectorAll("tr.group")).forEach(function(row) {
+          tbody.removeChild(row);
+        });
+        var shown = 0, groupRow = null, groupKey = null, groupCount = 0;
+        ordered.forEach(function(row) {
+          var show = visible(row);
+          if (group) {
+            var key = row.getAttribute("data-" + group);
+            if (key !== groupKey) {
+              groupKey = key;
+              groupCount = 0;
+              groupRow = tbody.insertRow(-1);
+              groupRow.className = "group";
+              groupRow.setAttribute("data-key", key);
+              groupRow.insertCell(0).colSpan = headers.length;
+              groupRow.onclick = function() {
+                var k = this.getAttribute("data-key");
+                collapsed[k] = !collapsed[k];
+                render();
+              };
+            }
+            if (show) {
+              groupCount++;
+              groupRow.cells[0].textContent = (collapsed[key] ? "▶ " : "▼ ") + key + " (" + groupCount + ")";
             }
-        )
-        $('select').formSelect();
+            groupRow.style.display = groupCount > 0 ? "" : "none";
+          }
+          tbody.appendChild(row);
+          if (show) {
+            shown++;
+          }
+          row.style.display = show && !(group && collapsed[groupKey]) ? "" : "none";
         });
-    </script>
-    <style>
-      body {
-        display: flex;
-        min-height: 100vh;
-        flex-direction: column;
+        document.getElementById("count").textContent = shown + " of " + rows.length + " results";
       }
 
-      main {
-        flex: 1 0 auto;
-      }
-    </style>
+      var filterRow = table.tHead.insertRow(-1);
+      filterRow.className = "filters";
+      Array.prototype.slice.call(headers).forEach(function(header, i) {
+        var input = document.createElement("input");
+        input.type = "text";
+        input.placeholder = "Filter";
+        input.title = "Text the column contains, or a comparison like >100";
+        input.oninput = render;
+        filterRow.appendChild(document.createElement("th")).appendChild(input);
+        filters.push(input);
+        header.onclick = function() {
+          sortAscending = sortColumn === i ? !sortAscending : true;
+          sortColumn = i;
+          Array.prototype.slice.call(headers).forEach(function(h) { h.className = ""; });
+          header.className = sortAscending ? "sorted-asc" : "sorted-desc";
+          render();
+        };
+      });
+      search.oninput = render;
+      grouping.onchange = render;
+      render();
+    })();
+    </script>
   </body>
 </html>
-
-	`
+`
 )
 
+// htmlGroups returns the host and the directory of the URL of a result
+func htmlGroups(r ffuf.Result) (string, string) {
+	u, err := url.Parse(r.Url)
+	if err != nil || u.Host == "" {
+		return r.Host, r.Url
+	}
+	dir := u.Path
+	if i := strings.LastIndex(dir, "/"); i >= 0 {
+		dir = dir[:i+1]
+	} else {
+		dir = "/"
+	}
+	return u.Host, u.Scheme + "://" + u.Host + dir
+}
+
+// htmlPreview returns the start of the response of a -od result file, and
+// whether it was cut to htmlPreviewLimit bytes. Only the preview is read, not
+// the whole response.
+func htmlPreview(filename string) (string, bool) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	found, err := skipPast(r, []byte(resultFileSeparator))
+	if err != nil {
+		return "", false
+	}
+	if !found {
+		// Not a request and a response, show the file from its start
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return "", false
+		}
+		r.Reset(f)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, htmlPreviewLimit+1))
+	if err != nil {
+		return "", false
+	}
+	if len(data) > htmlPreviewLimit {
+		return strings.ToValidUTF8(string(data[:htmlPreviewLimit]), ""), true
+	}
+	return strings.ToValidUTF8(string(data), ""), false
+}
+
+// skipPast reads r up to and including sep, and reports whether it was found
+func skipPast(r *bufio.Reader, sep []byte) (bool, error) {
+	window := make([]byte, 0, len(sep))
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if len(window) == len(sep) {
+			window = append(window[:0], window[1:]...)
+		}
+		window = append(window, c)
+		if bytes.Equal(window, sep) {
+			return true, nil
+		}
+	}
+}
+
 // colorizeResults returns a new slice with HTMLColor attribute
 func colorizeResults(results []ffuf.Result) []ffuf.Result {
 	newResults := make([]ffuf.Result, 0)
@@ -210,6 +417,7 @@ func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result) erro
 		keywords = append(keywords, inputprovider.Keyword)
 	}
 	htmlResults := make([]htmlResult, 0)
+	previews := false
 
 	for _, r := range results {
 		ffufhash := ""
@@ -253,13 +461,39 @@ func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result) erro
 			HTMLColor:        r.HTMLColor,
 			FfufHash:         ffufhash,
 		}
+		hres.GroupHost, hres.GroupDir = htmlGroups(r)
+		if config.OutputDirectory != "" && r.ResultFile != "" {
+			hres.Preview, hres.PreviewTruncated = htmlPreview(filepath.Join(config.OutputDirectory, r.ResultFile))
+			previews = true
+		}
 		htmlResults = append(htmlResults, hres)
 	}
+	if config.Cluster {
+		// Only the representative of every cluster gets a row, with the other
+		// results of the cluster expandable in it
+		clustered := make([]htmlResult, 0)
+		for _, members := range ClusterResults(results) {
+			hres := htmlResults[members[0]]
+			hres.ClusterSize = len(members)
+			for _, i := range members[1:] {
+				hres.Members = append(hres.Members, htmlResults[i])
+			}
+			clustered = append(clustered, hres)
+		}
+		htmlResults = clustered
+	}
+	configJSON, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
 	outHTML := htmlFileOutput{
 		CommandLine: config.CommandLine,
 		Time:        ti.Format(time.RFC3339),
+		Config:      string(configJSON),
 		Results:     htmlResults,
 		Keys:        keywords,
+		Clustered:   config.Cluster,
+		Previews:    previews,
 	}
 
 	f, err := os.Create(filename)
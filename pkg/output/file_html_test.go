@@ -0,0 +1,36 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTMLPreview(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small")
+	large := filepath.Join(dir, "large")
+	request := "GET / HTTP/1.1\r\n\r\n"
+	if err := os.WriteFile(small, []byte(request+resultFileSeparator+"HTTP/1.1 200 OK\r\n\r\nhello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(large, []byte(request+resultFileSeparator+strings.Repeat("a", htmlPreviewLimit+1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if preview, truncated := htmlPreview(small); preview != "HTTP/1.1 200 OK\r\n\r\nhello" || truncated {
+		t.Errorf("preview %q, truncated %t", preview, truncated)
+	}
+	if preview, truncated := htmlPreview(large); len(preview) != htmlPreviewLimit || !truncated {
+		t.Errorf("preview of %d bytes, truncated %t, want %d bytes cut", len(preview), truncated, htmlPreviewLimit)
+	}
+
+	plain := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plain, []byte("no separator"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if preview, truncated := htmlPreview(plain); preview != "no separator" || truncated {
+		t.Errorf("preview %q of a file without a request, truncated %t", preview, truncated)
+	}
+}
@@ -12,9 +12,18 @@ type ejsonFileOutput struct {
 	CommandLine string        `json:"commandline"`
 	Time        string        `json:"time"`
 	Results     []ffuf.Result `json:"results"`
+	Clusters    []jsonCluster `json:"clusters,omitempty"`
 	Config      *ffuf.Config  `json:"config"`
 }
 
+// jsonCluster is a -cluster of near-identical results. Representative and
+// Members are indexes to the results.
+type jsonCluster struct {
+	Count          int   `json:"count"`
+	Representative int   `json:"representative"`
+	Members        []int `json:"members"`
+}
+
 type JsonResult struct {
 	Input            map[string]string   `json:"input"`
 	Position         int                 `json:"position"`
@@ -29,13 +38,32 @@ type JsonResult struct {
 	ResultFile       string              `json:"resultfile"`
 	Url              string              `json:"url"`
 	Host             string              `json:"host"`
+	RemoteAddr       string              `json:"remoteaddr"`
+	Diff             *ffuf.ResultDiff    `json:"diff,omitempty"`
 }
 
 type jsonFileOutput struct {
-	CommandLine string       `json:"commandline"`
-	Time        string       `json:"time"`
-	Results     []JsonResult `json:"results"`
-	Config      *ffuf.Config `json:"config"`
+	CommandLine string        `json:"commandline"`
+	Time        string        `json:"time"`
+	Results     []JsonResult  `json:"results"`
+	Clusters    []jsonCluster `json:"clusters,omitempty"`
+	Config      *ffuf.Config  `json:"config"`
+}
+
+// jsonClusters returns the clusters of the results with -cluster
+func jsonClusters(config *ffuf.Config, res []ffuf.Result) []jsonCluster {
+	if !config.Cluster {
+		return nil
+	}
+	clusters := make([]jsonCluster, 0)
+	for _, members := range ClusterResults(res) {
+		clusters = append(clusters, jsonCluster{
+			Count:          len(members),
+			Representative: members[0],
+			Members:        members,
+		})
+	}
+	return clusters
 }
 
 func writeEJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
@@ -44,6 +72,7 @@ func writeEJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
 		CommandLine: config.CommandLine,
 		Time:        t.Format(time.RFC3339),
 		Results:     res,
+		Clusters:    jsonClusters(config, res),
 	}
 
 	outBytes, err := json.Marshal(outJSON)
@@ -57,34 +86,43 @@ func writeEJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
 	return nil
 }
 
+// ToJsonResult returns a result as it is written to the JSON output, with the
+// inputs as strings
+func ToJsonResult(r ffuf.Result) JsonResult {
+	strinput := make(map[string]string)
+	for k, v := range r.Input {
+		strinput[k] = string(v)
+	}
+	return JsonResult{
+		Input:            strinput,
+		Position:         r.Position,
+		StatusCode:       r.StatusCode,
+		ContentLength:    r.ContentLength,
+		ContentWords:     r.ContentWords,
+		ContentLines:     r.ContentLines,
+		ContentType:      r.ContentType,
+		RedirectLocation: r.RedirectLocation,
+		ScraperData:      r.ScraperData,
+		Duration:         r.Duration,
+		ResultFile:       r.ResultFile,
+		Url:              r.Url,
+		Host:             r.Host,
+		RemoteAddr:       r.RemoteAddr,
+		Diff:             r.Diff,
+	}
+}
+
 func writeJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
 	t := time.Now()
 	jsonRes := make([]JsonResult, 0)
 	for _, r := range res {
-		strinput := make(map[string]string)
-		for k, v := range r.Input {
-			strinput[k] = string(v)
-		}
-		jsonRes = append(jsonRes, JsonResult{
-			Input:            strinput,
-			Position:         r.Position,
-			StatusCode:       r.StatusCode,
-			ContentLength:    r.ContentLength,
-			ContentWords:     r.ContentWords,
-			ContentLines:     r.ContentLines,
-			ContentType:      r.ContentType,
-			RedirectLocation: r.RedirectLocation,
-			ScraperData:      r.ScraperData,
-			Duration:         r.Duration,
-			ResultFile:       r.ResultFile,
-			Url:              r.Url,
-			Host:             r.Host,
-		})
+		jsonRes = append(jsonRes, ToJsonResult(r))
 	}
 	outJSON := jsonFileOutput{
 		CommandLine: config.CommandLine,
 		Time:        t.Format(time.RFC3339),
 		Results:     jsonRes,
+		Clusters:    jsonClusters(config, res),
 		Config:      config,
 	}
 	outBytes, err := json.Marshal(outJSON)
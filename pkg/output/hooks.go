@@ -0,0 +1,169 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/input"
+)
+
+// hookQueueSize is the number of fired hooks that can wait for a free worker.
+// The hooks fired while the queue is full are dropped, so that a slow hook
+// never holds up the scan.
+const hookQueueSize = 1024
+
+// HookPayload is the JSON a -hook command reads from its stdin, and a -hook
+// URL is posted
+type HookPayload struct {
+	Event   string      `json:"event"`
+	Time    time.Time   `json:"time"`
+	Url     string      `json:"url"`
+	Message string      `json:"message,omitempty"`
+	Result  *JsonResult `json:"result,omitempty"`
+}
+
+// HookRunner runs the -hook commands and webhooks in HookConcurrency worker
+// goroutines, each for at most HookTimeout seconds
+type HookRunner struct {
+	hooks   []ffuf.HookConfig
+	timeout time.Duration
+	client  *http.Client
+	tasks   chan hookTask
+	wg      sync.WaitGroup
+	mutex   sync.RWMutex // guards closed against a Fire racing Close
+	closed  bool
+}
+
+type hookTask struct {
+	hook    ffuf.HookConfig
+	event   ffuf.HookEvent
+	payload []byte
+}
+
+func NewHookRunner(conf *ffuf.Config) *HookRunner {
+	h := &HookRunner{
+		hooks:   conf.Hooks,
+		timeout: time.Duration(conf.HookTimeout) * time.Second,
+		client:  &http.Client{},
+		tasks:   make(chan hookTask, hookQueueSize),
+	}
+	for i := 0; i < conf.HookConcurrency; i++ {
+		h.wg.Add(1)
+		go h.worker()
+	}
+	return h
+}
+
+// Fire queues the hooks that run on an event, without waiting for them
+func (h *HookRunner) Fire(event ffuf.HookEvent) {
+	var payload []byte
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	if h.closed {
+		return
+	}
+	for _, hook := range h.hooks {
+		if !hook.Runs(event.Name) {
+			continue
+		}
+		if payload == nil {
+			// The payload is built here, as the response may change after Fire returns
+			payload = hookPayload(event)
+		}
+		select {
+		case h.tasks <- hookTask{hook: hook, event: event, payload: payload}:
+		default:
+			log.Printf("Too many hooks running, dropped the %s hook for %s", event.Name, event.Url)
+		}
+	}
+}
+
+// Close waits for the queued hooks to finish
+func (h *HookRunner) Close() {
+	h.mutex.Lock()
+	if h.closed {
+		h.mutex.Unlock()
+		return
+	}
+	h.closed = true
+	close(h.tasks)
+	h.mutex.Unlock()
+	h.wg.Wait()
+}
+
+func (h *HookRunner) worker() {
+	defer h.wg.Done()
+	for task := range h.tasks {
+		var err error
+		if task.hook.Url != "" {
+			err = h.post(task)
+		} else {
+			err = h.run(task)
+		}
+		if err != nil {
+			log.Printf("Hook on %s failed: %s", task.event.Name, err)
+		}
+	}
+}
+
+// run runs a command hook with the payload on its stdin
+func (h *HookRunner) run(task hookTask) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, input.SHELL_CMD, input.SHELL_ARG, task.hook.Command)
+	cmd.Stdin = bytes.NewReader(task.payload)
+	cmd.Env = append(os.Environ(), "FFUF_EVENT="+task.event.Name, "FFUF_URL="+task.event.Url)
+	// Do not wait for the children of a killed command to close its output
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("command %q: %s", task.hook.Command, err)
+	}
+	return nil
+}
+
+// post posts the payload to a webhook URL
+func (h *HookRunner) post(task hookTask) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", task.hook.Url, bytes.NewReader(task.payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("%s v%s", "Fuzz Faster U Fool", ffuf.Version()))
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %s", task.hook.Url, resp.Status)
+	}
+	return nil
+}
+
+func hookPayload(event ffuf.HookEvent) []byte {
+	p := HookPayload{
+		Event:   event.Name,
+		Time:    time.Now(),
+		Url:     event.Url,
+		Message: event.Message,
+	}
+	if event.Response != nil {
+		res := ToJsonResult(NewResult(*event.Response))
+		p.Result = &res
+	}
+	payload, _ := json.Marshal(p)
+	return payload
+}
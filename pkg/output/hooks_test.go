@@ -0,0 +1,71 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func TestHookRunner(t *testing.T) {
+	var mutex sync.Mutex
+	posted := make([]HookPayload, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p HookPayload
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("webhook payload %q: %s", body, err)
+		}
+		mutex.Lock()
+		posted = append(posted, p)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	stdin := filepath.Join(t.TempDir(), "stdin.json")
+	conf := &ffuf.Config{HookConcurrency: 2, HookTimeout: 10}
+	conf.Hooks = []ffuf.HookConfig{
+		{Events: []string{ffuf.HookMatch}, Url: server.URL},
+		{Events: []string{ffuf.HookJobFinished}, Command: "cat > " + stdin},
+	}
+	hooks := NewHookRunner(conf)
+	req := ffuf.Request{Url: "http://example.org/admin", Input: map[string][]byte{"FUZZ": []byte("admin")}}
+	resp := ffuf.Response{StatusCode: 200, ContentLength: 12, Request: &req}
+	hooks.Fire(ffuf.HookEvent{Name: ffuf.HookMatch, Url: req.Url, Response: &resp})
+	hooks.Fire(ffuf.HookEvent{Name: ffuf.HookError, Url: req.Url, Message: "no hook runs on this"})
+	hooks.Fire(ffuf.HookEvent{Name: ffuf.HookJobFinished, Url: "http://example.org/FUZZ"})
+	hooks.Close()
+	// Firing after Close is a no-op
+	hooks.Fire(ffuf.HookEvent{Name: ffuf.HookMatch, Url: req.Url, Response: &resp})
+
+	if len(posted) != 1 || posted[0].Event != ffuf.HookMatch || posted[0].Result == nil || posted[0].Result.Url != req.Url || posted[0].Result.StatusCode != 200 {
+		t.Errorf("unexpected webhook payloads %+v", posted)
+	}
+	b, err := os.ReadFile(stdin)
+	if err != nil {
+		t.Fatalf("the command hook did not run: %s", err)
+	}
+	var p HookPayload
+	if err := json.Unmarshal(b, &p); err != nil || p.Event != ffuf.HookJobFinished || p.Url != "http://example.org/FUZZ" || p.Result != nil {
+		t.Errorf("unexpected command payload %s: %v", b, err)
+	}
+}
+
+func TestHookRunnerTimeout(t *testing.T) {
+	conf := &ffuf.Config{HookConcurrency: 1, HookTimeout: 1}
+	conf.Hooks = []ffuf.HookConfig{{Events: []string{ffuf.HookStopped}, Command: "sleep 30"}}
+	hooks := NewHookRunner(conf)
+	start := time.Now()
+	hooks.Fire(ffuf.HookEvent{Name: ffuf.HookStopped, Message: "Receiving spurious errors, exiting."})
+	hooks.Close()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("the hook was not stopped at its timeout, Close took %s", elapsed)
+	}
+}
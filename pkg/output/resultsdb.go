@@ -0,0 +1,285 @@
+package output
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	runsBucket    = []byte("runs")
+	resultsBucket = []byte("results")
+)
+
+const (
+	// resultsBatch is the number of results written to the database at once
+	resultsBatch = 100
+	// resultsFlushInterval is how long results are held back at most, when
+	// another result comes in
+	resultsFlushInterval = 2 * time.Second
+)
+
+// ResultsDB is the -results-db results store: a bbolt database file holding the
+// results of every run, along with the FFUFHASH history entry of the run. The
+// database is only opened to write to it, so that several ffuf processes can
+// share it and it can be queried while a scan is running. The results are
+// written in batches, of resultsBatch or of those found in resultsFlushInterval,
+// and the rest when the output is finalized with Flush.
+type ResultsDB struct {
+	path    string
+	mu      sync.Mutex
+	pending [][]byte
+	flushed time.Time
+}
+
+// StoredRun is the metadata of a run that results were stored for
+type StoredRun struct {
+	Hash        string    `json:"hash"`
+	Time        time.Time `json:"time"`
+	Url         string    `json:"url"`
+	CommandLine string    `json:"commandline"`
+	// Options is the FFUFHASH history entry of the run, if it could be read
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// StoredResult is a result in a results database, Run is the hash of the run
+// it was found by
+type StoredResult struct {
+	Run    string      `json:"run"`
+	Time   time.Time   `json:"time"`
+	Result ffuf.Result `json:"result"`
+}
+
+// ResultsQuery selects results from a results database. The zero value selects
+// all of them. Host and Path are matched case insensitively, and a * in them
+// matches any characters.
+type ResultsQuery struct {
+	Run     string
+	Host    string
+	Path    string
+	Status  []ffuf.ValueRange
+	Size    []ffuf.ValueRange
+	Scraper *regexp.Regexp
+	Since   time.Time
+	Until   time.Time
+}
+
+func NewResultsDB(path string) *ResultsDB {
+	return &ResultsDB{path: path}
+}
+
+func (db *ResultsDB) update(fn func(tx *bolt.Tx) error) error {
+	bdb, err := bolt.Open(db.path, 0644, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return fmt.Errorf("could not open results database %s: %s", db.path, err)
+	}
+	defer bdb.Close()
+	return bdb.Update(fn)
+}
+
+func (db *ResultsDB) view(fn func(tx *bolt.Tx) error) error {
+	if _, err := os.Stat(db.path); err != nil {
+		return fmt.Errorf("could not open results database: %s", err)
+	}
+	bdb, err := bolt.Open(db.path, 0644, &bolt.Options{Timeout: 10 * time.Second, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("could not open results database %s: %s", db.path, err)
+	}
+	defer bdb.Close()
+	return bdb.View(fn)
+}
+
+// AddRun stores the metadata of a run, reading its options from the FFUFHASH
+// history entry.
+func (db *ResultsDB) AddRun(hash string, config *ffuf.Config) error {
+	run := StoredRun{
+		Hash:        hash,
+		Time:        time.Now(),
+		Url:         config.Url,
+		CommandLine: config.CommandLine,
+	}
+	if hash != "" {
+		if options, err := os.ReadFile(filepath.Join(ffuf.HISTORYDIR, hash, "options")); err == nil && json.Valid(options) {
+			run.Options = options
+		}
+	}
+	value, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return db.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(runsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(hash), value)
+	})
+}
+
+// AddResult stores a result of the run with the hash, with the next batch of
+// results
+func (db *ResultsDB) AddResult(hash string, res ffuf.Result) error {
+	value, err := json.Marshal(StoredResult{Run: hash, Time: time.Now(), Result: res})
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.pending = append(db.pending, value)
+	if len(db.pending) < resultsBatch && time.Since(db.flushed) < resultsFlushInterval {
+		return nil
+	}
+	return db.flush()
+}
+
+// Flush writes the results that are held back to the database
+func (db *ResultsDB) Flush() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.flush()
+}
+
+func (db *ResultsDB) flush() error {
+	if len(db.pending) == 0 {
+		return nil
+	}
+	// The results are dropped if they can not be written, rather than held back
+	// for the next batch without a bound
+	pending := db.pending
+	db.pending = nil
+	db.flushed = time.Now()
+	return db.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(resultsBucket)
+		if err != nil {
+			return err
+		}
+		for _, value := range pending {
+			// The keys are a sequence, keeping the results in the order they were found
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			key := make([]byte, 8)
+			binary.BigEndian.PutUint64(key, seq)
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Runs returns the metadata of the runs in the database by their hash
+func (db *ResultsDB) Runs() (map[string]StoredRun, error) {
+	runs := make(map[string]StoredRun)
+	err := db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(runsBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var run StoredRun
+			if err := json.Unmarshal(v, &run); err != nil {
+				return fmt.Errorf("bad run %s in results database: %s", k, err)
+			}
+			runs[run.Hash] = run
+			return nil
+		})
+	})
+	return runs, err
+}
+
+// Query calls handle for every result the query selects, in the order they
+// were stored.
+func (db *ResultsDB) Query(q ResultsQuery, handle func(StoredResult) error) error {
+	host, path := wildcardRegexp(q.Host), wildcardRegexp(q.Path)
+	return db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(resultsBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var res StoredResult
+			if err := json.Unmarshal(v, &res); err != nil {
+				return fmt.Errorf("bad result %d in results database: %s", binary.BigEndian.Uint64(k), err)
+			}
+			if q.matches(res, host, path) {
+				return handle(res)
+			}
+			return nil
+		})
+	})
+}
+
+func (q ResultsQuery) matches(res StoredResult, host, path *regexp.Regexp) bool {
+	if !strings.HasPrefix(res.Run, q.Run) {
+		return false
+	}
+	if (!q.Since.IsZero() && res.Time.Before(q.Since)) || (!q.Until.IsZero() && res.Time.After(q.Until)) {
+		return false
+	}
+	if !inRanges(res.Result.StatusCode, q.Status) || !inRanges(res.Result.ContentLength, q.Size) {
+		return false
+	}
+	if host != nil || path != nil {
+		u, err := url.Parse(res.Result.Url)
+		if err != nil {
+			return false
+		}
+		if host != nil && !host.MatchString(u.Hostname()) && !host.MatchString(res.Result.Host) {
+			return false
+		}
+		if path != nil && !path.MatchString(u.Path) {
+			return false
+		}
+	}
+	if q.Scraper != nil {
+		found := false
+		for name, values := range res.Result.ScraperData {
+			for _, value := range values {
+				found = found || q.Scraper.MatchString(name+"="+value)
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func inRanges(value int64, ranges []ffuf.ValueRange) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if value >= r.Min && value <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardRegexp returns a case insensitive regexp matching the whole of a
+// string against a pattern where * matches any characters, or nil for an empty
+// pattern
+func wildcardRegexp(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("(?i)^" + strings.Join(parts, ".*") + "$")
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func TestResultsDB(t *testing.T) {
+	db := NewResultsDB(filepath.Join(t.TempDir(), "results.db"))
+	conf := ffuf.Config{Url: "https://example.org/FUZZ", CommandLine: "ffuf -u https://example.org/FUZZ"}
+	if err := db.AddRun("abcdef0123", &conf); err != nil {
+		t.Fatalf("AddRun: %s", err)
+	}
+	results := []ffuf.Result{
+		{Url: "https://example.org/admin", StatusCode: 200, ContentLength: 100, Input: map[string][]byte{"FUZZ": []byte("admin")}},
+		{Url: "https://api.example.org/v1/users", StatusCode: 403, ContentLength: 20},
+		{Url: "https://example.com/backup.zip", StatusCode: 200, ContentLength: 5000, ScraperData: map[string][]string{"aws": {"AKIAEXAMPLE"}}},
+	}
+	for i, res := range results {
+		run := "abcdef0123"
+		if i == 2 {
+			run = "123456789a"
+		}
+		if err := db.AddResult(run, res); err != nil {
+			t.Fatalf("AddResult: %s", err)
+		}
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush: %s", err)
+	}
+
+	query := func(q ResultsQuery) []string {
+		t.Helper()
+		var urls []string
+		if err := db.Query(q, func(res StoredResult) error {
+			urls = append(urls, res.Result.Url)
+			return nil
+		}); err != nil {
+			t.Fatalf("Query: %s", err)
+		}
+		return urls
+	}
+	tests := []struct {
+		name  string
+		query ResultsQuery
+		want  int
+	}{
+		{"all", ResultsQuery{}, 3},
+		{"run", ResultsQuery{Run: "abc"}, 2},
+		{"host", ResultsQuery{Host: "*.EXAMPLE.org"}, 1},
+		{"exact host", ResultsQuery{Host: "example.org"}, 1},
+		{"path", ResultsQuery{Path: "/v1/*"}, 1},
+		{"status", ResultsQuery{Status: []ffuf.ValueRange{{Min: 200, Max: 299}}}, 2},
+		{"size", ResultsQuery{Size: []ffuf.ValueRange{{Min: 50, Max: 1000}}}, 1},
+		{"scraper", ResultsQuery{Scraper: regexp.MustCompile("^aws=AKIA")}, 1},
+		{"since", ResultsQuery{Since: time.Now().Add(time.Hour)}, 0},
+		{"until", ResultsQuery{Until: time.Now().Add(time.Hour)}, 3},
+		{"combined", ResultsQuery{Host: "example.org", Status: []ffuf.ValueRange{{Min: 403, Max: 403}}}, 0},
+	}
+	for _, tc := range tests {
+		if got := query(tc.query); len(got) != tc.want {
+			t.Errorf("%s: got %v, want %d results", tc.name, got, tc.want)
+		}
+	}
+	if got := query(ResultsQuery{}); got[0] != results[0].Url || got[2] != results[2].Url {
+		t.Errorf("Expected the results in the order they were stored, got %v", got)
+	}
+
+	runs, err := db.Runs()
+	if err != nil {
+		t.Fatalf("Runs: %s", err)
+	}
+	if run, ok := runs["abcdef0123"]; !ok || run.CommandLine != conf.CommandLine || run.Url != conf.Url {
+		t.Errorf("Unexpected runs %+v", runs)
+	}
+
+	if err := NewResultsDB(filepath.Join(t.TempDir(), "missing.db")).Query(ResultsQuery{}, func(StoredResult) error { return nil }); err == nil {
+		t.Errorf("Expected an error querying a missing database")
+	}
+}
+
+func TestResultsDBBatches(t *testing.T) {
+	db := NewResultsDB(filepath.Join(t.TempDir(), "results.db"))
+	count := func() int {
+		t.Helper()
+		n := 0
+		if err := db.Query(ResultsQuery{}, func(StoredResult) error { n++; return nil }); err != nil {
+			t.Fatalf("Query: %s", err)
+		}
+		return n
+	}
+	for i := 0; i < 3; i++ {
+		if err := db.AddResult("abcdef0123", ffuf.Result{Url: "https://example.org/"}); err != nil {
+			t.Fatalf("AddResult: %s", err)
+		}
+	}
+	// The first result is written right away, the next ones with the batch
+	if n := count(); n != 1 {
+		t.Errorf("got %d results before the batch was written, want 1", n)
+	}
+	for i := 0; i < resultsBatch; i++ {
+		if err := db.AddResult("abcdef0123", ffuf.Result{Url: "https://example.org/"}); err != nil {
+			t.Fatalf("AddResult: %s", err)
+		}
+	}
+	if n := count(); n != resultsBatch+1 {
+		t.Errorf("got %d results after a full batch, want %d", n, resultsBatch+1)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush: %s", err)
+	}
+	if n := count(); n != resultsBatch+3 {
+		t.Errorf("got %d results after Flush, want all %d", n, resultsBatch+3)
+	}
+}
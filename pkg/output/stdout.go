@@ -4,6 +4,7 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path"
 	"sort"
@@ -36,6 +37,10 @@ type Stdoutput struct {
 	stdoutIsTerminal bool
 	stderrIsTerminal bool
 	paused           bool // when set, Result records matches but does not print them
+	resultsDB        *ResultsDB
+	run              string // hash of the history entry of the current job, guarded by resultMutex
+	differ           *ResultDiffer
+	observe          func(ffuf.Result) // set with SetResultObserver, guarded by resultMutex
 }
 
 func NewStdoutput(conf *ffuf.Config) *Stdoutput {
@@ -50,6 +55,9 @@ func NewStdoutput(conf *ffuf.Config) *Stdoutput {
 		outp.fuzzkeywords = append(outp.fuzzkeywords, ip.Keyword)
 	}
 	sort.Strings(outp.fuzzkeywords)
+	if conf.ResultsDB != "" {
+		outp.resultsDB = NewResultsDB(conf.ResultsDB)
+	}
 	return &outp
 }
 
@@ -142,12 +150,43 @@ func (s *Stdoutput) Banner() {
 
 		if s.config.OutputFormat == "all" {
 			// Actually... append all extensions
-			OutputFile += ".{json,ejson,html,md,csv,ecsv}"
+			OutputFile += ".{json,ejson,html,md,csv,ecsv,har}"
 		}
 
 		printOption([]byte("Output file"), []byte(OutputFile))
 		printOption([]byte("File format"), []byte(s.config.OutputFormat))
 	}
+	if len(s.config.OutputRequestDir) > 0 {
+		printOption([]byte("Request files"), []byte(s.config.OutputRequestDir))
+	}
+	if len(s.config.ResultsDB) > 0 {
+		printOption([]byte("Results database"), []byte(s.config.ResultsDB))
+	}
+	if len(s.config.Baseline) > 0 {
+		printOption([]byte("Baseline"), []byte(s.config.Baseline))
+	}
+	if s.config.Cluster {
+		printOption([]byte("Cluster results"), []byte("true"))
+	}
+	if len(s.config.Api) > 0 {
+		printOption([]byte("Control API"), []byte("http://"+s.config.Api))
+	}
+	if len(s.config.Metrics) > 0 {
+		printOption([]byte("Metrics"), []byte("http://"+s.config.Metrics+"/metrics"))
+	}
+	for _, hook := range s.config.Hooks {
+		target := hook.Command
+		if hook.Url != "" {
+			target = hook.Url
+		}
+		printOption([]byte("Hook"), []byte(strings.Join(hook.Events, ",")+": "+target))
+	}
+	if s.config.Script != "" {
+		printOption([]byte("Script"), []byte(s.config.Script))
+	}
+	for _, plugin := range s.config.Plugins {
+		printOption([]byte("Plugin"), []byte(plugin.Name+" ("+strings.Join(plugin.Roles, ",")+"): "+plugin.Command))
+	}
 
 	// Follow redirects?
 	follow := fmt.Sprintf("%t", s.config.FollowRedirects)
@@ -157,6 +196,29 @@ func (s *Stdoutput) Banner() {
 	autocalib := fmt.Sprintf("%t", s.config.AutoCalibration)
 	printOption([]byte("Calibration"), []byte(autocalib))
 
+	// Connection overrides
+	hostports := make([]string, 0, len(s.config.Resolve))
+	for hostport := range s.config.Resolve {
+		hostports = append(hostports, hostport)
+	}
+	sort.Strings(hostports)
+	for _, hostport := range hostports {
+		printOption([]byte("Resolve"), []byte(fmt.Sprintf("%s -> %s", hostport, s.config.Resolve[hostport])))
+	}
+	if len(s.config.DNSResolver) > 0 {
+		printOption([]byte("DNS resolver"), []byte(s.config.DNSResolver))
+	}
+	if len(s.config.UnixSocket) > 0 {
+		printOption([]byte("Unix socket"), []byte(s.config.UnixSocket))
+	}
+	if s.config.WebSocket {
+		wsReplies := fmt.Sprintf("%d ms", s.config.WSTimeout)
+		if s.config.WSFrames > 0 {
+			wsReplies = fmt.Sprintf("%s or %d messages", wsReplies, s.config.WSFrames)
+		}
+		printOption([]byte("WebSocket replies"), []byte(wsReplies))
+	}
+
 	// Proxies
 	if len(s.config.ProxyURL) > 0 {
 		printOption([]byte("Proxy"), []byte(s.config.ProxyURL))
@@ -349,20 +411,41 @@ func (s *Stdoutput) writeToAll(filename string, config *ffuf.Config, res []ffuf.
 		s.Error(err.Error())
 	}
 
+	s.config.OutputFile = BaseFilename + ".har"
+	err = writeHAR(s.config.OutputFile, s.config, res)
+	if err != nil {
+		s.Error(err.Error())
+	}
+
 	return nil
 
 }
 
-// SaveFile saves the current results to a file of a given type
-func (s *Stdoutput) SaveFile(filename, format string) error {
-	var err error
-	// Snapshot the results under the lock so a concurrent Result() cannot race the
-	// file writers, then write outside the lock.
+// AllResults returns a snapshot of the results of all the jobs
+func (s *Stdoutput) AllResults() []ffuf.Result {
 	s.resultMutex.Lock()
+	defer s.resultMutex.Unlock()
 	all := make([]ffuf.Result, 0, len(s.Results)+len(s.CurrentResults))
 	all = append(all, s.Results...)
 	all = append(all, s.CurrentResults...)
-	s.resultMutex.Unlock()
+	return all
+}
+
+// ValidFormat reports whether format is one of the -of output file formats
+func ValidFormat(format string) bool {
+	for _, f := range []string{"all", "json", "ejson", "html", "md", "csv", "ecsv", "har"} {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// SaveFile saves the current results to a file of a given type
+func (s *Stdoutput) SaveFile(filename, format string) error {
+	var err error
+	// Snapshot the results so a concurrent Result() cannot race the file writers
+	all := s.AllResults()
 
 	if s.config.OutputSkipEmptyFile && len(all) == 0 {
 		s.Info("No results and -or defined, output file not written.")
@@ -383,13 +466,57 @@ func (s *Stdoutput) SaveFile(filename, format string) error {
 		err = writeCSV(filename, s.config, all, false)
 	case "ecsv":
 		err = writeCSV(filename, s.config, all, true)
+	case "har":
+		err = writeHAR(filename, s.config, all)
 	}
 	return err
 }
 
+// LoadBaseline reads the results of an earlier run from a JSON output file, to
+// only output the results that are new or changed compared to them, and the
+// ones that are gone when finalizing.
+func (s *Stdoutput) LoadBaseline(filename string) error {
+	baseline, err := ReadResults(filename)
+	if err != nil {
+		return fmt.Errorf("could not read the -baseline: %s", err)
+	}
+	s.differ = NewResultDiffer(baseline)
+	return nil
+}
+
+// printClusters prints the representative of every -cluster of results, with
+// the number of results in it
+func (s *Stdoutput) printClusters() {
+	all := s.AllResults()
+	for _, cluster := range ClusterResults(all) {
+		res := all[cluster[0]]
+		res.ClusterSize = len(cluster)
+		s.PrintResult(res)
+	}
+}
+
 // Finalize gets run after all the ffuf jobs are completed
 func (s *Stdoutput) Finalize() error {
 	var err error
+	if s.differ != nil {
+		for _, res := range s.differ.Gone() {
+			res.Printed = true
+			s.resultMutex.Lock()
+			s.CurrentResults = append(s.CurrentResults, res)
+			s.resultMutex.Unlock()
+			if !s.config.Cluster {
+				s.PrintResult(res)
+			}
+		}
+	}
+	if s.config.Cluster {
+		s.printClusters()
+	}
+	if s.resultsDB != nil {
+		if err := s.resultsDB.Flush(); err != nil {
+			s.Error(err.Error())
+		}
+	}
 	if s.config.OutputFile != "" {
 		err = s.SaveFile(s.config.OutputFile, s.config.OutputFormat)
 		if err != nil {
@@ -402,17 +529,13 @@ func (s *Stdoutput) Finalize() error {
 	return nil
 }
 
-func (s *Stdoutput) Result(resp ffuf.Response) {
-	// Do we want to write request and response to a file
-	if len(s.config.OutputDirectory) > 0 {
-		resp.ResultFile = s.writeResultToFile(resp)
-	}
-
+// NewResult returns the result of a matched response
+func NewResult(resp ffuf.Response) ffuf.Result {
 	inputs := make(map[string][]byte, len(resp.Request.Input))
 	for k, v := range resp.Request.Input {
 		inputs[k] = v
 	}
-	sResult := ffuf.Result{
+	return ffuf.Result{
 		Input:            inputs,
 		Position:         resp.Request.Position,
 		StatusCode:       resp.StatusCode,
@@ -426,6 +549,40 @@ func (s *Stdoutput) Result(resp ffuf.Response) {
 		Duration:         resp.Duration,
 		ResultFile:       resp.ResultFile,
 		Host:             resp.Request.Host,
+		RemoteAddr:       resp.RemoteAddr,
+	}
+}
+
+func (s *Stdoutput) Result(resp ffuf.Response) {
+	// Do we want to write request and response to a file
+	if len(s.config.OutputDirectory) > 0 {
+		resp.ResultFile = s.writeResultToFile(resp)
+	}
+	if len(s.config.OutputRequestDir) > 0 {
+		s.writeRequestToFile(resp)
+	}
+
+	sResult := NewResult(resp)
+	if s.config.Cluster {
+		sResult.Simhash = Simhash(resp.Data)
+	}
+	if s.config.OutputFile != "" && (s.config.OutputFormat == "har" || s.config.OutputFormat == "all") {
+		sResult.Response = harExchange(resp)
+	}
+	s.resultMutex.Lock()
+	run := s.run
+	s.resultMutex.Unlock()
+	if s.resultsDB != nil {
+		if err := s.resultsDB.AddResult(run, sResult); err != nil {
+			s.Error(err.Error())
+		}
+	}
+	// With a -baseline only the differences to it are output
+	if s.differ != nil {
+		var changed bool
+		if sResult, changed = s.differ.Compare(sResult); !changed {
+			return
+		}
 	}
 	s.resultMutex.Lock()
 	paused := s.paused
@@ -435,16 +592,41 @@ func (s *Stdoutput) Result(resp ffuf.Response) {
 	// the pending count - the count can never contradict what "show" displays.
 	sResult.Printed = !paused
 	s.CurrentResults = append(s.CurrentResults, sResult)
+	observe := s.observe
 	s.resultMutex.Unlock()
+	if observe != nil {
+		observe(sResult)
+	}
 	// While the interactive console is open we still record the match above (so
 	// show, savejson and -o output stay complete) but do not stream it to the
 	// terminal - otherwise inflight requests completing after the user opens the
-	// console would scroll the banner off screen.
-	if !paused {
+	// console would scroll the banner off screen. With -cluster the results are
+	// printed by cluster when finalizing.
+	if !paused && !s.config.Cluster {
 		s.PrintResult(sResult)
 	}
 }
 
+// SetRun records the hash of the FFUFHASH history entry of the job that is
+// starting, and stores its metadata in the -results-db.
+func (s *Stdoutput) SetRun(hash string) {
+	s.resultMutex.Lock()
+	s.run = hash
+	s.resultMutex.Unlock()
+	if s.resultsDB != nil {
+		if err := s.resultsDB.AddRun(hash, s.config); err != nil {
+			s.Error(err.Error())
+		}
+	}
+}
+
+// SetResultObserver sets a function to call with every result Result records
+func (s *Stdoutput) SetResultObserver(observe func(ffuf.Result)) {
+	s.resultMutex.Lock()
+	s.observe = observe
+	s.resultMutex.Unlock()
+}
+
 // SetPaused toggles whether Result streams matches to the live terminal. While
 // paused (the interactive console is open) matches are recorded but not printed,
 // so inflight requests completing cannot scroll the console off screen. Leaving
@@ -478,6 +660,9 @@ func (s *Stdoutput) PendingResults() int {
 	return n
 }
 
+// resultFileSeparator separates the request from the response in the -od files
+const resultFileSeparator = "\n---- ↑ Request ---- Response ↓ ----\n\n"
+
 func (s *Stdoutput) writeResultToFile(resp ffuf.Response) string {
 	var fileContent, fileName, filePath string
 	// Create directory if needed
@@ -490,7 +675,7 @@ func (s *Stdoutput) writeResultToFile(resp ffuf.Response) string {
 			}
 		}
 	}
-	fileContent = fmt.Sprintf("%s\n---- ↑ Request ---- Response ↓ ----\n\n%s", resp.Request.Raw, resp.Raw)
+	fileContent = resp.Request.Raw + resultFileSeparator + resp.Raw
 
 	// Create file name
 	fileName = fmt.Sprintf("%x", md5.Sum([]byte(fileContent)))
@@ -503,6 +688,53 @@ func (s *Stdoutput) writeResultToFile(resp ffuf.Response) string {
 	return fileName
 }
 
+// writeRequestToFile writes the request of a match to -odr as a raw request
+// file that -request reads back: the absolute URL in the request line, so the
+// scheme and port need no -request-proto, the headers and the body. The Host
+// header is kept only if it is not the host of the URL, as when fuzzing
+// virtual hosts.
+func (s *Stdoutput) writeRequestToFile(resp ffuf.Response) {
+	err := os.MkdirAll(s.config.OutputRequestDir, 0750)
+	if err != nil && !os.IsExist(err) {
+		s.Error(err.Error())
+		return
+	}
+	req := resp.Request
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\n", req.Method, req.Url)
+	urlHost := ""
+	if u, err := url.Parse(req.Url); err == nil {
+		urlHost = u.Host
+	}
+	names := make([]string, 0, len(req.Headers))
+	for name, value := range req.Headers {
+		// The length is recalculated
+		if strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		if strings.EqualFold(name, "Host") && strings.EqualFold(value, urlHost) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\r\n", name, req.Headers[name])
+	}
+	b.WriteString("\r\n")
+	b.Write(req.Data)
+	// -request removes a single trailing newline, which may be part of the body
+	if len(req.Data) > 0 && !strings.HasSuffix(b.String(), "\r") {
+		b.WriteString("\n")
+	}
+
+	fileName := fmt.Sprintf("%x.txt", md5.Sum([]byte(b.String())))
+	err = os.WriteFile(path.Join(s.config.OutputRequestDir, fileName), []byte(b.String()), 0640)
+	if err != nil {
+		s.Error(err.Error())
+	}
+}
+
 func (s *Stdoutput) PrintResult(res ffuf.Result) {
 	switch {
 	case s.config.Json:
@@ -542,13 +774,47 @@ func (s *Stdoutput) prepareInputsOneLine(res ffuf.Result) string {
 }
 
 func (s *Stdoutput) resultQuiet(res ffuf.Result) {
-	fmt.Println(s.prepareInputsOneLine(res))
+	prefix := ""
+	if res.Diff != nil {
+		prefix = map[string]string{"new": "+ ", "gone": "- ", "changed": "~ "}[res.Diff.Change]
+	}
+	fmt.Println(prefix + s.prepareInputsOneLine(res))
+}
+
+// diffSummary describes how a result differs from the -baseline
+func diffSummary(res ffuf.Result) string {
+	if res.Diff == nil {
+		return ""
+	}
+	if res.Diff.Change != "changed" {
+		return fmt.Sprintf(" [%s]", res.Diff.Change)
+	}
+	changes := make([]string, 0)
+	if res.Diff.BaselineStatusCode != res.StatusCode {
+		changes = append(changes, fmt.Sprintf("Status: %d -> %d", res.Diff.BaselineStatusCode, res.StatusCode))
+	}
+	if delta := res.ContentLength - res.Diff.BaselineContentLength; delta != 0 {
+		changes = append(changes, fmt.Sprintf("Size: %+d", delta))
+	}
+	if delta := res.ContentWords - res.Diff.BaselineContentWords; delta != 0 {
+		changes = append(changes, fmt.Sprintf("Words: %+d", delta))
+	}
+	return fmt.Sprintf(" [changed: %s]", strings.Join(changes, ", "))
+}
+
+// clusterSummary tells the number of results in the -cluster of a result, if
+// there are others than it
+func clusterSummary(res ffuf.Result) string {
+	if res.ClusterSize < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" [Cluster: %d results]", res.ClusterSize)
 }
 
 func (s *Stdoutput) resultMultiline(res ffuf.Result) {
 	var res_hdr, res_str string
 	res_str = "%s%s    * %s: %s\n"
-	res_hdr = fmt.Sprintf("%s%s[Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms]%s", s.stdoutClear(), s.colorize(res.StatusCode), res.StatusCode, res.ContentLength, res.ContentWords, res.ContentLines, res.Duration.Milliseconds(), s.ansiClear())
+	res_hdr = fmt.Sprintf("%s%s[Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms]%s%s%s", s.stdoutClear(), s.colorize(res.StatusCode), res.StatusCode, res.ContentLength, res.ContentWords, res.ContentLines, res.Duration.Milliseconds(), diffSummary(res), clusterSummary(res), s.ansiClear())
 	reslines := ""
 	if s.config.Verbose {
 		reslines = fmt.Sprintf("%s%s| URL | %s\n", reslines, s.stdoutClear(), res.Url)
@@ -581,7 +847,7 @@ func (s *Stdoutput) resultMultiline(res ffuf.Result) {
 }
 
 func (s *Stdoutput) resultNormal(res ffuf.Result) {
-	resnormal := fmt.Sprintf("%s%s%-23s [Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms]%s", s.stdoutClear(), s.colorize(res.StatusCode), s.prepareInputsOneLine(res), res.StatusCode, res.ContentLength, res.ContentWords, res.ContentLines, res.Duration.Milliseconds(), s.ansiClear())
+	resnormal := fmt.Sprintf("%s%s%-23s [Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms]%s%s%s", s.stdoutClear(), s.colorize(res.StatusCode), s.prepareInputsOneLine(res), res.StatusCode, res.ContentLength, res.ContentWords, res.ContentLines, res.Duration.Milliseconds(), diffSummary(res), clusterSummary(res), s.ansiClear())
 	fmt.Println(resnormal)
 }
 
@@ -2,13 +2,16 @@ package output
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
 )
 
 // captureStdout redirects os.Stdout to an in-memory pipe for the duration of
@@ -153,3 +156,82 @@ func TestResultOutputKeepsColorResetWhenColorsAreEnabled(t *testing.T) {
 		t.Errorf("result line printed with -c is missing its ANSI reset code: %q", out)
 	}
 }
+
+func TestBannerSortsResolveOverrides(t *testing.T) {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.MatcherManager = filter.NewMatcherManager()
+	conf.Resolve = map[string]string{
+		"d.example:443": "10.0.0.4",
+		"a.example:443": "10.0.0.1",
+		"c.example:80":  "10.0.0.3",
+		"b.example:*":   "10.0.0.2",
+	}
+	out := captureStderr(t, func() { NewStdoutput(&conf).Banner() })
+	var got []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "Resolve") {
+			got = append(got, strings.TrimSpace(line[strings.Index(line, " : ")+3:]))
+		}
+	}
+	want := "a.example:443 -> 10.0.0.1,b.example:* -> 10.0.0.2,c.example:80 -> 10.0.0.3,d.example:443 -> 10.0.0.4"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got the Resolve lines %v, want them sorted", got)
+	}
+}
+
+func TestResultKeepsHARBodyUpToLimit(t *testing.T) {
+	conf := &ffuf.Config{OutputFile: "out.har", OutputFormat: "har"}
+	outp := NewStdoutput(conf)
+	body := bytes.Repeat([]byte("a"), harBodyLimit+10)
+	resp := ffuf.Response{
+		StatusCode:    200,
+		ContentLength: int64(len(body)),
+		Data:          body,
+		Request:       &ffuf.Request{Method: "GET", Url: "http://x/big"},
+	}
+	captureStdout(t, func() { outp.Result(resp) })
+
+	if len(outp.CurrentResults) != 1 {
+		t.Fatalf("expected one result, got %d", len(outp.CurrentResults))
+	}
+	res := outp.CurrentResults[0]
+	if len(res.Response.Data) != harBodyLimit || cap(res.Response.Data) > harBodyLimit {
+		t.Errorf("kept %d bytes of the body for the HAR output, want %d", cap(res.Response.Data), harBodyLimit)
+	}
+	content := harResultEntry(res).Response.Content
+	if content.Size != int64(len(body)) || content.Comment == "" {
+		t.Errorf("the HAR content of a truncated body is %d bytes, comment %q", content.Size, content.Comment)
+	}
+}
+
+func TestRequestFileKeepsVirtualHost(t *testing.T) {
+	dir := t.TempDir()
+	conf := &ffuf.Config{OutputRequestDir: dir}
+	outp := NewStdoutput(conf)
+	resp := ffuf.Response{
+		StatusCode: 200,
+		Request: &ffuf.Request{
+			Method:  "GET",
+			Url:     "http://10.0.0.1:8080/",
+			Headers: map[string]string{"Host": "admin.example.org", "X-Test": "yes"},
+		},
+	}
+	captureStdout(t, func() { outp.Result(resp) })
+
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one request file, got %v %v", files, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs, err := ffuf.ImportRequests(data, ffuf.RequestFormatRaw, "https")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	req := reqs[0]
+	if req.Url != "http://10.0.0.1:8080/" || req.Headers["Host"] != "admin.example.org" || req.Headers["X-Test"] != "yes" {
+		t.Errorf("the request read back is %+v", req)
+	}
+}
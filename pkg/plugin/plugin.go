@@ -0,0 +1,269 @@
+// Package plugin runs the out-of-process plugins declared in the config file:
+//
+//	[[general.plugins]]
+//	name = "waf"
+//	command = "/usr/local/bin/ffuf-waf-filter"
+//	args = ["--strict"]
+//	roles = ["filter"]
+//	instances = 4
+//	timeout = 10
+//
+// A plugin is an executable that reads messages from its stdin and writes a
+// reply to each of them to its stdout, one JSON object per line. Its instances
+// are started once for the scan and each of them gets one message at a time,
+// so the requests of the scan are handled by up to instances of them at once.
+// An instance that does not reply within timeout seconds (10 by default) is
+// killed, and an instance that failed is replaced by a new one before it would
+// get the next message. An instance is stopped by closing its stdin. What a
+// plugin writes to its stderr is passed through. Bodies are base64 encoded.
+//
+// The messages and their replies are:
+//
+//	{"type":"init","name":"waf","roles":["filter"],"version":"2.1.0"}
+//	    Sent to every instance as it starts, the reply is {}.
+//	{"type":"filter","response":RESPONSE}
+//	{"type":"matcher","response":RESPONSE}
+//	    The reply is {"result":true} to filter out, or to match, the response.
+//	{"type":"mutate","request":REQUEST}
+//	    The reply is {"request":REQUEST}, the request to send instead.
+//	{"type":"input","keyword":"FUZZ"}
+//	    Sent to one instance, the reply is {"values":["admin","login",...]}
+//	    to fuzz the keyword with.
+//
+// Any reply can instead be {"error":"message"}. A REQUEST is an object with
+// method, url, headers (name to value), body and input (keyword to value), a
+// RESPONSE one with status, headers (name to values), body, length, words,
+// lines, content_type, duration (in nanoseconds) and request.
+//
+// A filter or matcher plugin is listed among the filters or matchers as
+// "plugin:NAME", and is combined with them according to -fmode or -mmode.
+// Mutators are run in the order they are declared, input plugins add the
+// keyword they fuzz like a wordlist does.
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// defaultTimeout is how long an instance has to reply to a message if the
+// plugin has no timeout
+const defaultTimeout = 10 * time.Second
+
+// closeTimeout is how long the instances of a plugin have to exit after their
+// stdin is closed, before they are killed
+const closeTimeout = 2 * time.Second
+
+// Plugin is a running plugin and its instances
+type Plugin struct {
+	Config    ffuf.PluginConfig
+	mu        sync.Mutex
+	instances []*instance
+	pool      chan *instance
+}
+
+type instance struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	// err is the error the instance failed with, it is not used after one
+	err error
+}
+
+// Start starts the instances of a plugin
+func Start(conf ffuf.PluginConfig) (*Plugin, error) {
+	p := &Plugin{Config: conf}
+	count := conf.Instances
+	if len(conf.Roles) == 1 && conf.HasRole(ffuf.PluginInput) {
+		count = 1
+	}
+	p.pool = make(chan *instance, count)
+	for i := 0; i < count; i++ {
+		inst, err := p.startInstance()
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.instances = append(p.instances, inst)
+		p.pool <- inst
+	}
+	return p, nil
+}
+
+func (p *Plugin) startInstance() (*instance, error) {
+	cmd := exec.Command(p.Config.Command, p.Config.Args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start plugin %s: %s", p.Config.Name, err)
+	}
+	inst := &instance{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}
+	init := message{Type: "init", Name: p.Config.Name, Roles: p.Config.Roles, Version: ffuf.Version()}
+	if _, err = inst.call(init, p.timeout()); err != nil {
+		p.closeInstance(inst)
+		return nil, fmt.Errorf("plugin %s: %s", p.Config.Name, err)
+	}
+	return inst, nil
+}
+
+// call sends a message to a free instance of the plugin and returns its reply
+func (p *Plugin) call(msg message) (reply, error) {
+	inst := <-p.pool
+	defer func() { p.pool <- inst }()
+	if inst.err != nil {
+		replacement, err := p.replaceInstance(inst)
+		if err != nil {
+			return reply{}, err
+		}
+		inst = replacement
+	}
+	r, err := inst.call(msg, p.timeout())
+	if err != nil {
+		return r, fmt.Errorf("plugin %s: %s", p.Config.Name, err)
+	}
+	return r, nil
+}
+
+// replaceInstance stops an instance that failed and starts a new one in its
+// place. If the new one does not start, the failed one is kept to be replaced
+// on its next use.
+func (p *Plugin) replaceInstance(failed *instance) (*instance, error) {
+	p.closeInstance(failed)
+	inst, err := p.startInstance()
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.instances {
+		if p.instances[i] == failed {
+			p.instances[i] = inst
+		}
+	}
+	return inst, nil
+}
+
+func (p *Plugin) timeout() time.Duration {
+	if p.Config.Timeout == 0 {
+		return defaultTimeout
+	}
+	return time.Duration(p.Config.Timeout) * time.Second
+}
+
+func (inst *instance) call(msg message, timeout time.Duration) (reply, error) {
+	var r reply
+	if inst.err != nil {
+		return r, inst.err
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return r, err
+	}
+	type result struct {
+		line []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		if _, err := inst.stdin.Write(append(data, '\n')); err != nil {
+			done <- result{err: fmt.Errorf("instance is not running: %s", err)}
+			return
+		}
+		line, err := inst.stdout.ReadBytes('\n')
+		if err != nil {
+			err = fmt.Errorf("instance exited without a reply to %s", msg.Type)
+		}
+		done <- result{line, err}
+	}()
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(timeout):
+		// Killing the instance ends the write or the read it is stuck in
+		_ = inst.cmd.Process.Kill()
+		inst.err = fmt.Errorf("instance did not reply to %s within %s and was killed", msg.Type, timeout)
+		return r, inst.err
+	}
+	if res.err != nil {
+		inst.err = res.err
+		return r, inst.err
+	}
+	if err = json.Unmarshal(res.line, &r); err != nil {
+		inst.err = fmt.Errorf("invalid reply to %s: %s", msg.Type, strings.TrimSpace(string(res.line)))
+		return r, inst.err
+	}
+	if r.Error != "" {
+		return r, fmt.Errorf("%s", r.Error)
+	}
+	return r, nil
+}
+
+// Values asks an input plugin for the values to fuzz its keyword with
+func (p *Plugin) Values() ([]string, error) {
+	r, err := p.call(message{Type: "input", Keyword: p.Config.Keyword})
+	return r.Values, err
+}
+
+// Mutate lets a mutator plugin modify a prepared request
+func (p *Plugin) Mutate(req *ffuf.Request) error {
+	r, err := p.call(message{Type: "mutate", Request: newRequest(req)})
+	if err != nil {
+		return err
+	}
+	if r.Request == nil {
+		return fmt.Errorf("plugin %s replied without a request", p.Config.Name)
+	}
+	r.Request.apply(req)
+	return nil
+}
+
+// Close stops all the instances of the plugin at once, without waiting for the
+// messages they are handling. An instance that does not exit within
+// closeTimeout is killed.
+func (p *Plugin) Close() {
+	p.mu.Lock()
+	instances := append([]*instance(nil), p.instances...)
+	p.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, inst := range instances {
+		wg.Add(1)
+		go func(inst *instance) {
+			defer wg.Done()
+			p.closeInstance(inst)
+		}(inst)
+	}
+	wg.Wait()
+}
+
+func (p *Plugin) closeInstance(inst *instance) {
+	_ = inst.stdin.Close()
+	exited := make(chan struct{})
+	go func() {
+		_ = inst.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(closeTimeout):
+		log.Printf("Plugin %s did not exit, killing it", p.Config.Name)
+		_ = inst.cmd.Process.Kill()
+		<-exited
+	}
+}
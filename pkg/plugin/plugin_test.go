@@ -0,0 +1,181 @@
+package plugin_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/input"
+	"github.com/ffuf/ffuf/v2/pkg/plugin"
+	"github.com/ffuf/ffuf/v2/pkg/plugin/plugintest"
+)
+
+func TestMain(m *testing.M) {
+	plugintest.Main()
+	os.Exit(m.Run())
+}
+
+// testPlugin declares the plugintest plugin, running in mode
+func testPlugin(t *testing.T, mode string, roles ...string) ffuf.PluginConfig {
+	t.Setenv(plugintest.Env, mode)
+	return ffuf.PluginConfig{Name: "test", Command: os.Args[0], Roles: roles, Keyword: "FUZZ", Instances: 2}
+}
+
+func TestPluginFilterAndMatcher(t *testing.T) {
+	ps, err := plugin.StartAll([]ffuf.PluginConfig{testPlugin(t, "ok", ffuf.PluginFilter, ffuf.PluginMatcher)})
+	if err != nil {
+		t.Fatalf("StartAll: %s", err)
+	}
+	defer ps.Close()
+	conf := &ffuf.Config{MatcherManager: filter.NewMatcherManager()}
+	if err := ps.Setup(conf, nil); err != nil {
+		t.Fatalf("Setup: %s", err)
+	}
+	f, ok := conf.MatcherManager.GetFilters()["plugin:test"]
+	if !ok {
+		t.Fatalf("the filter plugin was not added to the filters")
+	}
+	m, ok := conf.MatcherManager.GetMatchers()["plugin:test"]
+	if !ok {
+		t.Fatalf("the matcher plugin was not added to the matchers")
+	}
+	forbidden := &ffuf.Response{StatusCode: 403, Data: []byte("forbidden"), Request: &ffuf.Request{}}
+	admin := &ffuf.Response{StatusCode: 200, Data: []byte("admin panel")}
+	if res, err := f.Filter(forbidden); err != nil || !res {
+		t.Errorf("the 403 response was not filtered: %v, %v", res, err)
+	}
+	if res, err := f.Filter(admin); err != nil || res {
+		t.Errorf("the 200 response was filtered: %v, %v", res, err)
+	}
+	if res, err := m.Filter(admin); err != nil || !res {
+		t.Errorf("the admin response was not matched: %v, %v", res, err)
+	}
+	if m.Repr() != "Plugin: test" {
+		t.Errorf("unexpected Repr %q", m.Repr())
+	}
+}
+
+func TestPluginMutate(t *testing.T) {
+	ps, err := plugin.StartAll([]ffuf.PluginConfig{testPlugin(t, "ok", ffuf.PluginMutator)})
+	if err != nil {
+		t.Fatalf("StartAll: %s", err)
+	}
+	defer ps.Close()
+	req := ffuf.Request{
+		Method:  "GET",
+		Url:     "http://example.org/status/500",
+		Headers: map[string]string{"Accept": "*/*"},
+		Data:    []byte("a=b"),
+		Input:   map[string][]byte{"FUZZ": []byte("500")},
+	}
+	if err := ps.Mutate(&req); err != nil {
+		t.Fatalf("Mutate: %s", err)
+	}
+	if req.Url != "http://example.org/status/201" || req.Headers["X-Plugin"] != "mutated" || req.Headers["Accept"] != "*/*" {
+		t.Errorf("unexpected mutated request %+v", req)
+	}
+	if string(req.Data) != "a=b" || string(req.Input["FUZZ"]) != "500" {
+		t.Errorf("the body or the inputs of the request changed: %+v", req)
+	}
+}
+
+func TestPluginInput(t *testing.T) {
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = "http://example.org/FUZZ"
+	opts.General.Plugins = []ffuf.PluginConfig{testPlugin(t, "ok", ffuf.PluginInput)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %s", err)
+	}
+	ip, errs := input.NewInputProvider(conf)
+	if errs.ErrorOrNil() != nil {
+		t.Fatalf("NewInputProvider: %s", errs.ErrorOrNil())
+	}
+	if ip.Total() != 0 {
+		t.Errorf("the input of the plugin has values before it runs")
+	}
+	ps, err := plugin.StartAll(conf.Plugins)
+	if err != nil {
+		t.Fatalf("StartAll: %s", err)
+	}
+	defer ps.Close()
+	if err := ps.Setup(conf, ip); err != nil {
+		t.Fatalf("Setup: %s", err)
+	}
+	values := make([]string, 0)
+	for ip.Next() {
+		values = append(values, string(ip.Value()["FUZZ"]))
+	}
+	if strings.Join(values, ",") != strings.Join(plugintest.Values, ",") {
+		t.Errorf("got values %v, want %v", values, plugintest.Values)
+	}
+}
+
+func TestPluginErrors(t *testing.T) {
+	_, err := plugin.StartAll([]ffuf.PluginConfig{testPlugin(t, "fail-init", ffuf.PluginFilter)})
+	if err == nil || !strings.Contains(err.Error(), "refusing to start") {
+		t.Errorf("StartAll returned %v, want the error of init", err)
+	}
+	_, err = plugin.StartAll([]ffuf.PluginConfig{{Name: "missing", Command: "/nonexistent/plugin", Roles: []string{ffuf.PluginFilter}, Instances: 1}})
+	if err == nil {
+		t.Errorf("StartAll did not fail for a missing command")
+	}
+
+	ps, err := plugin.StartAll([]ffuf.PluginConfig{testPlugin(t, "crash", ffuf.PluginMutator)})
+	if err != nil {
+		t.Fatalf("StartAll: %s", err)
+	}
+	defer ps.Close()
+	req := ffuf.Request{Url: "http://example.org/", Headers: map[string]string{}}
+	for i := 0; i < 3; i++ {
+		if err := ps.Mutate(&req); err == nil || !strings.Contains(err.Error(), "plugin test") {
+			t.Errorf("Mutate returned %v, want an error of the exited plugin", err)
+		}
+	}
+}
+
+func TestPluginTimeout(t *testing.T) {
+	conf := testPlugin(t, "hang", ffuf.PluginMutator)
+	conf.Instances = 1
+	conf.Timeout = 1
+	ps, err := plugin.StartAll([]ffuf.PluginConfig{conf})
+	if err != nil {
+		t.Fatalf("StartAll: %s", err)
+	}
+	defer ps.Close()
+	req := ffuf.Request{Url: "http://example.org/", Headers: map[string]string{}}
+	start := time.Now()
+	if err := ps.Mutate(&req); err == nil || !strings.Contains(err.Error(), "did not reply") {
+		t.Errorf("Mutate returned %v, want the error of the timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the hung plugin was not killed after its timeout, took %s", elapsed)
+	}
+}
+
+func TestPluginRestart(t *testing.T) {
+	conf := testPlugin(t, "crash-once:"+filepath.Join(t.TempDir(), "crashed"), ffuf.PluginMutator)
+	conf.Instances = 1
+	ps, err := plugin.StartAll([]ffuf.PluginConfig{conf})
+	if err != nil {
+		t.Fatalf("StartAll: %s", err)
+	}
+	defer ps.Close()
+	req := ffuf.Request{Url: "http://example.org/", Headers: map[string]string{}}
+	if err := ps.Mutate(&req); err == nil {
+		t.Fatalf("Mutate did not fail with the crashing instance")
+	}
+	if err := ps.Mutate(&req); err != nil {
+		t.Fatalf("Mutate returned %v, want the instance to be replaced", err)
+	}
+	if req.Headers["X-Plugin"] != "mutated" {
+		t.Errorf("the replaced instance did not mutate the request: %v", req.Headers)
+	}
+}
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/input"
+)
+
+// Filter is a filter or matcher plugin, as a FilterProvider
+type Filter struct {
+	plugin *Plugin
+	role   string
+}
+
+func (f *Filter) Filter(response *ffuf.Response) (bool, error) {
+	r, err := f.plugin.call(message{Type: f.role, Response: newResponse(response)})
+	if err != nil {
+		log.Printf("%s", err)
+		return false, err
+	}
+	return r.Result, nil
+}
+
+func (f *Filter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.plugin.Config.Name,
+	})
+}
+
+func (f *Filter) Repr() string {
+	return fmt.Sprintf("Plugin: %s", f.plugin.Config.Name)
+}
+
+func (f *Filter) ReprVerbose() string {
+	return fmt.Sprintf("Response is checked by the plugin %s", f.plugin.Config.Name)
+}
+
+// Plugins are the running plugins of a scan
+type Plugins struct {
+	plugins  []*Plugin
+	mutators []*Plugin
+}
+
+// StartAll starts the plugins, in the order they are declared. If one of them
+// fails to start, the ones already started are stopped.
+func StartAll(confs []ffuf.PluginConfig) (*Plugins, error) {
+	ps := &Plugins{}
+	for _, conf := range confs {
+		p, err := Start(conf)
+		if err != nil {
+			ps.Close()
+			return nil, err
+		}
+		ps.plugins = append(ps.plugins, p)
+		if conf.HasRole(ffuf.PluginMutator) {
+			ps.mutators = append(ps.mutators, p)
+		}
+	}
+	return ps, nil
+}
+
+// Setup adds the filter and matcher plugins to the matchers and filters of conf,
+// and sets the values of the input plugins in ip
+func (ps *Plugins) Setup(conf *ffuf.Config, ip ffuf.InputProvider) error {
+	for _, p := range ps.plugins {
+		if p.Config.HasRole(ffuf.PluginFilter) || p.Config.HasRole(ffuf.PluginMatcher) {
+			if conf.MatcherManager == nil {
+				return fmt.Errorf("plugin %s: the filters and matchers are not set up", p.Config.Name)
+			}
+		}
+		if p.Config.HasRole(ffuf.PluginFilter) {
+			conf.MatcherManager.AddFilterProvider("plugin:"+p.Config.Name, &Filter{plugin: p, role: ffuf.PluginFilter})
+		}
+		if p.Config.HasRole(ffuf.PluginMatcher) {
+			conf.MatcherManager.AddMatcherProvider("plugin:"+p.Config.Name, &Filter{plugin: p, role: ffuf.PluginMatcher})
+		}
+		if p.Config.HasRole(ffuf.PluginInput) {
+			values, err := p.Values()
+			if err != nil {
+				return err
+			}
+			if err = input.SetPluginValues(ip, p.Config.Name, values); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Mutate lets the mutator plugins modify a prepared request, in the order they
+// are declared
+func (ps *Plugins) Mutate(req *ffuf.Request) error {
+	for _, p := range ps.mutators {
+		if err := p.Mutate(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the plugins
+func (ps *Plugins) Close() {
+	for _, p := range ps.plugins {
+		p.Close()
+	}
+}
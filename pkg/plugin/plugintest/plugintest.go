@@ -0,0 +1,102 @@
+// Package plugintest is a plugin for testing the plugin protocol without
+// building one. A test binary calls Main first in its TestMain, and a test
+// declares a plugin running the test binary itself with Env set: the test
+// binary then acts as the plugin instead of running the tests.
+//
+// The plugin filters out the responses with status 403 and matches the ones
+// whose body contains "admin". As a mutator it sets the X-Plugin header and
+// sends the requests for /status/500 to /status/201 instead. As an input it
+// fuzzes with 200, 403, 404 and 500.
+package plugintest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/plugin"
+)
+
+// Env selects what the plugin does: "ok" to work as described, "fail-init" to
+// reply to init with an error, "crash" to exit on the first request after init,
+// "crash-once:FILE" to do so only if FILE does not exist yet, creating it, and
+// "hang" to never reply to a request after init
+const Env = "FFUF_TEST_PLUGIN"
+
+// Values are the values of the plugin as an input
+var Values = []string{"200", "403", "404", "500"}
+
+type message struct {
+	Type     string           `json:"type"`
+	Request  *plugin.Request  `json:"request"`
+	Response *plugin.Response `json:"response"`
+}
+
+// Main runs the plugin and exits if Env is set, and returns otherwise
+func Main() {
+	mode := os.Getenv(Env)
+	if mode == "" {
+		return
+	}
+	if err := serve(mode, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "test plugin: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func serve(mode string, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(out)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return err
+		}
+		if msg.Type != "init" && mode == "crash" {
+			return fmt.Errorf("crashing on %s", msg.Type)
+		}
+		if marker, ok := strings.CutPrefix(mode, "crash-once:"); ok && msg.Type != "init" {
+			if _, err := os.Stat(marker); err != nil {
+				_ = os.WriteFile(marker, nil, 0600)
+				return fmt.Errorf("crashing on %s", msg.Type)
+			}
+		}
+		if msg.Type != "init" && mode == "hang" {
+			time.Sleep(time.Hour)
+		}
+		var err error
+		switch msg.Type {
+		case "init":
+			if mode == "fail-init" {
+				err = enc.Encode(map[string]string{"error": "refusing to start"})
+			} else {
+				err = enc.Encode(map[string]string{})
+			}
+		case "filter":
+			err = enc.Encode(map[string]bool{"result": msg.Response.Status == 403})
+		case "matcher":
+			err = enc.Encode(map[string]bool{"result": strings.Contains(string(msg.Response.Body), "admin")})
+		case "mutate":
+			req := msg.Request
+			req.Headers["X-Plugin"] = "mutated"
+			if strings.HasSuffix(req.Url, "/status/500") {
+				req.Url = strings.TrimSuffix(req.Url, "500") + "201"
+			}
+			err = enc.Encode(map[string]*plugin.Request{"request": req})
+		case "input":
+			err = enc.Encode(map[string][]string{"values": Values})
+		default:
+			err = enc.Encode(map[string]string{"error": "unknown message " + msg.Type})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
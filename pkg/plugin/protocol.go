@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// message is a request sent to a plugin
+type message struct {
+	Type     string    `json:"type"`
+	Name     string    `json:"name,omitempty"`
+	Roles    []string  `json:"roles,omitempty"`
+	Version  string    `json:"version,omitempty"`
+	Keyword  string    `json:"keyword,omitempty"`
+	Request  *Request  `json:"request,omitempty"`
+	Response *Response `json:"response,omitempty"`
+}
+
+// reply is the reply of a plugin to a message
+type reply struct {
+	Error   string   `json:"error,omitempty"`
+	Result  bool     `json:"result,omitempty"`
+	Request *Request `json:"request,omitempty"`
+	Values  []string `json:"values,omitempty"`
+}
+
+// Request is a request as the plugins see it
+type Request struct {
+	Method  string            `json:"method"`
+	Url     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    []byte            `json:"body"`
+	Input   map[string]string `json:"input"`
+}
+
+// Response is a response as the plugins see it
+type Response struct {
+	Status        int64               `json:"status"`
+	Headers       map[string][]string `json:"headers"`
+	Body          []byte              `json:"body"`
+	ContentLength int64               `json:"length"`
+	ContentWords  int64               `json:"words"`
+	ContentLines  int64               `json:"lines"`
+	ContentType   string              `json:"content_type"`
+	Duration      time.Duration       `json:"duration"`
+	Request       *Request            `json:"request"`
+}
+
+func newRequest(req *ffuf.Request) *Request {
+	input := make(map[string]string, len(req.Input))
+	for k, v := range req.Input {
+		input[k] = string(v)
+	}
+	return &Request{
+		Method:  req.Method,
+		Url:     req.Url,
+		Headers: req.Headers,
+		Body:    req.Data,
+		Input:   input,
+	}
+}
+
+func newResponse(resp *ffuf.Response) *Response {
+	r := &Response{
+		Status:        resp.StatusCode,
+		Headers:       resp.Headers,
+		Body:          resp.Data,
+		ContentLength: resp.ContentLength,
+		ContentWords:  resp.ContentWords,
+		ContentLines:  resp.ContentLines,
+		ContentType:   resp.ContentType,
+		Duration:      resp.Duration,
+	}
+	if resp.Request != nil {
+		r.Request = newRequest(resp.Request)
+	}
+	return r
+}
+
+// apply changes req to the request a mutator plugin replied with. The inputs
+// are not changed, they identify the request in the results.
+func (r *Request) apply(req *ffuf.Request) {
+	req.Method = r.Method
+	req.Url = r.Url
+	req.Headers = r.Headers
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	req.Data = r.Body
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// dialContextFunc is the signature of http.Transport.DialContext.
+type dialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newDialContext returns the DialContext for the runner transport. The -resolve
+// overrides only swap the address that gets dialed, and -dns-resolver only swaps
+// the resolver the dialer uses, so the request URL, the Host header and the TLS
+// SNI (all derived from the request, not the connection) stay under the user's
+// control independently of where the connection actually goes.
+func newDialContext(conf *ffuf.Config) dialContextFunc {
+	timeout := time.Duration(conf.Timeout) * time.Second
+	dialer := &net.Dialer{
+		Timeout: timeout,
+	}
+	if conf.DNSResolver != "" {
+		resolverAddr := conf.DNSResolver
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				d := net.Dialer{Timeout: timeout}
+				return d.DialContext(ctx, network, resolverAddr)
+			},
+		}
+	}
+	if len(conf.Resolve) == 0 {
+		return dialer.DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, resolveOverride(conf.Resolve, addr))
+	}
+}
+
+// newUnixDialContext returns a DialContext that connects to the Unix domain
+// socket at path regardless of the address the transport asks for. Everything
+// above the connection (URL templating, Host header, TLS) is left as is.
+func newUnixDialContext(conf *ffuf.Config, path string) dialContextFunc {
+	dialer := &net.Dialer{
+		Timeout: time.Duration(conf.Timeout) * time.Second,
+	}
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", path)
+	}
+}
+
+// resolveOverride returns the address to dial for addr ("host:port") after
+// applying the -resolve mappings. An exact host:port mapping wins over a
+// host:* wildcard; addr is returned unchanged when nothing matches.
+func resolveOverride(overrides map[string]string, addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	host = strings.ToLower(host)
+	if ip, ok := overrides[net.JoinHostPort(host, port)]; ok {
+		return net.JoinHostPort(ip, port)
+	}
+	if ip, ok := overrides[net.JoinHostPort(host, "*")]; ok {
+		return net.JoinHostPort(ip, port)
+	}
+	return addr
+}
@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func TestResolveOverride(t *testing.T) {
+	overrides := map[string]string{
+		"example.org:443": "10.0.0.1",
+		"example.org:*":   "10.0.0.2",
+		"v6.example:80":   "::1",
+	}
+	tests := map[string]string{
+		"example.org:443": "10.0.0.1:443",
+		"EXAMPLE.org:443": "10.0.0.1:443",
+		"example.org:80":  "10.0.0.2:80",
+		"v6.example:80":   "[::1]:80",
+		"other.org:443":   "other.org:443",
+	}
+	for addr, want := range tests {
+		if got := resolveOverride(overrides, addr); got != want {
+			t.Errorf("resolveOverride(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+// A -resolve override must change only where the connection goes: the request
+// keeps its URL host in the Host header, and the dialed address is recorded on
+// the response.
+func TestExecute_ResolveOverride(t *testing.T) {
+	var gotHost string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		_, _ = w.Write([]byte("staging"))
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	_, port, _ := net.SplitHostPort(u.Host)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf := ffuf.NewConfig(ctx, cancel)
+	conf.Timeout = 5
+	conf.Resolve["production.invalid:"+port] = "127.0.0.1"
+	r := NewSimpleRunner(&conf, false)
+
+	req := ffuf.NewRequest(&conf)
+	req.Url = "http://production.invalid:" + port + "/"
+	resp, err := r.Execute(&req)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if string(resp.Data) != "staging" {
+		t.Errorf("body = %q, want the overridden target's response", resp.Data)
+	}
+	if gotHost != "production.invalid:"+port {
+		t.Errorf("Host header = %q, want the URL host to be kept", gotHost)
+	}
+	if resp.RemoteAddr != "127.0.0.1:"+port {
+		t.Errorf("RemoteAddr = %q, want 127.0.0.1:%s", resp.RemoteAddr, port)
+	}
+}
+
+// With -unix-socket every connection goes to the socket, while the URL host is
+// still what the server sees in the Host header and the path is templated as
+// usual.
+func TestExecute_UnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "ffuf.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets not available: %v", err)
+	}
+	var gotHost, gotPath string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost, gotPath = r.Host, r.URL.Path
+		_, _ = w.Write([]byte("from the socket"))
+	}))
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf := ffuf.NewConfig(ctx, cancel)
+	conf.Timeout = 5
+	conf.UnixSocket = sock
+	conf.Url = "http://docker/containers/FUZZ"
+	r := NewSimpleRunner(&conf, false)
+
+	base := ffuf.NewRequest(&conf)
+	req, _ := r.Prepare(map[string][]byte{"FUZZ": []byte("json")}, &base)
+	resp, err := r.Execute(&req)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if string(resp.Data) != "from the socket" {
+		t.Errorf("body = %q, want the socket server's response", resp.Data)
+	}
+	if gotHost != "docker" || gotPath != "/containers/json" {
+		t.Errorf("server saw Host %q path %q, want docker /containers/json", gotHost, gotPath)
+	}
+}
@@ -0,0 +1,266 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// graphqlRequest is the JSON envelope a GraphQL query is sent in.
+type graphqlRequest struct {
+	Query     string          `json:"query"`
+	Variables json.RawMessage `json:"variables,omitempty"`
+}
+
+// graphqlAliasPrefix names the aliased copies of the query in a batch: ffuf0, ffuf1, ...
+const graphqlAliasPrefix = "ffuf"
+
+// graphqlLeadingField matches the first field of a selection set, with its alias if it has one.
+var graphqlLeadingField = regexp.MustCompile(`^\s*([_A-Za-z][_0-9A-Za-z]*)(\s*:\s*([_A-Za-z][_0-9A-Za-z]*))?`)
+
+// GraphQLRunner builds the request body from the -graphql query template instead
+// of -d. Keywords are substituted into the query before it is JSON encoded, and
+// JSON escaped in the -graphql-vars variables, so any input produces a well formed
+// envelope. The request itself is sent by the wrapped http runner.
+//
+// With -graphql-batch it is a ffuf.BatchRunnerProvider: the inputs of a batch are
+// sent as aliased copies of the query's selection set in one request, and the
+// response is split back to one response per input so the matchers and filters
+// see the same body they would for a request of its own.
+type GraphQLRunner struct {
+	config *ffuf.Config
+	runner ffuf.RunnerProvider
+	// suggestions receives the names suggested in GraphQL error messages, for -graphql-harvest
+	suggestions func(names []string)
+}
+
+func NewGraphQLRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
+	return &GraphQLRunner{
+		config: conf,
+		runner: NewSimpleRunner(conf, replay),
+	}
+}
+
+// OnSuggestions sets the function the names suggested in error messages are passed to.
+func (r *GraphQLRunner) OnSuggestions(f func(names []string)) {
+	r.suggestions = f
+}
+
+func (r *GraphQLRunner) Prepare(input map[string][]byte, basereq *ffuf.Request) (ffuf.Request, error) {
+	req, err := r.runner.Prepare(input, basereq)
+	if err != nil {
+		return req, err
+	}
+	query := r.config.GraphQLQuery
+	for keyword, inputitem := range input {
+		query = strings.ReplaceAll(query, keyword, string(inputitem))
+	}
+	req.Data, err = r.envelope(query, input)
+	r.setContentType(&req)
+	return req, err
+}
+
+func (r *GraphQLRunner) BatchSize() int {
+	return r.config.GraphQLBatch
+}
+
+func (r *GraphQLRunner) PrepareBatch(inputs []map[string][]byte, basereq *ffuf.Request) (ffuf.Request, error) {
+	req, err := r.runner.Prepare(inputs[0], basereq)
+	if err != nil {
+		return req, err
+	}
+	prefix, selection, suffix, err := splitSelectionSet(r.config.GraphQLQuery)
+	if err != nil {
+		return req, err
+	}
+	var query strings.Builder
+	query.WriteString(prefix)
+	for i, input := range inputs {
+		member, _ := batchMember(selection, input)
+		fmt.Fprintf(&query, " %s%d: %s", graphqlAliasPrefix, i, member)
+	}
+	query.WriteString(suffix)
+	req.Data, err = r.envelope(query.String(), inputs[0])
+	r.setContentType(&req)
+	return req, err
+}
+
+func (r *GraphQLRunner) SplitBatch(resp ffuf.Response, inputs []map[string][]byte) []ffuf.Response {
+	responses := make([]ffuf.Response, len(inputs))
+	var body struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []map[string]interface{}   `json:"errors"`
+	}
+	_, selection, _, err := splitSelectionSet(r.config.GraphQLQuery)
+	parsed := err == nil && json.Unmarshal(resp.Data, &body) == nil
+	for i, input := range inputs {
+		split := resp
+		req := *resp.Request
+		req.Input = input
+		split.Request = &req
+		split.ScraperData = make(map[string][]string)
+		responses[i] = split
+		if !parsed {
+			// Not a GraphQL response (an error page or similar), every input gets all of it
+			continue
+		}
+		alias := fmt.Sprintf("%s%d", graphqlAliasPrefix, i)
+		_, key := batchMember(selection, input)
+		member := map[string]interface{}{}
+		if body.Data != nil {
+			data := map[string]json.RawMessage{}
+			if value, ok := body.Data[alias]; ok {
+				data[key] = value
+			}
+			member["data"] = data
+		} else {
+			member["data"] = nil
+		}
+		errs := make([]map[string]interface{}, 0)
+		for _, e := range body.Errors {
+			path, _ := e["path"].([]interface{})
+			if len(path) == 0 {
+				// Not tied to a field (eg. a validation error): applies to the whole batch
+				errs = append(errs, e)
+				continue
+			}
+			if path[0] != alias {
+				continue
+			}
+			renamed := make(map[string]interface{}, len(e))
+			for k, v := range e {
+				renamed[k] = v
+			}
+			renamed["path"] = append([]interface{}{key}, path[1:]...)
+			errs = append(errs, renamed)
+		}
+		if len(errs) > 0 {
+			member["errors"] = errs
+		}
+		if data, err := json.Marshal(member); err == nil {
+			setContent(&responses[i], data)
+		}
+	}
+	return responses
+}
+
+func (r *GraphQLRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+	resp, err := r.runner.Execute(req)
+	if err == nil && r.suggestions != nil {
+		if gql, ok := ffuf.ParseGraphQLResponse(resp.Data); ok {
+			if names := gql.Suggestions(); len(names) > 0 {
+				r.suggestions(names)
+			}
+		}
+	}
+	return resp, err
+}
+
+func (r *GraphQLRunner) Dump(req *ffuf.Request) ([]byte, error) {
+	return r.runner.Dump(req)
+}
+
+// envelope returns the JSON request body for query, with the keywords in the
+// variables template substituted with JSON escaped input values.
+func (r *GraphQLRunner) envelope(query string, input map[string][]byte) ([]byte, error) {
+	body := graphqlRequest{Query: query}
+	if r.config.GraphQLVariables != "" {
+		variables := r.config.GraphQLVariables
+		for keyword, inputitem := range input {
+			escaped, _ := json.Marshal(string(inputitem))
+			variables = strings.ReplaceAll(variables, keyword, string(escaped[1:len(escaped)-1]))
+		}
+		if !json.Valid([]byte(variables)) {
+			return nil, fmt.Errorf("GraphQL variables (-graphql-vars) are not valid JSON: %s", variables)
+		}
+		body.Variables = json.RawMessage(variables)
+	}
+	return json.Marshal(body)
+}
+
+func (r *GraphQLRunner) setContentType(req *ffuf.Request) {
+	if _, ok := req.Headers["Content-Type"]; !ok {
+		req.Headers["Content-Type"] = "application/json"
+	}
+}
+
+// splitSelectionSet splits a GraphQL document around the contents of the first
+// operation's selection set, eg. `query Q($id: ID) {` + ` user(id: $id) { name } ` + `}`.
+// Strings, comments and variable definitions (which may contain braces in their
+// default values) are skipped over.
+func splitSelectionSet(query string) (prefix, selection, suffix string, err error) {
+	depth, parens, open := 0, 0, -1
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; c {
+		case '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case '"':
+			i = skipGraphQLString(query, i)
+		case '(':
+			parens++
+		case ')':
+			parens--
+		case '{':
+			if parens > 0 {
+				continue
+			}
+			if depth == 0 && open == -1 {
+				open = i
+			}
+			depth++
+		case '}':
+			if parens > 0 {
+				continue
+			}
+			depth--
+			if depth == 0 && open != -1 {
+				return query[:open+1], query[open+1 : i], query[i:], nil
+			}
+		}
+	}
+	return "", "", "", fmt.Errorf("could not find the selection set of the GraphQL query to batch")
+}
+
+// skipGraphQLString returns the index of the closing quote of the string or
+// block string starting at query[start].
+func skipGraphQLString(query string, start int) int {
+	if strings.HasPrefix(query[start:], `"""`) {
+		if end := strings.Index(query[start+3:], `"""`); end != -1 {
+			return start + 3 + end + 2
+		}
+		return len(query)
+	}
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(query)
+}
+
+// batchMember returns the selection with input substituted and any alias of its
+// leading field removed, along with the response key the field would have had
+// on its own (the alias, or the field name).
+func batchMember(selection string, input map[string][]byte) (string, string) {
+	for keyword, inputitem := range input {
+		selection = strings.ReplaceAll(selection, keyword, string(inputitem))
+	}
+	m := graphqlLeadingField.FindStringSubmatchIndex(selection)
+	if m == nil {
+		return selection, ""
+	}
+	key := selection[m[2]:m[3]]
+	if m[6] != -1 {
+		// Drop the alias, it is replaced by the batch alias
+		return selection[m[6]:], key
+	}
+	return selection, key
+}
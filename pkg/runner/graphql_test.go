@@ -0,0 +1,148 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func newGraphQLConfig(t *testing.T, url, query string) *ffuf.Config {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf := ffuf.NewConfig(ctx, cancel)
+	conf.Timeout = 5
+	conf.Url = url
+	conf.Method = "POST"
+	conf.GraphQLQuery = query
+	conf.GraphQLBatch = 1
+	return &conf
+}
+
+func decodeEnvelope(t *testing.T, data []byte) graphqlRequest {
+	t.Helper()
+	var body graphqlRequest
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("request body is not JSON: %s", data)
+	}
+	return body
+}
+
+func TestGraphQLRunner_PrepareEnvelope(t *testing.T) {
+	conf := newGraphQLConfig(t, "http://127.0.0.1/graphql", `{ user(name: "FUZZ") { id } }`)
+	conf.GraphQLVariables = `{"filter": "FUZZ"}`
+	r := NewGraphQLRunner(conf, false)
+	base := ffuf.BaseRequest(conf)
+	req, err := r.Prepare(map[string][]byte{"FUZZ": []byte(`a"b\c`)}, &base)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	body := decodeEnvelope(t, req.Data)
+	if want := `{ user(name: "a"b\c") { id } }`; body.Query != want {
+		t.Errorf("query = %q, want %q", body.Query, want)
+	}
+	var vars map[string]string
+	if err := json.Unmarshal(body.Variables, &vars); err != nil || vars["filter"] != `a"b\c` {
+		t.Errorf("variables = %s (%v)", body.Variables, err)
+	}
+	if req.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type = %q", req.Headers["Content-Type"])
+	}
+
+	conf.GraphQLVariables = `{"filter": FUZZ}`
+	if _, err := r.Prepare(map[string][]byte{"FUZZ": []byte("x")}, &base); err == nil {
+		t.Errorf("expected an error for variables that are not valid JSON")
+	}
+}
+
+func TestSplitSelectionSet(t *testing.T) {
+	query := `query Q($f: In = {a: "}"}) # not {here}
+{ user(name: "{FUZZ}") { id } }`
+	prefix, selection, suffix, err := splitSelectionSet(query)
+	if err != nil {
+		t.Fatalf("splitSelectionSet: %v", err)
+	}
+	if !strings.HasSuffix(prefix, "{here}\n{") || selection != ` user(name: "{FUZZ}") { id } ` || suffix != "}" {
+		t.Errorf("got %q / %q / %q", prefix, selection, suffix)
+	}
+	if _, _, _, err := splitSelectionSet(`query Q`); err == nil {
+		t.Errorf("expected an error for a query without a selection set")
+	}
+}
+
+func TestGraphQLRunner_Batch(t *testing.T) {
+	conf := newGraphQLConfig(t, "http://127.0.0.1/graphql", `query { u: user(name: "FUZZ") { id } }`)
+	conf.GraphQLBatch = 3
+	r := NewGraphQLRunner(conf, false).(*GraphQLRunner)
+	inputs := []map[string][]byte{
+		{"FUZZ": []byte("admin")},
+		{"FUZZ": []byte("bob")},
+		{"FUZZ": []byte("eve")},
+	}
+	base := ffuf.BaseRequest(conf)
+	req, err := r.PrepareBatch(inputs, &base)
+	if err != nil {
+		t.Fatalf("prepare batch: %v", err)
+	}
+	want := `query { ffuf0: user(name: "admin") { id }  ffuf1: user(name: "bob") { id }  ffuf2: user(name: "eve") { id } }`
+	if got := decodeEnvelope(t, req.Data).Query; got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+
+	resp := ffuf.NewResponse(&http.Response{StatusCode: 200}, &req)
+	resp.Data = []byte(`{"data":{"ffuf0":{"id":1},"ffuf1":null,"ffuf2":null},"errors":[
+		{"message":"not found","path":["ffuf1"]},
+		{"message":"forbidden","path":["ffuf2","id"]},
+		{"message":"rate limited"}]}`)
+	split := r.SplitBatch(resp, inputs)
+	wantBodies := []string{
+		`{"data":{"u":{"id":1}},"errors":[{"message":"rate limited"}]}`,
+		`{"data":{"u":null},"errors":[{"message":"not found","path":["u"]},{"message":"rate limited"}]}`,
+		`{"data":{"u":null},"errors":[{"message":"forbidden","path":["u","id"]},{"message":"rate limited"}]}`,
+	}
+	for i, s := range split {
+		if string(s.Data) != wantBodies[i] {
+			t.Errorf("split %d = %s, want %s", i, s.Data, wantBodies[i])
+		}
+		if !reflect.DeepEqual(s.Request.Input, inputs[i]) {
+			t.Errorf("split %d has input %v", i, s.Request.Input)
+		}
+		if s.ContentLength != int64(len(s.Data)) {
+			t.Errorf("split %d content length %d, body %d", i, s.ContentLength, len(s.Data))
+		}
+	}
+	resp.Data = []byte("<html>bad gateway</html>")
+	for i, s := range r.SplitBatch(resp, inputs) {
+		if string(s.Data) != "<html>bad gateway</html>" {
+			t.Errorf("non GraphQL split %d = %s", i, s.Data)
+		}
+	}
+}
+
+func TestGraphQLRunner_Suggestions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Cannot query field \"usr\" on type \"Query\". Did you mean \"user\"?"}]}`))
+	}))
+	defer srv.Close()
+
+	conf := newGraphQLConfig(t, srv.URL, `{ FUZZ }`)
+	r := NewGraphQLRunner(conf, false).(*GraphQLRunner)
+	var got []string
+	r.OnSuggestions(func(names []string) { got = append(got, names...) })
+	base := ffuf.BaseRequest(conf)
+	req, _ := r.Prepare(map[string][]byte{"FUZZ": []byte("usr")}, &base)
+	if _, err := r.Execute(&req); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"user"}) {
+		t.Errorf("suggestions = %v", got)
+	}
+}
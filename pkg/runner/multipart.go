@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// multipartBody builds the -F multipart/form-data body with the keywords
+// substituted, and returns it with its Content-Type. The boundary is new for
+// every body, so it can not collide with an input.
+func multipartBody(parts []ffuf.MultipartPart, input map[string][]byte) ([]byte, string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, p := range parts {
+		for keyword, inputitem := range input {
+			p.Name = strings.ReplaceAll(p.Name, keyword, string(inputitem))
+			p.Value = strings.ReplaceAll(p.Value, keyword, string(inputitem))
+			p.File = strings.ReplaceAll(p.File, keyword, string(inputitem))
+			p.Filename = strings.ReplaceAll(p.Filename, keyword, string(inputitem))
+			p.ContentType = strings.ReplaceAll(p.ContentType, keyword, string(inputitem))
+		}
+		content := []byte(p.Value)
+		if p.File != "" {
+			var err error
+			if content, err = os.ReadFile(p.File); err != nil {
+				return nil, "", fmt.Errorf("could not read the file of multipart part %s: %w", p.Name, err)
+			}
+			if p.Filename == "" {
+				p.Filename = filepath.Base(p.File)
+			}
+		}
+
+		h := make(textproto.MIMEHeader)
+		disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(p.Name))
+		if p.Filename != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(p.Filename))
+			if p.ContentType == "" {
+				p.ContentType = "application/octet-stream"
+			}
+		}
+		h.Set("Content-Disposition", disposition)
+		if p.ContentType != "" {
+			h.Set("Content-Type", p.ContentType)
+		}
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		_, _ = pw.Write(content)
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), w.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes a Content-Disposition parameter value like mime/multipart does
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
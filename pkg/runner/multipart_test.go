@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func TestMultipartBody(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "shell.php"), []byte("<?php ?>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parts := []ffuf.MultipartPart{
+		{Name: "note", Value: "hello FUZZ"},
+		{Name: "upload", File: filepath.Join(dir, "FUZZ"), ContentType: "image/png"},
+		{Name: "raw", Value: "x", Filename: `a"FUZZ`},
+	}
+	input := map[string][]byte{"FUZZ": []byte("shell.php")}
+	body, contentType, err := multipartBody(parts, input)
+	if err != nil {
+		t.Fatalf("multipartBody: %v", err)
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(contentType, "multipart/form-data") {
+		t.Fatalf("Content-Type %q: %v", contentType, err)
+	}
+
+	type part struct{ name, filename, contentType, content string }
+	want := []part{
+		{"note", "", "", "hello shell.php"},
+		{"upload", "shell.php", "image/png", "<?php ?>"},
+		{"raw", `a"shell.php`, "application/octet-stream", "x"},
+	}
+	r := multipart.NewReader(strings.NewReader(string(body)), params["boundary"])
+	for i, w := range want {
+		p, err := r.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		content, _ := io.ReadAll(p)
+		got := part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(content)}
+		if got != w {
+			t.Errorf("part %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	_, second, _ := multipartBody(parts, input)
+	if second == contentType {
+		t.Errorf("expected a new boundary for every body")
+	}
+	if _, _, err := multipartBody(parts, map[string][]byte{"FUZZ": []byte("missing")}); err == nil {
+		t.Errorf("expected an error for a file that does not exist")
+	}
+}
@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// ParamMinerRunner adds the candidate parameter names of -param-mine to the
+// request instead of substituting a keyword: the FUZZ inputs of a batch are all
+// sent in one request, as query or form parameters, JSON body members or
+// headers, each with the same random value.
+//
+// The engine compares the response of a batch to the autocalibration baseline
+// and bisects the batches that differ, so SplitBatch is only a fallback that
+// gives every input the response of the whole batch.
+type ParamMinerRunner struct {
+	config *ffuf.Config
+	runner ffuf.RunnerProvider
+	// value is sent as the value of every candidate parameter, random so a reflection of it stands out
+	value string
+}
+
+func NewParamMinerRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
+	return &ParamMinerRunner{
+		config: conf,
+		runner: NewSimpleRunner(conf, replay),
+		value:  ffuf.RandomString(8),
+	}
+}
+
+func (r *ParamMinerRunner) Prepare(input map[string][]byte, basereq *ffuf.Request) (ffuf.Request, error) {
+	return r.PrepareBatch([]map[string][]byte{input}, basereq)
+}
+
+func (r *ParamMinerRunner) BatchSize() int {
+	return r.config.ParamBatch
+}
+
+func (r *ParamMinerRunner) PrepareBatch(inputs []map[string][]byte, basereq *ffuf.Request) (ffuf.Request, error) {
+	req, err := r.runner.Prepare(inputs[0], basereq)
+	if err != nil {
+		return req, err
+	}
+	names := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		names = append(names, string(input["FUZZ"]))
+	}
+
+	switch r.config.ParamMine {
+	case "query":
+		params := r.urlencoded(names)
+		if strings.Contains(req.Url, "?") {
+			req.Url += "&" + params
+		} else {
+			req.Url += "?" + params
+		}
+	case "form":
+		params := r.urlencoded(names)
+		if len(req.Data) > 0 {
+			params = string(req.Data) + "&" + params
+		}
+		req.Data = []byte(params)
+		r.setContentType(&req, "application/x-www-form-urlencoded")
+	case "json":
+		data := strings.TrimSpace(string(req.Data))
+		if data == "" {
+			data = "{}"
+		}
+		if !strings.HasPrefix(data, "{") || !strings.HasSuffix(data, "}") {
+			return req, fmt.Errorf("-param-mine json needs the request body to be a JSON object")
+		}
+		value, _ := json.Marshal(r.value)
+		var members strings.Builder
+		for i, name := range names {
+			if i > 0 {
+				members.WriteString(",")
+			}
+			key, _ := json.Marshal(name)
+			fmt.Fprintf(&members, "%s:%s", key, value)
+		}
+		body := strings.TrimSpace(data[1 : len(data)-1])
+		if body != "" {
+			body += ","
+		}
+		req.Data = []byte("{" + body + members.String() + "}")
+		r.setContentType(&req, "application/json")
+	case "header":
+		for _, name := range names {
+			// A name that is not a valid header field name would fail the whole request
+			if validHeaderName(name) {
+				req.Headers[name] = r.value
+			}
+		}
+	}
+	return req, nil
+}
+
+func (r *ParamMinerRunner) SplitBatch(resp ffuf.Response, inputs []map[string][]byte) []ffuf.Response {
+	responses := make([]ffuf.Response, len(inputs))
+	for i, input := range inputs {
+		responses[i] = resp
+		req := *resp.Request
+		req.Input = input
+		responses[i].Request = &req
+		responses[i].ScraperData = make(map[string][]string)
+	}
+	return responses
+}
+
+func (r *ParamMinerRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+	return r.runner.Execute(req)
+}
+
+func (r *ParamMinerRunner) Dump(req *ffuf.Request) ([]byte, error) {
+	return r.runner.Dump(req)
+}
+
+func (r *ParamMinerRunner) urlencoded(names []string) string {
+	params := make([]string, 0, len(names))
+	for _, name := range names {
+		params = append(params, url.QueryEscape(name)+"="+url.QueryEscape(r.value))
+	}
+	return strings.Join(params, "&")
+}
+
+func (r *ParamMinerRunner) setContentType(req *ffuf.Request, contentType string) {
+	for h := range req.Headers {
+		if strings.EqualFold(h, "Content-Type") {
+			return
+		}
+	}
+	req.Headers["Content-Type"] = contentType
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name (a token)
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c > 0x7e || c <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c) {
+			return false
+		}
+	}
+	return true
+}
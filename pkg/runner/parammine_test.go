@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func TestParamMinerRunner_PrepareBatch(t *testing.T) {
+	inputs := []map[string][]byte{
+		{"FUZZ": []byte("id")},
+		{"FUZZ": []byte("a b")},
+		{"FUZZ": []byte("bad:name")},
+	}
+	for _, test := range []struct {
+		location string
+		url      string
+		data     string
+		check    func(t *testing.T, req ffuf.Request, value string)
+	}{
+		{"query", "http://example.com/?x=1", "", func(t *testing.T, req ffuf.Request, value string) {
+			want := "http://example.com/?x=1&id=" + value + "&a+b=" + value + "&bad%3Aname=" + value
+			if req.Url != want {
+				t.Errorf("url = %s, want %s", req.Url, want)
+			}
+		}},
+		{"form", "http://example.com/", "x=1", func(t *testing.T, req ffuf.Request, value string) {
+			want := "x=1&id=" + value + "&a+b=" + value + "&bad%3Aname=" + value
+			if string(req.Data) != want || req.Headers["Content-Type"] != "application/x-www-form-urlencoded" {
+				t.Errorf("body = %s (%s), want %s", req.Data, req.Headers["Content-Type"], want)
+			}
+		}},
+		{"json", "http://example.com/", `{"x": 1}`, func(t *testing.T, req ffuf.Request, value string) {
+			want := `{"x": 1,"id":"` + value + `","a b":"` + value + `","bad:name":"` + value + `"}`
+			if string(req.Data) != want || req.Headers["Content-Type"] != "application/json" {
+				t.Errorf("body = %s (%s), want %s", req.Data, req.Headers["Content-Type"], want)
+			}
+		}},
+		{"header", "http://example.com/", "", func(t *testing.T, req ffuf.Request, value string) {
+			if req.Headers["id"] != value {
+				t.Errorf("headers = %v, missing the candidate", req.Headers)
+			}
+			for h := range req.Headers {
+				if strings.Contains(h, " ") || strings.Contains(h, ":") {
+					t.Errorf("invalid header name %q was added", h)
+				}
+			}
+		}},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		conf := ffuf.NewConfig(ctx, cancel)
+		conf.Url = test.url
+		conf.Data = test.data
+		conf.ParamMine = test.location
+		conf.ParamBatch = len(inputs)
+		r := NewParamMinerRunner(&conf, false).(*ParamMinerRunner)
+		base := ffuf.BaseRequest(&conf)
+		req, err := r.PrepareBatch(inputs, &base)
+		if err != nil {
+			t.Errorf("%s: %v", test.location, err)
+		} else {
+			test.check(t, req, r.value)
+		}
+		if len(base.Headers) != 0 {
+			t.Errorf("%s: the base request was modified: %v", test.location, base.Headers)
+		}
+		cancel()
+	}
+}
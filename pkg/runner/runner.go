@@ -0,0 +1,19 @@
+package runner
+
+import (
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// NewRunner returns the runner the configuration calls for: the WebSocket,
+// GraphQL or parameter mining runner, or the plain http one.
+func NewRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
+	switch {
+	case conf.ParamMine != "":
+		return NewParamMinerRunner(conf, replay)
+	case conf.GraphQLQuery != "":
+		return NewGraphQLRunner(conf, replay)
+	case conf.WebSocket:
+		return NewWebSocketRunner(conf, replay)
+	}
+	return NewSimpleRunner(conf, replay)
+}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net"
 	"net/http"
 	"net/http/httptrace"
 	"net/http/httputil"
@@ -90,6 +89,13 @@ func NewSimpleRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
 			proxyURL = http.ProxyURL(pu)
 		}
 	}
+	dialContext := newDialContext(conf)
+	if len(conf.UnixSocket) > 0 && !replay {
+		// The socket is the target itself, there is no host to proxy to. The replay
+		// runner keeps dialing its proxy as usual.
+		dialContext = newUnixDialContext(conf, conf.UnixSocket)
+		proxyURL = nil
+	}
 	cert := []tls.Certificate{}
 
 	if conf.ClientCert != "" && conf.ClientKey != "" {
@@ -108,9 +114,7 @@ func NewSimpleRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
 			MaxIdleConns:        1000,
 			MaxIdleConnsPerHost: 500,
 			MaxConnsPerHost:     500,
-			DialContext: (&net.Dialer{
-				Timeout: time.Duration(time.Duration(conf.Timeout) * time.Second),
-			}).DialContext,
+			DialContext:         dialContext,
 			TLSHandshakeTimeout: time.Duration(time.Duration(conf.Timeout) * time.Second),
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: true,
@@ -131,6 +135,10 @@ func (r *SimpleRunner) Prepare(input map[string][]byte, basereq *ffuf.Request) (
 	req := ffuf.CopyRequest(basereq)
 
 	for keyword, inputitem := range input {
+		if keyword == "FUZZ" && req.Encoding != "" {
+			// Placed by -mode auto, encode the input for the type of its injection point
+			inputitem = ffuf.EncodeInjection(req.Encoding, inputitem)
+		}
 		req.Method = strings.ReplaceAll(req.Method, keyword, string(inputitem))
 		headers := make(map[string]string, len(req.Headers))
 		for h, v := range req.Headers {
@@ -142,6 +150,20 @@ func (r *SimpleRunner) Prepare(input map[string][]byte, basereq *ffuf.Request) (
 		req.Data = []byte(strings.ReplaceAll(string(req.Data), keyword, string(inputitem)))
 	}
 
+	if len(r.config.Multipart) > 0 {
+		body, contentType, err := multipartBody(r.config.Multipart, input)
+		if err != nil {
+			return req, err
+		}
+		req.Data = body
+		for h := range req.Headers {
+			if strings.EqualFold(h, "Content-Type") {
+				delete(req.Headers, h)
+			}
+		}
+		req.Headers["Content-Type"] = contentType
+	}
+
 	req.Input = input
 	return req, nil
 }
@@ -177,8 +199,15 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (resp ffuf.Response, err error
 
 	var start time.Time
 	var firstByteTime time.Duration
+	var remoteAddr string
 
 	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			// DumpRequestOut runs the request through a fake connection without an address
+			if addr := info.Conn.RemoteAddr(); addr != nil {
+				remoteAddr = addr.String() // the address actually dialed
+			}
+		},
 		WroteRequest: func(wri httptrace.WroteRequestInfo) {
 			start = time.Now() // begin the timer after the request is fully written
 		},
@@ -227,6 +256,7 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (resp ffuf.Response, err error
 	req.Timestamp = start
 
 	resp = ffuf.NewResponse(httpresp, req)
+	resp.RemoteAddr = remoteAddr
 	defer httpresp.Body.Close()
 
 	// Check if we should download the resource or not
@@ -358,7 +388,7 @@ func (r *SimpleRunner) parsePreflightRequest(filename string, vars map[string]st
 	} else {
 		scheme := "https"
 		if u, uerr := url.Parse(r.config.Url); uerr == nil && u.Scheme != "" {
-			scheme = u.Scheme
+			scheme = httpScheme(u.Scheme)
 			if host == "" {
 				host = u.Host
 			}
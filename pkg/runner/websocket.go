@@ -0,0 +1,415 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// WebSocket opcodes, RFC 6455 section 5.2
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+)
+
+// wsAcceptGUID is appended to the handshake key to compute Sec-WebSocket-Accept.
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocketRunner fuzzes ws:// and wss:// targets. Every request is a fresh
+// connection: the handshake carries the templated URL and headers, the templated
+// body (-d) is sent as a single text message, and the messages received within
+// -ws-timeout are joined into the response body, so all of the existing matchers,
+// filters and outputs work on them unchanged.
+type WebSocketRunner struct {
+	config *ffuf.Config
+	// http does the handshake and the preflight chains, so proxies, -resolve,
+	// -unix-socket, client certificates and preflight variables apply as usual.
+	http   *SimpleRunner
+	client *http.Client
+}
+
+func NewWebSocketRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
+	simplerunner := NewSimpleRunner(conf, replay).(*SimpleRunner)
+	transport := simplerunner.client.Transport.(*http.Transport)
+	// The upgrade only works over HTTP/1.1
+	transport.ForceAttemptHTTP2 = false
+	return &WebSocketRunner{
+		config: conf,
+		http:   simplerunner,
+		// No client timeout: it would also cover the upgraded connection. The
+		// handshake and the exchange are bounded by Execute instead.
+		client: &http.Client{
+			CheckRedirect: simplerunner.client.CheckRedirect,
+			Transport:     transport,
+		},
+	}
+}
+
+func (r *WebSocketRunner) Prepare(input map[string][]byte, basereq *ffuf.Request) (ffuf.Request, error) {
+	return r.http.Prepare(input, basereq)
+}
+
+func (r *WebSocketRunner) Execute(req *ffuf.Request) (resp ffuf.Response, err error) {
+	targetHost := hostOf(req.Url)
+	appliedVars, releaseLane, pferr := r.http.runPreflights(req)
+	defer releaseLane()
+	defer func() {
+		if err == nil {
+			r.http.runPostflights(appliedVars)
+		}
+	}()
+	if pferr != nil {
+		return ffuf.Response{}, pferr
+	}
+	if len(appliedVars) > 0 {
+		if h := hostOf(req.Url); h != targetHost {
+			return ffuf.Response{}, fmt.Errorf("preflight variable changed the request host from %q to %q; refusing to send (a captured value must not alter the target host)", targetHost, h)
+		}
+	}
+
+	// Bound the handshake and the whole exchange by -timeout
+	ctx, cancel := context.WithTimeout(r.config.Context, time.Duration(r.config.Timeout)*time.Second)
+	defer cancel()
+	httpreq, key, err := r.handshakeRequest(ctx, req)
+	if err != nil {
+		return ffuf.Response{}, err
+	}
+	dump := len(r.config.OutputDirectory) > 0 || len(r.config.AuditLog) > 0
+	var rawreq []byte
+	if dump {
+		rawreq, _ = httputil.DumpRequestOut(httpreq, false)
+		req.Raw = string(rawreq) + string(req.Data)
+	}
+
+	start := time.Now()
+	httpresp, err := r.client.Do(httpreq)
+	if err != nil {
+		return ffuf.Response{}, err
+	}
+	req.Timestamp = start
+	resp = ffuf.NewResponse(httpresp, req)
+	resp.Duration = time.Since(start)
+	resp.Timestamp = start.Add(resp.Duration)
+
+	if httpresp.StatusCode != http.StatusSwitchingProtocols {
+		// The handshake was refused: report the HTTP response as is
+		defer httpresp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(httpresp.Body, int64(MAX_DOWNLOAD_SIZE)))
+		if dump {
+			rawresp, _ := httputil.DumpResponse(httpresp, false)
+			resp.Raw = string(rawresp) + string(body)
+		}
+		setContent(&resp, body)
+		return resp, nil
+	}
+
+	conn, ok := httpresp.Body.(io.ReadWriteCloser)
+	if !ok {
+		httpresp.Body.Close()
+		return ffuf.Response{}, fmt.Errorf("websocket: connection to %s could not be upgraded", req.Url)
+	}
+	defer conn.Close()
+	if httpresp.Header.Get("Sec-WebSocket-Accept") != wsAcceptKey(key) {
+		return ffuf.Response{}, fmt.Errorf("websocket: invalid Sec-WebSocket-Accept in the handshake response from %s", req.Url)
+	}
+
+	ws := &wsConn{rw: conn, br: bufio.NewReader(conn)}
+	if len(req.Data) > 0 {
+		if err := ws.writeFrame(wsOpText, req.Data); err != nil {
+			return ffuf.Response{}, fmt.Errorf("websocket: sending the message failed: %s", err)
+		}
+	}
+	sent := time.Now()
+	messages, firstReply := r.collect(ctx, ws, sent)
+	// Best effort, the connection is closed right after anyway
+	_ = ws.writeFrame(wsOpClose, []byte{0x03, 0xe8})
+
+	body := bytes.Join(messages, []byte("\n"))
+	if dump {
+		rawresp, _ := httputil.DumpResponse(httpresp, false)
+		resp.Raw = string(rawresp) + string(body)
+	}
+	setContent(&resp, body)
+	if firstReply > 0 {
+		resp.Duration = firstReply
+		resp.Timestamp = sent.Add(firstReply)
+	}
+	return resp, nil
+}
+
+// collect reads messages until -ws-timeout has passed since sent, -ws-frames
+// messages were received, the server closes the connection or ctx is done. It
+// returns the messages and the time from sent to the first one (0 if none).
+func (r *WebSocketRunner) collect(ctx context.Context, ws *wsConn, sent time.Time) ([][]byte, time.Duration) {
+	type message struct {
+		data []byte
+		at   time.Time
+	}
+	incoming := make(chan message)
+	done := make(chan struct{})
+	go func() {
+		defer close(incoming)
+		for {
+			data, err := ws.readMessage()
+			if err != nil {
+				return
+			}
+			select {
+			case incoming <- message{data: data, at: time.Now()}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	messages := make([][]byte, 0)
+	var firstReply time.Duration
+	size := 0
+	deadline := time.NewTimer(time.Until(sent.Add(time.Duration(r.config.WSTimeout) * time.Millisecond)))
+	defer deadline.Stop()
+loop:
+	for {
+		select {
+		case msg, ok := <-incoming:
+			if !ok {
+				break loop
+			}
+			if len(messages) == 0 {
+				firstReply = msg.at.Sub(sent)
+			}
+			size += len(msg.data)
+			if size > MAX_DOWNLOAD_SIZE {
+				break loop
+			}
+			messages = append(messages, msg.data)
+			if r.config.WSFrames > 0 && len(messages) >= r.config.WSFrames {
+				break loop
+			}
+		case <-deadline.C:
+			break loop
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	// Unblock the reader: the caller closes the connection once we return
+	close(done)
+	return messages, firstReply
+}
+
+// handshakeRequest builds the HTTP upgrade request for req and returns it along
+// with the Sec-WebSocket-Key it carries.
+func (r *WebSocketRunner) handshakeRequest(ctx context.Context, req *ffuf.Request) (*http.Request, string, error) {
+	target := req.Url
+	if idx := strings.Index(target, "://"); idx != -1 {
+		target = httpScheme(strings.ToLower(target[:idx])) + target[idx:]
+	}
+	httpreq, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, ok := req.Headers["User-Agent"]; !ok {
+		req.Headers["User-Agent"] = fmt.Sprintf("%s v%s", "Fuzz Faster U Fool", ffuf.Version())
+	}
+	if _, ok := req.Headers["Host"]; ok {
+		httpreq.Host = req.Headers["Host"]
+	}
+	req.Host = httpreq.Host
+	if r.config.Raw {
+		httpreq.URL.Opaque = target
+	}
+	for k, v := range req.Headers {
+		httpreq.Header.Set(k, v)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	httpreq.Header.Set("Connection", "Upgrade")
+	httpreq.Header.Set("Upgrade", "websocket")
+	httpreq.Header.Set("Sec-WebSocket-Version", "13")
+	httpreq.Header.Set("Sec-WebSocket-Key", key)
+	return httpreq, key, nil
+}
+
+func (r *WebSocketRunner) Dump(req *ffuf.Request) ([]byte, error) {
+	httpreq, _, err := r.handshakeRequest(r.config.Context, req)
+	if err != nil {
+		return []byte{}, err
+	}
+	rawreq, err := httputil.DumpRequestOut(httpreq, false)
+	if err != nil {
+		return []byte{}, err
+	}
+	return append(rawreq, req.Data...), nil
+}
+
+// setContent sets the response body and the size, word and line counts derived from it.
+func setContent(resp *ffuf.Response, body []byte) {
+	resp.Data = body
+	resp.ContentLength = int64(len(body))
+	resp.ContentWords = int64(len(strings.Split(string(body), " ")))
+	resp.ContentLines = int64(len(strings.Split(string(body), "\n")))
+}
+
+// httpScheme maps a ws/wss scheme to the http scheme its handshake is made over.
+func httpScheme(scheme string) string {
+	switch scheme {
+	case "ws":
+		return "http"
+	case "wss":
+		return "https"
+	}
+	return scheme
+}
+
+// wsAcceptKey returns the Sec-WebSocket-Accept value expected for key.
+func wsAcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// wsConn is the client side of an upgraded connection. Writes are serialized, as
+// the reader answers pings while the caller may be sending.
+type wsConn struct {
+	rw io.ReadWriter
+	br *bufio.Reader
+	mu sync.Mutex
+}
+
+// writeFrame writes a single, final, masked frame (clients must mask).
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode, 0x80}
+	switch {
+	case len(payload) < 126:
+		header[1] |= byte(len(payload))
+	case len(payload) <= 0xffff:
+		header[1] |= 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header[1] |= 127
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame := make([]byte, 0, len(header)+4+len(payload))
+	frame = append(frame, header...)
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.rw.Write(frame)
+	return err
+}
+
+// readFrame reads one frame, unmasking the payload if the peer masked it.
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	opcode = head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > MAX_DOWNLOAD_SIZE {
+		err = fmt.Errorf("websocket frame of %d bytes exceeds the download limit", length)
+		return
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// errWSClosed is returned by readMessage once the peer sent a close frame.
+var errWSClosed = errors.New("websocket connection closed by the server")
+
+// readMessage returns the next complete text or binary message, reassembling
+// fragments and answering pings on the way.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var message []byte
+	started := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			return nil, errWSClosed
+		case wsOpText, wsOpBinary:
+			message = payload
+			started = true
+		case wsOpContinuation:
+			if !started {
+				return nil, fmt.Errorf("websocket continuation frame without a message to continue")
+			}
+			message = append(message, payload...)
+		default:
+			return nil, fmt.Errorf("websocket frame with unknown opcode %#x", opcode)
+		}
+		if len(message) > MAX_DOWNLOAD_SIZE {
+			return nil, fmt.Errorf("websocket message exceeds the download limit")
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+// writeServerFrame writes an unmasked frame, as a server does.
+func writeServerFrame(w *bufio.Writer, fin bool, opcode byte, payload []byte) {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	header := []byte{first}
+	if len(payload) < 126 {
+		header = append(header, byte(len(payload)))
+	} else {
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	}
+	_, _ = w.Write(header)
+	_, _ = w.Write(payload)
+	_ = w.Flush()
+}
+
+// newWSServer starts a WebSocket server that hands the upgraded connection to
+// handle. A request without an Authorization header is refused with a 403.
+func newWSServer(t *testing.T, handle func(c *wsConn, w *bufio.Writer)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("no token"))
+			return
+		}
+		if r.Header.Get("Upgrade") != "websocket" {
+			t.Errorf("handshake without Upgrade: websocket")
+		}
+		conn, brw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + wsAcceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = brw.Flush()
+		handle(&wsConn{rw: conn, br: brw.Reader}, brw.Writer)
+	}))
+}
+
+func newWSConfig(t *testing.T, srvURL string) *ffuf.Config {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf := ffuf.NewConfig(ctx, cancel)
+	conf.Timeout = 5
+	conf.WebSocket = true
+	conf.WSTimeout = 2000
+	conf.Url = "ws" + strings.TrimPrefix(srvURL, "http") + "/socket"
+	conf.Headers["Authorization"] = "Bearer FUZZ"
+	conf.Data = `{"action":"FUZZ"}`
+	return &conf
+}
+
+func TestWebSocketRunner_Exchange(t *testing.T) {
+	srv := newWSServer(t, func(c *wsConn, w *bufio.Writer) {
+		msg, err := c.readMessage()
+		if err != nil {
+			t.Errorf("server read: %v", err)
+			return
+		}
+		// A ping and a fragmented reply: the ping must be answered, and the
+		// fragments reassembled into one message.
+		writeServerFrame(w, true, wsOpPing, []byte("p"))
+		if _, opcode, payload, err := c.readFrame(); err != nil || opcode != wsOpPong || string(payload) != "p" {
+			t.Errorf("expected a pong, got opcode %#x payload %q err %v", opcode, payload, err)
+		}
+		writeServerFrame(w, false, wsOpText, []byte("echo "))
+		writeServerFrame(w, true, wsOpContinuation, msg)
+		writeServerFrame(w, true, wsOpText, []byte("never collected"))
+	})
+	defer srv.Close()
+
+	conf := newWSConfig(t, srv.URL)
+	conf.WSFrames = 1
+	r := NewWebSocketRunner(conf, false)
+	base := ffuf.BaseRequest(conf)
+	req, _ := r.Prepare(map[string][]byte{"FUZZ": []byte("ping")}, &base)
+	resp, err := r.Execute(&req)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if resp.StatusCode != 101 {
+		t.Errorf("status = %d, want 101", resp.StatusCode)
+	}
+	if want := `echo {"action":"ping"}`; string(resp.Data) != want {
+		t.Errorf("body = %q, want %q", resp.Data, want)
+	}
+	if resp.ContentLength != int64(len(resp.Data)) || resp.ContentWords != 2 || resp.ContentLines != 1 {
+		t.Errorf("size/words/lines = %d/%d/%d", resp.ContentLength, resp.ContentWords, resp.ContentLines)
+	}
+}
+
+// Without -ws-frames every message within -ws-timeout is collected, one per line.
+func TestWebSocketRunner_CollectUntilTimeout(t *testing.T) {
+	srv := newWSServer(t, func(c *wsConn, w *bufio.Writer) {
+		if _, err := c.readMessage(); err != nil {
+			return
+		}
+		writeServerFrame(w, true, wsOpText, []byte("first"))
+		writeServerFrame(w, true, wsOpBinary, []byte("second"))
+		// Hold the connection open until the client gives up
+		_, _ = c.readMessage()
+	})
+	defer srv.Close()
+
+	conf := newWSConfig(t, srv.URL)
+	conf.WSTimeout = 300
+	r := NewWebSocketRunner(conf, false)
+	base := ffuf.BaseRequest(conf)
+	req, _ := r.Prepare(map[string][]byte{"FUZZ": []byte("x")}, &base)
+	resp, err := r.Execute(&req)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if string(resp.Data) != "first\nsecond" || resp.ContentLines != 2 {
+		t.Errorf("body = %q (%d lines), want both messages", resp.Data, resp.ContentLines)
+	}
+}
+
+// A refused handshake is reported as the plain HTTP response.
+func TestWebSocketRunner_RefusedHandshake(t *testing.T) {
+	srv := newWSServer(t, func(c *wsConn, w *bufio.Writer) {})
+	defer srv.Close()
+
+	conf := newWSConfig(t, srv.URL)
+	delete(conf.Headers, "Authorization")
+	r := NewWebSocketRunner(conf, false)
+	base := ffuf.BaseRequest(conf)
+	req, _ := r.Prepare(map[string][]byte{"FUZZ": []byte("x")}, &base)
+	resp, err := r.Execute(&req)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if resp.StatusCode != 403 || string(resp.Data) != "no token" {
+		t.Errorf("got %d %q, want 403 \"no token\"", resp.StatusCode, resp.Data)
+	}
+}
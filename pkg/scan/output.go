@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/output"
+)
+
+// resultOutput is the OutputProvider of a scan: it sends the results to the
+// Results channel and the progress and messages to the Handlers, and keeps
+// nothing itself
+type resultOutput struct {
+	ctx      context.Context
+	results  chan<- ffuf.Result
+	handlers Handlers
+}
+
+func (o *resultOutput) Banner() {}
+
+func (o *resultOutput) Finalize() error {
+	return nil
+}
+
+func (o *resultOutput) Progress(status ffuf.Progress) {
+	if o.handlers.Progress != nil {
+		o.handlers.Progress(status)
+	}
+}
+
+func (o *resultOutput) message(level, message string) {
+	// The job warns with an empty message when it is stopped without a reason
+	if o.handlers.Message != nil && message != "" {
+		o.handlers.Message(level, message)
+	}
+}
+
+func (o *resultOutput) Info(infostring string) {
+	o.message("info", infostring)
+}
+
+func (o *resultOutput) Error(errstring string) {
+	o.message("error", errstring)
+}
+
+func (o *resultOutput) Warning(warnstring string) {
+	o.message("warning", warnstring)
+}
+
+func (o *resultOutput) Raw(output string) {}
+
+func (o *resultOutput) Result(resp ffuf.Response) {
+	res := output.NewResult(resp)
+	res.Response = &resp
+	select {
+	case o.results <- res:
+	case <-o.ctx.Done():
+		// Stopped, the program may not be receiving anymore
+	}
+}
+
+func (o *resultOutput) PrintResult(res ffuf.Result) {}
+
+func (o *resultOutput) SaveFile(filename, format string) error {
+	return fmt.Errorf("the results of a scan are not saved to files")
+}
+
+func (o *resultOutput) GetCurrentResults() []ffuf.Result {
+	return []ffuf.Result{}
+}
+
+func (o *resultOutput) SetCurrentResults(results []ffuf.Result) {}
+
+func (o *resultOutput) FilterCurrentResults(keep func(ffuf.Result) bool) {}
+
+func (o *resultOutput) SetPaused(paused bool) {}
+
+func (o *resultOutput) PendingResults() int {
+	return 0
+}
+
+func (o *resultOutput) Reset() {}
+
+func (o *resultOutput) Cycle() {}
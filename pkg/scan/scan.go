@@ -0,0 +1,149 @@
+// Package scan runs ffuf scans within Go programs. A scan is configured with the
+// same ffuf.ConfigOptions that the command line flags and the ffufrc file fill
+// in, and its matched results are received on a channel instead of being
+// printed. It needs no terminal and does not use the ffuf config directory: no
+// ffufrc is read, no FFUFHASH history is written and the scrapers are only read
+// from -scraperfile. The options that print or save the results (-o, -od, -or,
+// -json and the like) are not used, the results are the program's to keep.
+//
+//	opts := ffuf.NewConfigOptions()
+//	opts.HTTP.URL = "https://example.org/FUZZ"
+//	opts.Input.Wordlists = []string{"wordlist.txt"}
+//	results, err := scan.Run(ctx, opts)
+package scan
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/engine"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+)
+
+// resultBuffer is the number of results a scan can be ahead of the program
+// receiving them
+const resultBuffer = 64
+
+// Handlers are called by a scan as it runs, from its goroutines, so they should
+// return quickly. Any of them may be nil.
+type Handlers struct {
+	// Progress is called with the progress of the scan a few times a second
+	Progress func(ffuf.Progress)
+	// Message is called with the messages the ffuf command would print: level
+	// is "info", "warning" or "error"
+	Message func(level string, message string)
+}
+
+// Scan is a running scan
+type Scan struct {
+	// Results receives the matched results, with the Response of each set. It
+	// is closed when the scan has finished. The scan waits for its results to
+	// be received, so the channel must be drained.
+	Results <-chan ffuf.Result
+
+	parent context.Context
+	cancel context.CancelFunc
+	job    *engine.Job
+	done   chan struct{}
+	err    error
+}
+
+// Start validates the options like the ffuf command does, and starts a scan
+// with them. The scan is stopped when ctx is cancelled.
+func Start(ctx context.Context, opts *ffuf.ConfigOptions, handlers Handlers) (*Scan, error) {
+	scanCtx, cancel := context.WithCancel(ctx)
+	conf, err := ffuf.ConfigFromOptions(opts, scanCtx, cancel)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	conf.Noninteractive = true
+	conf.MatcherManager, err = filter.FromConfig(conf.Options, filter.DefaultStatusMatcher(conf.Options))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	job, err := assembly.BuildEmbeddedJob(conf)
+	if err != nil {
+		closeJob(job)
+		cancel()
+		return nil, err
+	}
+	results := make(chan ffuf.Result, resultBuffer)
+	job.Output = &resultOutput{ctx: scanCtx, results: results, handlers: handlers}
+	s := &Scan{
+		Results: results,
+		parent:  ctx,
+		cancel:  cancel,
+		job:     job,
+		done:    make(chan struct{}),
+	}
+	go s.run(results)
+	return s, nil
+}
+
+// Run runs a scan to the end, and returns its results. The error is that of
+// Wait, the results found until the scan was stopped are returned along with
+// it.
+func Run(ctx context.Context, opts *ffuf.ConfigOptions) ([]ffuf.Result, error) {
+	s, err := Start(ctx, opts, Handlers{})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ffuf.Result, 0)
+	for res := range s.Results {
+		results = append(results, res)
+	}
+	return results, s.Wait()
+}
+
+func (s *Scan) run(results chan ffuf.Result) {
+	defer close(s.done)
+	s.job.Start()
+	closeJob(s.job)
+	s.cancel()
+	if reason := s.job.StopReason(); reason != "" {
+		s.err = errors.New(reason)
+	} else if s.parent.Err() != nil {
+		s.err = s.parent.Err()
+	}
+	close(results)
+}
+
+// Stop stops the scan, the requests that were sent are waited for
+func (s *Scan) Stop() {
+	s.cancel()
+}
+
+// Wait waits for the scan to finish. The error is the reason the scan was
+// stopped early by -sf, -se, -sa or -maxtime, or the error of the context it
+// was started with. It is nil when the scan finished, or was stopped by Stop.
+func (s *Scan) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// Job returns the job of the scan, to control it like the interactive console
+// and the -api do
+func (s *Scan) Job() *engine.Job {
+	return s.job
+}
+
+// closeJob waits for the hooks of the job, stops its plugins and closes its
+// audit log
+func closeJob(job *engine.Job) {
+	if job == nil {
+		return
+	}
+	if job.Hooks != nil {
+		job.Hooks.Close()
+	}
+	if job.Plugins != nil {
+		job.Plugins.Close()
+	}
+	if job.AuditLogger != nil {
+		job.AuditLogger.Close()
+	}
+}
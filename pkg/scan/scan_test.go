@@ -0,0 +1,154 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// withoutConfigDir points the ffuf config directories at a directory that does
+// not exist, and fails the test if a scan creates it
+func withoutConfigDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ffuf")
+	origHistory, origScraper, origAutocalib := ffuf.HISTORYDIR, ffuf.SCRAPERDIR, ffuf.AUTOCALIBDIR
+	ffuf.HISTORYDIR = filepath.Join(dir, "history")
+	ffuf.SCRAPERDIR = filepath.Join(dir, "scraper")
+	ffuf.AUTOCALIBDIR = filepath.Join(dir, "autocalibration")
+	t.Cleanup(func() {
+		ffuf.HISTORYDIR, ffuf.SCRAPERDIR, ffuf.AUTOCALIBDIR = origHistory, origScraper, origAutocalib
+		if _, err := os.Stat(dir); err == nil {
+			t.Errorf("the scan created the config directory %s", dir)
+		}
+	})
+}
+
+func writeWordlist(t *testing.T, words []string) string {
+	path := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(words, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	withoutConfigDir(t)
+	target := testtarget.New()
+	defer target.Close()
+
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/size/FUZZ"
+	opts.Input.Wordlists = []string{writeWordlist(t, []string{"10", "20", "30"})}
+	opts.Filter.Size = "20"
+	results, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+	sizes := make([]string, 0)
+	for _, res := range results {
+		sizes = append(sizes, string(res.Input["FUZZ"]))
+		if res.Response == nil || int64(len(res.Response.Data)) != res.ContentLength {
+			t.Errorf("result %s has no response body", res.Url)
+		}
+	}
+	sort.Strings(sizes)
+	if strings.Join(sizes, ",") != "10,30" {
+		t.Errorf("got results %v, want 10 and 30", sizes)
+	}
+}
+
+func TestStartHandlers(t *testing.T) {
+	withoutConfigDir(t)
+	target := testtarget.New()
+	defer target.Close()
+
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/status/FUZZ"
+	opts.Input.Wordlists = []string{writeWordlist(t, []string{"200", "404"})}
+	opts.Matcher.Status = "404"
+	var progress int64
+	s, err := Start(context.Background(), opts, Handlers{
+		Progress: func(ffuf.Progress) { atomic.AddInt64(&progress, 1) },
+	})
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	count := 0
+	for res := range s.Results {
+		count++
+		if res.StatusCode != 404 {
+			t.Errorf("unexpected result %+v", res)
+		}
+	}
+	if err := s.Wait(); err != nil || count != 1 {
+		t.Errorf("got %d results and %v, want 1 result", count, err)
+	}
+	if atomic.LoadInt64(&progress) == 0 {
+		t.Errorf("the progress handler was not called")
+	}
+}
+
+func TestStartCancel(t *testing.T) {
+	withoutConfigDir(t)
+	target := testtarget.New()
+	defer target.Close()
+
+	words := make([]string, 1000)
+	for i := range words {
+		words[i] = "20"
+	}
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/sleep/FUZZ"
+	opts.Input.Wordlists = []string{writeWordlist(t, words)}
+	opts.General.Threads = 2
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := Start(ctx, opts, Handlers{})
+	if err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	<-s.Results
+	cancel()
+	count := 1
+	for range s.Results {
+		count++
+	}
+	if err := s.Wait(); err != context.Canceled {
+		t.Errorf("Wait returned %v, want %v", err, context.Canceled)
+	}
+	if count == len(words) {
+		t.Errorf("the scan was not stopped")
+	}
+}
+
+func TestStartStopOn403(t *testing.T) {
+	withoutConfigDir(t)
+	target := testtarget.New()
+	defer target.Close()
+
+	words := make([]string, 100)
+	for i := range words {
+		words[i] = "403"
+	}
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/status/FUZZ"
+	opts.Input.Wordlists = []string{writeWordlist(t, words)}
+	opts.General.StopOn403 = true
+	opts.General.Threads = 1
+	_, err := Run(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Run returned %v, want the -sf reason", err)
+	}
+}
+
+func TestStartInvalidOptions(t *testing.T) {
+	opts := ffuf.NewConfigOptions()
+	if _, err := Start(context.Background(), opts, Handlers{}); err == nil {
+		t.Errorf("expected an error for options without a URL")
+	}
+}
@@ -0,0 +1,67 @@
+package script
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// hashModule has the checksums of a string, as hex
+var hashModule = &starlarkstruct.Module{
+	Name: "hash",
+	Members: starlark.StringDict{
+		"md5":    hashBuiltin("md5", md5.New),
+		"sha1":   hashBuiltin("sha1", sha1.New),
+		"sha256": hashBuiltin("sha256", sha256.New),
+		"crc32":  hashBuiltin("crc32", func() hash.Hash { return crc32.NewIEEE() }),
+	},
+}
+
+// base64Module encodes and decodes standard base64
+var base64Module = &starlarkstruct.Module{
+	Name: "base64",
+	Members: starlark.StringDict{
+		"encode": starlark.NewBuiltin("base64.encode", base64Encode),
+		"decode": starlark.NewBuiltin("base64.decode", base64Decode),
+	},
+}
+
+func hashBuiltin(name string, newHash func() hash.Hash) *starlark.Builtin {
+	return starlark.NewBuiltin("hash."+name, func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var s string
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &s); err != nil {
+			return nil, err
+		}
+		h := newHash()
+		h.Write([]byte(s))
+		return starlark.String(hex.EncodeToString(h.Sum(nil))), nil
+	})
+}
+
+func base64Encode(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &s); err != nil {
+		return nil, err
+	}
+	return starlark.String(base64.StdEncoding.EncodeToString([]byte(s))), nil
+}
+
+func base64Decode(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &s); err != nil {
+		return nil, err
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", fn.Name(), err)
+	}
+	return starlark.String(b), nil
+}
@@ -0,0 +1,174 @@
+// Package script runs the Starlark script given with -script on the requests
+// and responses of the scan. The script defines on_request, on_response or both:
+//
+//	def on_request(req):
+//	    req["headers"]["X-Signature"] = hash.sha256(req["body"] + "secret")
+//
+//	def on_response(resp):
+//	    if resp["content_type"] != "application/json":
+//	        return None
+//	    doc = json.decode(resp["body"])
+//	    return {"match": doc.get("admin", False), "data": {"user": doc.get("user", "")}}
+//
+// on_request gets a prepared request as a dict of method, url, headers (name to
+// value), body and input (keyword to value), and modifies it in place or
+// returns the dict to send instead. Changes to the input are ignored.
+//
+// on_response gets a response as a dict of status, headers (name to list of
+// values), body, length, words, lines, content_type, duration (milliseconds),
+// url, input and the request. It returns None to leave the response to the
+// matchers and filters, True or False to match it or not whatever they say, or
+// a dict of:
+//
+//	match    True to match the response, False not to
+//	filter   True to filter the response out
+//	data     a dict of name to a string or list of strings, added to the
+//	         scraper data of a response that is not filtered out
+//
+// The script runs sandboxed: it has no access to files, the network or the
+// environment, and each call is limited to maxExecutionSteps. The json, hash
+// (md5, sha1, sha256 and crc32, as hex) and base64 (encode and decode) modules
+// are predeclared, print writes to the debug log.
+package script
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// maxExecutionSteps is the number of steps a call of a hook may run for before
+// it is stopped, so that a loop in the script cannot stall the scan
+const maxExecutionSteps = 10000000
+
+// Script is a loaded -script
+type Script struct {
+	filename   string
+	onRequest  starlark.Callable
+	onResponse starlark.Callable
+}
+
+// Load runs the script in filename, and returns it if it defines on_request or
+// on_response
+func Load(filename string) (*Script, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read -script: %s", err)
+	}
+	opts := &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, Recursion: true}
+	globals, err := starlark.ExecFileOptions(opts, newThread(filename), filename, src, predeclared())
+	if err != nil {
+		return nil, fmt.Errorf("-script: %s", scriptError(err))
+	}
+	s := &Script{filename: filename}
+	if s.onRequest, err = hook(globals, "on_request"); err != nil {
+		return nil, err
+	}
+	if s.onResponse, err = hook(globals, "on_response"); err != nil {
+		return nil, err
+	}
+	if s.onRequest == nil && s.onResponse == nil {
+		return nil, fmt.Errorf("-script %s defines neither on_request nor on_response", filename)
+	}
+	return s, nil
+}
+
+func hook(globals starlark.StringDict, name string) (starlark.Callable, error) {
+	v, ok := globals[name]
+	if !ok {
+		return nil, nil
+	}
+	fn, ok := v.(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("-script: %s is a %s, not a function", name, v.Type())
+	}
+	return fn, nil
+}
+
+func predeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"json":   starlarkjson.Module,
+		"hash":   hashModule,
+		"base64": base64Module,
+	}
+}
+
+func newThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name:  name,
+		Print: func(_ *starlark.Thread, msg string) { log.Printf("Script: %s", msg) },
+	}
+	thread.SetMaxExecutionSteps(maxExecutionSteps)
+	return thread
+}
+
+// scriptError prefixes an error of the script with the position in the script
+// it occurred at
+func scriptError(err error) string {
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		for i := 0; i < len(evalErr.CallStack); i++ {
+			if frame := evalErr.CallStack.At(i); frame.Pos.IsValid() && frame.Pos.Filename() != "<builtin>" {
+				return fmt.Sprintf("%s: %s", frame.Pos, evalErr.Msg)
+			}
+		}
+	}
+	return err.Error()
+}
+
+func (s *Script) call(fn starlark.Callable, arg starlark.Value) (starlark.Value, error) {
+	v, err := starlark.Call(newThread(s.filename), fn, starlark.Tuple{arg}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("-script: %s", scriptError(err))
+	}
+	return v, nil
+}
+
+// OnRequest lets on_request modify a prepared request
+func (s *Script) OnRequest(req *ffuf.Request) error {
+	if s.onRequest == nil {
+		return nil
+	}
+	dict := requestDict(req)
+	v, err := s.call(s.onRequest, dict)
+	if err != nil {
+		return err
+	}
+	if v != starlark.None {
+		returned, ok := v.(*starlark.Dict)
+		if !ok {
+			return fmt.Errorf("-script: on_request returned a %s, not a dict or None", v.Type())
+		}
+		dict = returned
+	}
+	return applyRequest(dict, req)
+}
+
+// OnResponse evaluates a response with on_response
+func (s *Script) OnResponse(resp *ffuf.Response) (ffuf.ScriptVerdict, error) {
+	if s.onResponse == nil {
+		return ffuf.ScriptUndecided, nil
+	}
+	v, err := s.call(s.onResponse, responseDict(resp))
+	if err != nil {
+		return ffuf.ScriptUndecided, err
+	}
+	verdict, data, err := parseVerdict(v)
+	if err != nil {
+		return ffuf.ScriptUndecided, err
+	}
+	if verdict != ffuf.ScriptFilter && len(data) > 0 {
+		if resp.ScraperData == nil {
+			resp.ScraperData = make(map[string][]string)
+		}
+		for name, values := range data {
+			resp.ScraperData[name] = values
+		}
+	}
+	return verdict, nil
+}
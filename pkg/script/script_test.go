@@ -0,0 +1,129 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+)
+
+func writeScript(t *testing.T, src string) string {
+	path := filepath.Join(t.TempDir(), "script.star")
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOnRequest(t *testing.T) {
+	s, err := Load(writeScript(t, `
+def on_request(req):
+    req["method"] = "POST"
+    req["headers"]["X-Sum"] = hash.md5(req["body"])
+    req["body"] = req["body"] + "&sig=" + base64.encode(req["input"]["FUZZ"])
+`))
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	req := ffuf.Request{
+		Method:  "GET",
+		Url:     "http://example.org/",
+		Headers: map[string]string{"Accept": "*/*"},
+		Data:    []byte("a=b"),
+		Input:   map[string][]byte{"FUZZ": []byte("admin")},
+	}
+	if err := s.OnRequest(&req); err != nil {
+		t.Fatalf("OnRequest: %s", err)
+	}
+	if req.Method != "POST" || req.Headers["X-Sum"] != "7acaac15494e6820b1ed6d8b539af089" || req.Headers["Accept"] != "*/*" {
+		t.Errorf("unexpected request %+v", req)
+	}
+	if string(req.Data) != "a=b&sig=YWRtaW4=" {
+		t.Errorf("unexpected body %q", req.Data)
+	}
+	if verdict, err := s.OnResponse(&ffuf.Response{}); err != nil || verdict != ffuf.ScriptUndecided {
+		t.Errorf("a script without on_response decided %v, %v", verdict, err)
+	}
+}
+
+func TestOnRequestReturned(t *testing.T) {
+	s, err := Load(writeScript(t, `
+def on_request(req):
+    return {"method": "PUT", "url": req["url"] + "?x=1", "headers": {}, "body": ""}
+`))
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	req := ffuf.Request{Method: "GET", Url: "http://example.org/", Headers: map[string]string{"Accept": "*/*"}}
+	if err := s.OnRequest(&req); err != nil {
+		t.Fatalf("OnRequest: %s", err)
+	}
+	if req.Method != "PUT" || req.Url != "http://example.org/?x=1" || len(req.Headers) != 0 {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
+
+func TestOnResponse(t *testing.T) {
+	s, err := Load(writeScript(t, `
+def on_response(resp):
+    if resp["status"] == 500:
+        return None
+    if resp["status"] == 404:
+        return False
+    doc = json.decode(resp["body"])
+    return {"match": doc["admin"], "data": {"user": doc["user"], "roles": doc["roles"]}}
+`))
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	resp := ffuf.Response{StatusCode: 200, Data: []byte(`{"admin": true, "user": "bob", "roles": ["a", "b"]}`)}
+	verdict, err := s.OnResponse(&resp)
+	if err != nil || verdict != ffuf.ScriptMatch {
+		t.Errorf("got %v, %v for an admin response, want a match", verdict, err)
+	}
+	if strings.Join(resp.ScraperData["user"], ",") != "bob" || strings.Join(resp.ScraperData["roles"], ",") != "a,b" {
+		t.Errorf("unexpected data %v", resp.ScraperData)
+	}
+	if verdict, _ := s.OnResponse(&ffuf.Response{StatusCode: 404}); verdict != ffuf.ScriptFilter {
+		t.Errorf("got %v for False, want the response filtered", verdict)
+	}
+	if verdict, _ := s.OnResponse(&ffuf.Response{StatusCode: 500}); verdict != ffuf.ScriptUndecided {
+		t.Errorf("got %v for None, want no decision", verdict)
+	}
+	_, err = s.OnResponse(&ffuf.Response{StatusCode: 200, Data: []byte("not json")})
+	if err == nil || !strings.Contains(err.Error(), "script.star:7") {
+		t.Errorf("expected an error at the line of json.decode, got %v", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := map[string]string{
+		"x = 1":                         "neither on_request nor on_response",
+		"on_request = 1":                "not a function",
+		"def on_request(req)\n    pass": "script.star:2",
+		"load('other.star', 'x')":       "load",
+		"def on_response(resp):\n    pass\nx = 1 // 0": "division by zero",
+	}
+	for src, want := range tests {
+		_, err := Load(writeScript(t, src))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load(%q) returned %v, want an error containing %q", src, err, want)
+		}
+	}
+}
+
+func TestExecutionLimit(t *testing.T) {
+	s, err := Load(writeScript(t, `
+def on_response(resp):
+    while True:
+        pass
+`))
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	if _, err := s.OnResponse(&ffuf.Response{}); err == nil || !strings.Contains(err.Error(), "too many steps") {
+		t.Errorf("expected the loop to be stopped, got %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+
+	"go.starlark.net/starlark"
+)
+
+// newDict returns a dict with the entries in the order of their keys
+func newDict(entries map[string]starlark.Value) *starlark.Dict {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	d := starlark.NewDict(len(entries))
+	for _, k := range keys {
+		_ = d.SetKey(starlark.String(k), entries[k])
+	}
+	return d
+}
+
+func inputDict(input map[string][]byte) *starlark.Dict {
+	entries := make(map[string]starlark.Value, len(input))
+	for k, v := range input {
+		entries[k] = starlark.String(v)
+	}
+	return newDict(entries)
+}
+
+func requestDict(req *ffuf.Request) *starlark.Dict {
+	headers := make(map[string]starlark.Value, len(req.Headers))
+	for k, v := range req.Headers {
+		headers[k] = starlark.String(v)
+	}
+	return newDict(map[string]starlark.Value{
+		"method":  starlark.String(req.Method),
+		"url":     starlark.String(req.Url),
+		"headers": newDict(headers),
+		"body":    starlark.String(req.Data),
+		"input":   inputDict(req.Input),
+	})
+}
+
+func responseDict(resp *ffuf.Response) *starlark.Dict {
+	headers := make(map[string]starlark.Value, len(resp.Headers))
+	for k, values := range resp.Headers {
+		list := make([]starlark.Value, 0, len(values))
+		for _, v := range values {
+			list = append(list, starlark.String(v))
+		}
+		headers[k] = starlark.NewList(list)
+	}
+	entries := map[string]starlark.Value{
+		"status":       starlark.MakeInt64(resp.StatusCode),
+		"headers":      newDict(headers),
+		"body":         starlark.String(resp.Data),
+		"length":       starlark.MakeInt64(resp.ContentLength),
+		"words":        starlark.MakeInt64(resp.ContentWords),
+		"lines":        starlark.MakeInt64(resp.ContentLines),
+		"content_type": starlark.String(resp.ContentType),
+		"duration":     starlark.MakeInt64(resp.Duration.Milliseconds()),
+		"url":          starlark.String(""),
+		"input":        newDict(nil),
+		"request":      starlark.None,
+	}
+	if resp.Request != nil {
+		req := requestDict(resp.Request)
+		req.Freeze()
+		entries["url"] = starlark.String(resp.Request.Url)
+		entries["input"] = inputDict(resp.Request.Input)
+		entries["request"] = req
+	}
+	return newDict(entries)
+}
+
+// applyRequest changes req to the request dict of on_request
+func applyRequest(d *starlark.Dict, req *ffuf.Request) error {
+	method, err := stringField(d, "method")
+	if err != nil {
+		return err
+	}
+	url, err := stringField(d, "url")
+	if err != nil {
+		return err
+	}
+	body, err := stringField(d, "body")
+	if err != nil {
+		return err
+	}
+	v, _, _ := d.Get(starlark.String("headers"))
+	headerDict, ok := v.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("-script: the headers of the request are not a dict")
+	}
+	headers := make(map[string]string, headerDict.Len())
+	for _, item := range headerDict.Items() {
+		k, kok := starlark.AsString(item[0])
+		v, vok := starlark.AsString(item[1])
+		if !kok || !vok {
+			return fmt.Errorf("-script: the header %s of the request is not a string", item[0])
+		}
+		headers[k] = v
+	}
+	req.Method = method
+	req.Url = url
+	req.Headers = headers
+	req.Data = []byte(body)
+	return nil
+}
+
+func stringField(d *starlark.Dict, name string) (string, error) {
+	v, found, _ := d.Get(starlark.String(name))
+	if !found {
+		return "", fmt.Errorf("-script: the request has no %s", name)
+	}
+	s, ok := starlark.AsString(v)
+	if !ok {
+		return "", fmt.Errorf("-script: the %s of the request is a %s, not a string", name, v.Type())
+	}
+	return s, nil
+}
+
+// parseVerdict reads the value on_response returned
+func parseVerdict(v starlark.Value) (ffuf.ScriptVerdict, map[string][]string, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return ffuf.ScriptUndecided, nil, nil
+	case starlark.Bool:
+		return boolVerdict(v), nil, nil
+	case *starlark.Dict:
+		verdict := ffuf.ScriptUndecided
+		filtered := false
+		var data map[string][]string
+		for _, item := range v.Items() {
+			key, _ := starlark.AsString(item[0])
+			switch key {
+			case "match":
+				b, ok := item[1].(starlark.Bool)
+				if !ok {
+					return ffuf.ScriptUndecided, nil, fmt.Errorf("-script: the match of on_response is a %s, not a bool", item[1].Type())
+				}
+				verdict = boolVerdict(b)
+			case "filter":
+				b, ok := item[1].(starlark.Bool)
+				if !ok {
+					return ffuf.ScriptUndecided, nil, fmt.Errorf("-script: the filter of on_response is a %s, not a bool", item[1].Type())
+				}
+				filtered = bool(b)
+			case "data":
+				var err error
+				if data, err = parseData(item[1]); err != nil {
+					return ffuf.ScriptUndecided, nil, err
+				}
+			default:
+				return ffuf.ScriptUndecided, nil, fmt.Errorf("-script: unknown key %s returned by on_response, expected match, filter or data", item[0])
+			}
+		}
+		if filtered {
+			verdict = ffuf.ScriptFilter
+		}
+		return verdict, data, nil
+	}
+	return ffuf.ScriptUndecided, nil, fmt.Errorf("-script: on_response returned a %s, not None, a bool or a dict", v.Type())
+}
+
+func boolVerdict(b starlark.Bool) ffuf.ScriptVerdict {
+	if b {
+		return ffuf.ScriptMatch
+	}
+	return ffuf.ScriptFilter
+}
+
+func parseData(v starlark.Value) (map[string][]string, error) {
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("-script: the data of on_response is a %s, not a dict", v.Type())
+	}
+	data := make(map[string][]string, d.Len())
+	for _, item := range d.Items() {
+		name, ok := starlark.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("-script: the data name %s is not a string", item[0])
+		}
+		if s, ok := starlark.AsString(item[1]); ok {
+			data[name] = []string{s}
+			continue
+		}
+		iterable, ok := item[1].(starlark.Iterable)
+		if !ok {
+			return nil, fmt.Errorf("-script: the data %s is a %s, not a string or a list of strings", name, item[1].Type())
+		}
+		values := make([]string, 0)
+		iter := iterable.Iterate()
+		var elem starlark.Value
+		for iter.Next(&elem) {
+			s, ok := starlark.AsString(elem)
+			if !ok {
+				iter.Done()
+				return nil, fmt.Errorf("-script: the data %s has a %s, not a string", name, elem.Type())
+			}
+			values = append(values, s)
+		}
+		iter.Done()
+		data[name] = values
+	}
+	return data, nil
+}
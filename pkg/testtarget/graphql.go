@@ -0,0 +1,84 @@
+package testtarget
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// graphqlUsers are the names the user field resolves; any other name is a
+// "user not found" error on that field.
+var graphqlUsers = map[string]int{"admin": 1, "alice": 2}
+
+// graphqlFields are the fields of the Query type. An unknown field is a
+// validation error that suggests the fields sharing its first two letters, the
+// way a real server's "Did you mean" hint does.
+var graphqlFields = []string{"user", "version"}
+
+// graphqlField matches one top-level field of the selection set: an optional
+// alias, the field name, an optional name argument and an optional sub-selection.
+var graphqlField = regexp.MustCompile(`([_A-Za-z]\w*)\s*(?::\s*([_A-Za-z]\w*))?\s*(?:\(\s*name\s*:\s*"([^"]*)"\s*\))?\s*(?:\{[^{}]*\})?`)
+
+// graphql is a tiny GraphQL endpoint. It understands only a flat selection set
+// of the fields above, which is enough to exercise batching (aliased copies of
+// the user field, each resolved or errored on its own) and suggestion harvesting.
+func graphql(w http.ResponseWriter, body string) {
+	var req struct {
+		Query string `json:"query"`
+	}
+	open, end := -1, -1
+	if err := json.Unmarshal([]byte(body), &req); err == nil {
+		open, end = strings.Index(req.Query, "{"), strings.LastIndex(req.Query, "}")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if open == -1 || end < open {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errors":[{"message":"Syntax Error"}]}`)
+		return
+	}
+
+	data := map[string]interface{}{}
+	errs := make([]map[string]interface{}, 0)
+	valid := true
+	for _, m := range graphqlField.FindAllStringSubmatch(req.Query[open+1:end], -1) {
+		key, field := m[1], m[1]
+		if m[2] != "" {
+			field = m[2]
+		}
+		switch field {
+		case "version":
+			data[key] = "1.0"
+		case "user":
+			if id, ok := graphqlUsers[m[3]]; ok {
+				data[key] = map[string]int{"id": id}
+			} else {
+				data[key] = nil
+				errs = append(errs, map[string]interface{}{"message": "user not found", "path": []string{key}})
+			}
+		default:
+			valid = false
+			msg := fmt.Sprintf("Cannot query field %q on type \"Query\".", field)
+			var similar []string
+			for _, known := range graphqlFields {
+				if len(field) >= 2 && strings.HasPrefix(known, field[:2]) {
+					similar = append(similar, fmt.Sprintf("%q", known))
+				}
+			}
+			if len(similar) > 0 {
+				msg += " Did you mean " + strings.Join(similar, " or ") + "?"
+			}
+			errs = append(errs, map[string]interface{}{"message": msg})
+		}
+	}
+
+	resp := map[string]interface{}{}
+	if valid {
+		resp["data"] = data
+	}
+	if len(errs) > 0 {
+		resp["errors"] = errs
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
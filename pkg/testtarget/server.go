@@ -1,8 +1,8 @@
 // Package testtarget provides a deterministic mock HTTP target for ffuf
 // integration tests. It models the response dimensions ffuf matches and filters
 // on (status code, body size, word count, line count, latency, reflection,
-// soft-404 baselines, redirects, request-gated endpoints, and a nested
-// directory tree for recursion) and records every request it receives.
+// soft-404 baselines, redirects, request-gated endpoints, and nested
+// directory trees for recursion) and records every request it receives.
 //
 // The server is hermetic: it binds to an ephemeral localhost port via
 // httptest, uses no randomness, and returns byte-identical responses for
@@ -13,9 +13,15 @@
 package testtarget
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,6 +32,7 @@ import (
 // test can assert on what ffuf actually sent (method, headers, body, timing).
 type Recorded struct {
 	Method string
+	Host   string
 	Path   string
 	Query  string
 	Header http.Header
@@ -67,7 +74,7 @@ func (t *Target) handle(w http.ResponseWriter, r *http.Request) {
 	body := drain(r)
 	t.mu.Lock()
 	t.recorded = append(t.recorded, Recorded{
-		Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery,
+		Method: r.Method, Host: r.Host, Path: r.URL.Path, Query: r.URL.RawQuery,
 		Header: r.Header.Clone(), Body: body, At: time.Now(),
 	})
 	t.mu.Unlock()
@@ -199,6 +206,77 @@ func (t *Target) handle(w http.ResponseWriter, r *http.Request) {
 	case p == "/rdir/found":
 		fmt.Fprint(w, "found under rdir")
 		return
+
+	// --- content-based directories (content recursion strategy) ---------
+	// A directory listing, a directory served with a 200 and no redirect, a
+	// page that is not one, and a file, which the content recursion strategy
+	// tells apart by their contents and names.
+	case p == "/listing":
+		fmt.Fprint(w, `<html><head><title>Index of /listing</title></head><body><a href="deep">deep</a></body></html>`)
+		return
+	case p == "/listing/deep":
+		fmt.Fprint(w, "deep in the listing")
+		return
+	case p == "/app":
+		fmt.Fprint(w, `<a href="login">log in</a>`)
+		return
+	case p == "/app/login":
+		fmt.Fprint(w, "app login")
+		return
+	case p == "/plain":
+		fmt.Fprint(w, "a plain page")
+		return
+	case p == "/app.js":
+		fmt.Fprint(w, "var app;")
+		return
+
+	// --- API versions (recursion templates) -------------------------------
+	// An API with the fuzzed segment in the middle of the path, which only a
+	// -recursion-template can recurse into.
+	case p == "/api/v1/users":
+		fmt.Fprint(w, "v1 users")
+		return
+	case p == "/api/v1/admin/users":
+		fmt.Fprint(w, "v1 admin users")
+		return
+
+	// --- virtual hosts (recursion templates) ------------------------------
+	// Virtual hosts under example.test, told apart by the Host header only.
+	case p == "/vhost":
+		switch r.Host {
+		case "dev.example.test", "admin.dev.example.test":
+			fmt.Fprintf(w, "vhost %s", r.Host)
+		default:
+			notFound(w)
+		}
+		return
+
+	// --- GraphQL ---------------------------------------------------------
+	case p == "/graphql":
+		graphql(w, body)
+		return
+
+	// --- multipart upload --------------------------------------------------
+	// Stores a file part only if its filename has an image extension and its
+	// Content-Type is an image type, the way a naive upload filter does.
+	case p == "/upload":
+		upload(w, r, body)
+		return
+
+	// --- hidden parameters ------------------------------------------------
+	// "debug" changes the body and "admin" the status, in the query, a form
+	// or JSON body or as a header. Any other parameter is ignored.
+	case p == "/params":
+		names := hiddenParams(r, body)
+		switch {
+		case names["admin"]:
+			forbidden(w)
+		case names["debug"]:
+			fmt.Fprint(w, "params page, debug output enabled")
+		default:
+			fmt.Fprint(w, "params page")
+		}
+		return
 	}
 
 	notFound(w)
@@ -282,3 +360,57 @@ func drain(r *http.Request) string {
 	}
 	return string(buf)
 }
+
+// hiddenParams returns the parameter names of r: query and form parameters, the
+// members of a JSON object body and the header names, lowercased.
+func hiddenParams(r *http.Request, body string) map[string]bool {
+	names := make(map[string]bool)
+	for name := range r.URL.Query() {
+		names[name] = true
+	}
+	if form, err := url.ParseQuery(body); err == nil && !strings.HasPrefix(body, "{") {
+		for name := range form {
+			names[name] = true
+		}
+	}
+	var members map[string]interface{}
+	if json.Unmarshal([]byte(body), &members) == nil {
+		for name := range members {
+			names[name] = true
+		}
+	}
+	for name := range r.Header {
+		names[strings.ToLower(name)] = true
+	}
+	return names
+}
+
+func upload(w http.ResponseWriter, r *http.Request, body string) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "not a multipart body")
+		return
+	}
+	mr := multipart.NewReader(strings.NewReader(body), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FileName() == "" {
+			continue
+		}
+		content, _ := io.ReadAll(part)
+		ext := strings.ToLower(path.Ext(part.FileName()))
+		if (ext != ".png" && ext != ".jpg") || !strings.HasPrefix(part.Header.Get("Content-Type"), "image/") {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			fmt.Fprint(w, "type not allowed")
+			return
+		}
+		fmt.Fprintf(w, "stored %s (%d bytes)", part.FileName(), len(content))
+		return
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	fmt.Fprint(w, "no file")
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/output"
+)
+
+// runResults implements "ffuf results query": it searches the results of every
+// run stored in a -results-db and prints the selection, or writes it to any of
+// the output formats.
+func runResults(args []string) int {
+	if len(args) == 0 || args[0] != "query" {
+		fmt.Fprintf(os.Stderr, "Usage: ffuf results query [options]\n")
+		return 2
+	}
+	fs := flag.NewFlagSet("ffuf results query", flag.ContinueOnError)
+	db := fs.String("db", defaultResultsDB(), "Results database file, defaults to the -results-db of the ffufrc")
+	run := fs.String("run", "", "Only the results of the runs whose FFUFHASH history hash starts with this")
+	host := fs.String("host", "", "Host of the results, * matches any characters. For example: *.example.org")
+	path := fs.String("path", "", "URL path of the results, * matches any characters. For example: /admin*")
+	status := fs.String("status", "", "HTTP status codes of the results. Comma separated list of codes and ranges")
+	size := fs.String("size", "", "Response sizes of the results. Comma separated list of sizes and ranges")
+	scraper := fs.String("scraper", "", "Regexp the scraper data of the results matches, as \"name=value\"")
+	since := fs.String("since", "", "Only the results found from this date on, as 2006-01-02 or RFC 3339")
+	until := fs.String("until", "", "Only the results found until this date, as 2006-01-02 or RFC 3339")
+	outputFile := fs.String("o", "", "Write the results to a file")
+	outputFormat := fs.String("of", "json", "Output file format. Available formats: json, ejson, html, md, csv, ecsv, har (or, 'all' for all formats)")
+	jsonOut := fs.Bool("json", false, "Print the results as newline-delimited JSON records")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ffuf results query [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Search the results stored in a -results-db across runs.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	query, err := resultsQuery(*run, *host, *path, *status, *size, *scraper, *since, *until)
+	if err == nil && *db == "" {
+		err = fmt.Errorf("no results database, use -db")
+	}
+	if err == nil && *outputFile != "" && !output.ValidFormat(*outputFormat) {
+		err = fmt.Errorf("Unknown output file format (-of): %s", *outputFormat)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+
+	var selected []ffuf.Result
+	err = output.NewResultsDB(*db).Query(query, func(res output.StoredResult) error {
+		selected = append(selected, res.Result)
+		if *outputFile != "" {
+			return nil
+		}
+		if *jsonOut {
+			line, err := json.Marshal(res)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(line))
+			return nil
+		}
+		runhash := res.Run
+		if len(runhash) > 5 {
+			runhash = runhash[:5]
+		}
+		fmt.Printf("%s %-5s %s [Status: %d, Size: %d, Words: %d, Lines: %d]\n",
+			res.Time.Format("2006-01-02 15:04:05"), runhash, res.Result.Url,
+			res.Result.StatusCode, res.Result.ContentLength, res.Result.ContentWords, res.Result.ContentLines)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	if *outputFile != "" {
+		if err = exportResults(*outputFile, *outputFormat, selected); err != nil {
+			fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func resultsQuery(run, host, path, status, size, scraper, since, until string) (output.ResultsQuery, error) {
+	q := output.ResultsQuery{Run: run, Host: host, Path: path}
+	var err error
+	if q.Status, err = parseRanges(status); err != nil {
+		return q, fmt.Errorf("bad -status: %s", err)
+	}
+	if q.Size, err = parseRanges(size); err != nil {
+		return q, fmt.Errorf("bad -size: %s", err)
+	}
+	if scraper != "" {
+		if q.Scraper, err = regexp.Compile(scraper); err != nil {
+			return q, fmt.Errorf("bad -scraper: %s", err)
+		}
+	}
+	if since != "" {
+		if q.Since, _, err = parseDate(since); err != nil {
+			return q, fmt.Errorf("bad -since: %s", err)
+		}
+	}
+	if until != "" {
+		var dateOnly bool
+		if q.Until, dateOnly, err = parseDate(until); err != nil {
+			return q, fmt.Errorf("bad -until: %s", err)
+		}
+		if dateOnly {
+			// Until the end of the day
+			q.Until = q.Until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+	return q, nil
+}
+
+func parseRanges(list string) ([]ffuf.ValueRange, error) {
+	var ranges []ffuf.ValueRange
+	for _, value := range strings.Split(list, ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		r, err := ffuf.ValueRangeFromString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// parseDate parses a date in local time or an RFC 3339 timestamp, and reports
+// which one it was
+func parseDate(date string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, date)
+	return t, false, err
+}
+
+// exportResults writes results of several runs to an output file
+func exportResults(filename, format string, results []ffuf.Result) error {
+	out := resultsOutput(results, func(conf *ffuf.Config) {
+		conf.OutputFile = filename
+		conf.OutputFormat = format
+	})
+	return out.SaveFile(filename, format)
+}
+
+// resultsOutput returns a stdout output provider holding results read back from
+// output files or a results database, with the keyword columns of all of them.
+func resultsOutput(results []ffuf.Result, configure func(conf *ffuf.Config)) *output.Stdoutput {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.CommandLine = strings.Join(os.Args, " ")
+	keywords := make(map[string]bool)
+	for _, res := range results {
+		for keyword := range res.Input {
+			if keyword != "FFUFHASH" {
+				keywords[keyword] = true
+			}
+		}
+	}
+	for keyword := range keywords {
+		conf.InputProviders = append(conf.InputProviders, ffuf.InputProviderConfig{Name: "wordlist", Keyword: keyword})
+	}
+	sort.Slice(conf.InputProviders, func(i, j int) bool {
+		return conf.InputProviders[i].Keyword < conf.InputProviders[j].Keyword
+	})
+	configure(&conf)
+	out := output.NewStdoutput(&conf)
+	out.SetCurrentResults(results)
+	return out
+}
+
+// defaultResultsDB returns the -results-db of the default configuration file
+func defaultResultsDB() string {
+	opts, err := ffuf.ReadDefaultConfig()
+	if err != nil {
+		return ""
+	}
+	return opts.Output.ResultsDB
+}
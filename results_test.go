@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// scanToResultsDB runs a scan of /status/FUZZ matching status 200 and 403 that
+// stores its results to db
+func scanToResultsDB(t *testing.T, url, db string, statuses []string) {
+	t.Helper()
+	wordlist := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(wordlist, []byte(strings.Join(statuses, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = url + "/status/FUZZ"
+	opts.Input.Wordlists = []string{wordlist}
+	opts.Output.ResultsDB = db
+	opts.General.Quiet = true
+	opts.General.Noninteractive = true
+	opts.Matcher.Status = "200,403"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	if conf.MatcherManager, err = filter.FromConfig(opts, true); err != nil {
+		t.Fatal(err)
+	}
+	job, err := assembly.BuildJob(conf)
+	if err != nil {
+		t.Fatalf("BuildJob: %v", err)
+	}
+	captureStdout(job.Start)
+}
+
+func TestResultsQuery(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	db := filepath.Join(t.TempDir(), "results.db")
+	scanToResultsDB(t, target.URL, db, []string{"200", "404"})
+	scanToResultsDB(t, target.URL, db, []string{"403", "500"})
+
+	var code int
+	out := captureStdout(func() {
+		code = runResults([]string{"query", "-db", db, "-status", "200-299,403"})
+	})
+	if code != 0 {
+		t.Fatalf("query exited with %d", code)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.Contains(lines[0], "/status/200") || !strings.Contains(lines[1], "/status/403") {
+		t.Errorf("unexpected query output %q", out)
+	}
+
+	exported := filepath.Join(t.TempDir(), "out.json")
+	captureStdout(func() {
+		code = runResults([]string{"query", "-db", db, "-path", "/status/4*", "-o", exported})
+	})
+	if code != 0 {
+		t.Fatalf("query exited with %d", code)
+	}
+	if got := readJSONInputs(t, exported); strings.Join(got, ",") != "403" {
+		t.Errorf("exported results = %v, want [403]", got)
+	}
+
+	if code := runResults([]string{"query", "-db", db, "-status", "abc"}); code == 0 {
+		t.Errorf("expected a bad -status to fail")
+	}
+	if code := runResults([]string{"list"}); code == 0 {
+		t.Errorf("expected an unknown results command to fail")
+	}
+}
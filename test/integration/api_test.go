@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/interactive"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestControlAPI runs a scan with the -api control API, streams its events and
+// refilters and saves its results through the API.
+func TestControlAPI(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/status/FUZZ"
+	opts.HTTP.Method = "GET"
+	opts.Input.Wordlists = []string{writeWordlist(t, []string{"200", "201", "404"})}
+	opts.General.Quiet = true
+	opts.Output.OutputDirectory = t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	conf.MatcherManager = filter.NewMatcherManager()
+	mustMatch(t, conf.MatcherManager, "status", "200,201")
+	job, err := assembly.BuildJob(conf)
+	if err != nil {
+		t.Fatalf("BuildJob: %v", err)
+	}
+	server, err := interactive.ServeAPI(job, "127.0.0.1:0", "secret")
+	if err != nil {
+		t.Fatalf("ServeAPI: %v", err)
+	}
+	defer server.Close()
+	base := "http://" + server.Addr()
+
+	call := func(method, path, token, body string) (int, map[string]interface{}) {
+		t.Helper()
+		req, err := http.NewRequest(method, base+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		var doc map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&doc)
+		return resp.StatusCode, doc
+	}
+
+	if status, _ := call("GET", "/status", "wrong", ""); status != http.StatusUnauthorized {
+		t.Errorf("expected a wrong token to be refused, got %d", status)
+	}
+
+	stream, err := http.Get(base + "/events?token=secret")
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	defer stream.Body.Close()
+	job.Start()
+
+	var events []string
+	scanner := bufio.NewScanner(stream.Body)
+	for scanner.Scan() {
+		if name := strings.TrimPrefix(scanner.Text(), "event: "); name != scanner.Text() {
+			events = append(events, name)
+		}
+		// The result events are the results of the output, with their -od files
+		if data := strings.TrimPrefix(scanner.Text(), "data: "); data != scanner.Text() && events[len(events)-1] == "result" {
+			var res struct {
+				ResultFile string `json:"resultfile"`
+			}
+			if err := json.Unmarshal([]byte(data), &res); err != nil || res.ResultFile == "" {
+				t.Errorf("result event without its -od file: %s", data)
+			}
+		}
+	}
+	var results int
+	for _, name := range events {
+		if name == "result" {
+			results++
+		}
+	}
+	if results != 2 || events[len(events)-1] != "finished" {
+		t.Errorf("expected 2 result events and a finished one last, got %v", events)
+	}
+
+	if status, doc := call("PUT", "/filters/status", "secret", `{"value": "201"}`); status != http.StatusOK || doc["filters"].(map[string]interface{})["status"] != "201" {
+		t.Errorf("unexpected filter response %d %v", status, doc)
+	}
+	if status, _ := call("PUT", "/filters/nosuchfilter", "secret", `{"value": "1"}`); status != http.StatusBadRequest {
+		t.Errorf("expected an unknown filter to be refused, got %d", status)
+	}
+	if _, doc := call("GET", "/results", "secret", ""); len(doc["results"].([]interface{})) != 1 {
+		t.Errorf("expected the 201 result to be filtered out, got %v", doc["results"])
+	}
+	if status, doc := call("PUT", "/rate", "secret", `{"rate": 5}`); status != http.StatusOK || doc["rate"] != float64(5) {
+		t.Errorf("unexpected rate response %d %v", status, doc)
+	}
+
+	saved := filepath.Join(t.TempDir(), "saved.json")
+	if status, doc := call("POST", "/save", "secret", `{"filename": "`+saved+`"}`); status != http.StatusOK {
+		t.Fatalf("save failed: %d %v", status, doc)
+	}
+	if b, err := os.ReadFile(saved); err != nil || !strings.Contains(string(b), `"status":200`) {
+		t.Errorf("expected the saved results, got %s %v", b, err)
+	}
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// auditLogTypes returns the Type of every line of an audit log
+func auditLogTypes(t *testing.T, filename string) []string {
+	t.Helper()
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+	var types []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		var line struct{ Type string }
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("audit log line is not JSON: %v", err)
+		}
+		types = append(types, line.Type)
+	}
+	return types
+}
+
+// TestAuditLogScan runs a scan with -audit-log, which dumps every request and
+// response, and checks each exchange is logged after the config.
+func TestAuditLogScan(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	auditlog := filepath.Join(t.TempDir(), "audit.log")
+	got := runScan(t, target.URL+"/size/FUZZ", []string{"5", "6"},
+		func(o *ffuf.ConfigOptions) { o.Output.AuditLog = auditlog },
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "all") },
+	)
+	assertSet(t, got, []string{"5", "6"})
+
+	types := auditLogTypes(t, auditlog)
+	counts := make(map[string]int)
+	for _, typ := range types {
+		counts[typ]++
+	}
+	if len(types) == 0 || types[0] != "*ffuf.Config" || counts["*ffuf.Request"] != 2 || counts["*ffuf.Response"] != 2 {
+		t.Errorf("unexpected audit log entries %v", types)
+	}
+}
+
+// TestAuditLogOnlyMatched checks -audit-log-only matched leaves the exchanges
+// that are filtered out of the log, and the job header carries the FFUFHASH
+// history hash.
+func TestAuditLogOnlyMatched(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	auditlog := filepath.Join(t.TempDir(), "audit.log")
+	got := runScan(t, target.URL+"/status/FUZZ", []string{"200", "404", "500"},
+		func(o *ffuf.ConfigOptions) {
+			o.Output.AuditLog = auditlog
+			o.Output.AuditLogOnly = []string{"matched"}
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "200") },
+	)
+	assertSet(t, got, []string{"200"})
+
+	types := auditLogTypes(t, auditlog)
+	want := []string{"*ffuf.Config", "*ffuf.AuditHeader", "*ffuf.Request", "*ffuf.Response"}
+	if strings.Join(types, " ") != strings.Join(want, " ") {
+		t.Errorf("audit log entries = %v, want %v", types, want)
+	}
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestBaselineOutputsDifferences runs a scan against the results of an earlier
+// one with -baseline, and checks only the new and gone results are written.
+func TestBaselineOutputsDifferences(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	dir := t.TempDir()
+	baseline := filepath.Join(dir, "old.json")
+	matchAll := func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "all") }
+
+	old := runScanStdout(t, target.URL+"/size/FUZZ", []string{"10", "20"}, nil, matchAll)
+	if err := old.SaveFile(baseline, "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	newfile := filepath.Join(dir, "new.json")
+	runScanStdout(t, target.URL+"/size/FUZZ", []string{"30", "20"}, func(o *ffuf.ConfigOptions) {
+		o.Output.Baseline = baseline
+		o.Output.OutputFile = newfile
+		o.Output.OutputFormat = "json"
+	}, matchAll)
+
+	var changes []string
+	for _, r := range readJSONResults(t, newfile) {
+		changes = append(changes, r.Input["FUZZ"]+":"+r.Diff.Change)
+	}
+	sort.Strings(changes)
+	if strings.Join(changes, " ") != "10:gone 30:new" {
+		t.Errorf("unexpected differences %v, want 10:gone 30:new", changes)
+	}
+}
+
+type diffResult struct {
+	Input map[string]string `json:"input"`
+	Diff  *ffuf.ResultDiff  `json:"diff"`
+}
+
+func readJSONResults(t *testing.T, filename string) []diffResult {
+	t.Helper()
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var doc struct {
+		Results []diffResult `json:"results"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	for _, r := range doc.Results {
+		if r.Diff == nil {
+			t.Fatalf("result %v has no diff", r.Input)
+		}
+	}
+	return doc.Results
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestClusterOutput checks -cluster writes every result with a clusters
+// section grouping the near-identical ones.
+func TestClusterOutput(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	outfile := filepath.Join(t.TempDir(), "out.json")
+
+	runScanStdout(t, target.URL+"/size/FUZZ", []string{"100", "104", "500", "108"}, func(o *ffuf.ConfigOptions) {
+		o.Output.Cluster = true
+		o.Output.OutputFile = outfile
+		o.Output.OutputFormat = "json"
+	}, func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "all") })
+
+	b, err := os.ReadFile(outfile)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var doc struct {
+		Results []struct {
+			Input map[string]string `json:"input"`
+		} `json:"results"`
+		Clusters []struct {
+			Count          int   `json:"count"`
+			Representative int   `json:"representative"`
+			Members        []int `json:"members"`
+		} `json:"clusters"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(doc.Results) != 4 {
+		t.Fatalf("expected all 4 results, got %d", len(doc.Results))
+	}
+	var clusters [][]string
+	for _, c := range doc.Clusters {
+		if c.Count != len(c.Members) || c.Representative != c.Members[0] {
+			t.Errorf("inconsistent cluster %+v", c)
+		}
+		var words []string
+		for _, i := range c.Members {
+			words = append(words, doc.Results[i].Input["FUZZ"])
+		}
+		sort.Strings(words)
+		clusters = append(clusters, words)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i][0] < clusters[j][0] })
+	want := [][]string{{"100", "104", "108"}, {"500"}}
+	if !reflect.DeepEqual(clusters, want) {
+		t.Errorf("unexpected clusters %v, want %v", clusters, want)
+	}
+}
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestGraphQLBatch sends three inputs per request as aliased copies of the query.
+// The response is split back per input, so the data path matcher sees each
+// input's own field and only the names that resolve match.
+func TestGraphQLBatch(t *testing.T) {
+	tt := testtarget.New()
+	defer tt.Close()
+
+	got := runScan(t, tt.URL+"/graphql", []string{"admin", "bob", "alice", "eve"},
+		func(o *ffuf.ConfigOptions) {
+			o.HTTP.GraphQL = `query { user(name: "FUZZ") { id } }`
+			o.HTTP.GraphQLBatch = 3
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "gqldata", "user.id") })
+	assertSet(t, got, []string{"admin", "alice"})
+
+	if n := tt.Count(); n != 2 {
+		t.Errorf("target received %d requests, want 2 batches", n)
+	}
+	for _, r := range tt.Requests() {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("batch sent as %s with Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+	}
+}
+
+// TestGraphQLErrorMatchers matches on the GraphQL errors of a 200 response
+// rather than on the status code.
+func TestGraphQLErrorMatchers(t *testing.T) {
+	tt := testtarget.New()
+	defer tt.Close()
+
+	configure := func(o *ffuf.ConfigOptions) { o.HTTP.GraphQL = `{ user(name: "FUZZ") { id } }` }
+	words := []string{"admin", "bob", "eve"}
+	got := runScan(t, tt.URL+"/graphql", words, configure,
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "gqlerrors", "true") })
+	assertSet(t, got, []string{"bob", "eve"})
+
+	got = runScan(t, tt.URL+"/graphql", words, configure,
+		func(mm ffuf.MatcherManager) {
+			mustMatch(t, mm, "status", "200")
+			mustFilter(t, mm, "gqlmessage", "not found")
+		})
+	assertSet(t, got, []string{"admin"})
+}
+
+// TestGraphQLHarvest starts from prefixes of field names. The fields suggested
+// in the "Did you mean" errors are added to the wordlist and fuzzed in turn.
+func TestGraphQLHarvest(t *testing.T) {
+	tt := testtarget.New()
+	defer tt.Close()
+
+	got := runScan(t, tt.URL+"/graphql", []string{"ver", "xyz"},
+		func(o *ffuf.ConfigOptions) {
+			o.HTTP.GraphQL = `{ FUZZ }`
+			o.Input.GraphQLHarvest = "FUZZ"
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "gqlerrors", "false") })
+	assertSet(t, got, []string{"version"})
+}
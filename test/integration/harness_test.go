@@ -18,6 +18,7 @@ import (
 	"github.com/ffuf/ffuf/v2/pkg/assembly"
 	"github.com/ffuf/ffuf/v2/pkg/ffuf"
 	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/plugin/plugintest"
 )
 
 // TestMain points ffuf.SCRAPERDIR at an empty temp dir for the whole package.
@@ -25,7 +26,10 @@ import (
 // runner) has no populated XDG config dir, and unlike main the in-process harness
 // never runs ReadDefaultConfig to create it. Redirecting to an empty temp dir
 // keeps these tests hermetic, mirroring how the ffuf package redirects AUTOCALIBDIR.
+//
+// The test binary also acts as the plugin of the plugin tests, see plugintest.
 func TestMain(m *testing.M) {
+	plugintest.Main()
 	tmp, err := os.MkdirTemp("", "ffuf-scraperdir-*")
 	if err != nil {
 		panic(err)
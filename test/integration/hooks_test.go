@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/output"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// hookReceiver records the events posted to a -hook webhook
+type hookReceiver struct {
+	mutex  sync.Mutex
+	events []output.HookPayload
+}
+
+func (h *hookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var p output.HookPayload
+	b, _ := io.ReadAll(r.Body)
+	_ = json.Unmarshal(b, &p)
+	h.mutex.Lock()
+	h.events = append(h.events, p)
+	h.mutex.Unlock()
+}
+
+func (h *hookReceiver) count(event string) int {
+	n := 0
+	for _, p := range h.events {
+		if p.Event == event {
+			n++
+		}
+	}
+	return n
+}
+
+func runHookScan(t *testing.T, words []string, hook string, setup func(opts *ffuf.ConfigOptions)) {
+	t.Helper()
+	target := testtarget.New()
+	defer target.Close()
+
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/status/FUZZ"
+	opts.HTTP.Method = "GET"
+	opts.Input.Wordlists = []string{writeWordlist(t, words)}
+	opts.General.Quiet = true
+	opts.General.Hooks = []string{hook}
+	if setup != nil {
+		setup(opts)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	conf.MatcherManager = filter.NewMatcherManager()
+	mustMatch(t, conf.MatcherManager, "status", "200")
+	job, err := assembly.BuildJob(conf)
+	if err != nil {
+		t.Fatalf("BuildJob: %v", err)
+	}
+	job.Output = &capture{}
+	job.Start()
+	job.Hooks.Close()
+}
+
+// TestHookEvents posts the events of a scan to a -hook webhook.
+func TestHookEvents(t *testing.T) {
+	receiver := &hookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	runHookScan(t, []string{"200", "404", "200"}, "all:"+server.URL, nil)
+
+	for event, want := range map[string]int{
+		ffuf.HookJobQueued:   1,
+		ffuf.HookJobStarted:  1,
+		ffuf.HookMatch:       2,
+		ffuf.HookJobFinished: 1,
+		ffuf.HookStopped:     0,
+	} {
+		if got := receiver.count(event); got != want {
+			t.Errorf("got %d %s events, want %d: %+v", got, event, want, receiver.events)
+		}
+	}
+	for _, p := range receiver.events {
+		if p.Event == ffuf.HookMatch && (p.Result == nil || p.Result.StatusCode != 200) {
+			t.Errorf("unexpected match event %+v", p)
+		}
+	}
+}
+
+// TestHookStopped runs the stopped hooks when -sf stops the scan.
+func TestHookStopped(t *testing.T) {
+	receiver := &hookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	words := make([]string, 100)
+	for i := range words {
+		words[i] = "403"
+	}
+	runHookScan(t, words, "stopped:"+server.URL, func(opts *ffuf.ConfigOptions) {
+		opts.General.StopOn403 = true
+		opts.General.Threads = 1
+	})
+
+	if len(receiver.events) != 1 || receiver.events[0].Message != "Getting an unusual amount of 403 responses, exiting." {
+		t.Errorf("unexpected stopped events %+v", receiver.events)
+	}
+}
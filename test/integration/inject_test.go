@@ -0,0 +1,40 @@
+package integration
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestInputModeAuto fuzzes every query parameter and JSON body value in turn,
+// with the input encoded for each injection point: URL encoded in the query and
+// JSON escaped in the body, where the number becomes a string.
+func TestInputModeAuto(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	runScan(t, target.URL+"/reflect/x?a=1&b=2",
+		[]string{`x"y&`},
+		func(o *ffuf.ConfigOptions) {
+			o.Input.InputMode = "auto"
+			o.HTTP.Method = "POST"
+			o.HTTP.Headers = []string{"Content-Type: application/json"}
+			o.HTTP.Data = `{"user":"bob","id":7}`
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "all") },
+	)
+
+	var got []string
+	for _, r := range target.Requests() {
+		got = append(got, r.Query+" "+r.Body)
+	}
+	sort.Strings(got)
+	assertSet(t, got, []string{
+		`a=1&b=2 {"user":"bob","id":"x\"y&"}`,
+		`a=1&b=2 {"user":"x\"y&","id":7}`,
+		`a=1&b=x%22y%26 {"user":"bob","id":7}`,
+		`a=x%22y%26&b=2 {"user":"bob","id":7}`,
+	})
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/engine"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestMetricsEndpoint scrapes the -metrics endpoint after a scan.
+func TestMetricsEndpoint(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/status/FUZZ"
+	opts.HTTP.Method = "GET"
+	opts.Input.Wordlists = []string{writeWordlist(t, []string{"200", "403", "429", "404"})}
+	opts.General.Quiet = true
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	conf.MatcherManager = filter.NewMatcherManager()
+	mustMatch(t, conf.MatcherManager, "status", "200")
+	job, err := assembly.BuildJob(conf)
+	if err != nil {
+		t.Fatalf("BuildJob: %v", err)
+	}
+	job.Output = &capture{}
+	server, err := engine.ServeMetrics(job, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ServeMetrics: %v", err)
+	}
+	defer server.Close()
+	job.Start()
+
+	resp, err := http.Get("http://" + server.Addr() + "/metrics")
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	metrics := string(b)
+	host := strings.TrimPrefix(target.URL, "http://")
+	for _, want := range []string{
+		`ffuf_requests_total{host="` + host + `"} 4`,
+		`ffuf_matches_total{host="` + host + `"} 1`,
+		`ffuf_responses_total{host="` + host + `",status="403"} 1`,
+		`ffuf_responses_total{host="` + host + `",status="429"} 1`,
+		`ffuf_request_duration_seconds_count{host="` + host + `"} 4`,
+		`ffuf_request_duration_seconds_bucket{host="` + host + `",le="+Inf"} 4`,
+		"ffuf_job_inputs 4",
+		"# TYPE ffuf_request_duration_seconds histogram",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics are missing %q:\n%s", want, metrics)
+		}
+	}
+}
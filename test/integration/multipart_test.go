@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"mime"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestMultipartUpload fuzzes the extension of an uploaded file. The body is
+// rebuilt for every request, so each one has a boundary of its own and a
+// Content-Length that fits the input.
+func TestMultipartUpload(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	file := filepath.Join(t.TempDir(), "payload.bin")
+	if err := os.WriteFile(file, []byte("\x89PNG payload"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got := runScan(t, target.URL+"/upload", []string{"png", "php", "jpg", "phtml"},
+		func(o *ffuf.ConfigOptions) {
+			o.HTTP.Multipart = []string{
+				"note=uploading a FUZZ file",
+				"file=@" + file + ";filename=shell.FUZZ;type=image/png",
+			}
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "200") },
+	)
+	assertSet(t, got, []string{"jpg", "png"})
+
+	boundaries := map[string]bool{}
+	for _, r := range target.Requests() {
+		if r.Method != "POST" {
+			t.Errorf("multipart body sent with %s", r.Method)
+		}
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatalf("Content-Type %q: %v", r.Header.Get("Content-Type"), err)
+		}
+		boundaries[params["boundary"]] = true
+	}
+	if len(boundaries) != 4 {
+		t.Errorf("got %d distinct boundaries for 4 requests", len(boundaries))
+	}
+}
@@ -106,7 +106,7 @@ func TestOutputFormats(t *testing.T) {
 	)
 
 	dir := t.TempDir()
-	for _, format := range []string{"json", "ejson", "csv", "ecsv", "md", "html"} {
+	for _, format := range []string{"json", "ejson", "csv", "ecsv", "md", "html", "har"} {
 		path := filepath.Join(dir, "out."+format)
 		if err := out.SaveFile(path, format); err != nil {
 			t.Errorf("SaveFile(%s): %v", format, err)
@@ -178,6 +178,28 @@ func TestOutputFormats(t *testing.T) {
 				continue
 			}
 			assertSet(t, []string{rows[1][col], rows[2][col]}, []string{"200", "201"})
+		case "har":
+			var doc struct {
+				Log struct {
+					Entries []struct {
+						Response struct {
+							Status int64 `json:"status"`
+						} `json:"response"`
+						Input map[string]string `json:"_input"`
+					} `json:"entries"`
+				} `json:"log"`
+			}
+			if err := json.Unmarshal(b, &doc); err != nil {
+				t.Errorf("har: %v", err)
+				continue
+			}
+			var statuses, inputs []string
+			for _, e := range doc.Log.Entries {
+				statuses = append(statuses, fmt.Sprintf("%d", e.Response.Status))
+				inputs = append(inputs, e.Input["FUZZ"])
+			}
+			assertSet(t, statuses, []string{"200", "201"})
+			assertSet(t, inputs, []string{"200", "201"})
 		case "md", "html":
 			// Human formats: both status codes must be present in the rendered output.
 			for _, in := range []string{"200", "201"} {
@@ -188,3 +210,139 @@ func TestOutputFormats(t *testing.T) {
 		}
 	}
 }
+
+// TestOutputHARAndRequestFiles checks that the HAR output carries the full
+// exchange of each match, and that the -odr request files import back with
+// -request to the request that matched.
+func TestOutputHARAndRequestFiles(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	dir := t.TempDir()
+	harFile := filepath.Join(dir, "out.har")
+	requestDir := filepath.Join(dir, "requests")
+	out := runScanStdout(t, target.URL+"/needs-body?q=1",
+		[]string{"alpha", "beta"},
+		func(o *ffuf.ConfigOptions) {
+			o.HTTP.Method = "POST"
+			o.HTTP.Data = "token=FUZZ\n"
+			o.HTTP.Headers = []string{"X-Test: yes", "Content-Type: application/x-www-form-urlencoded"}
+			o.Output.OutputFile = harFile
+			o.Output.OutputFormat = "har"
+			o.Output.OutputRequestDir = requestDir
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "200") },
+	)
+	if err := out.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	b, err := os.ReadFile(harFile)
+	if err != nil {
+		t.Fatalf("read HAR: %v", err)
+	}
+	var har struct {
+		Log struct {
+			Version string `json:"version"`
+			Entries []struct {
+				StartedDateTime string `json:"startedDateTime"`
+				Request         struct {
+					Method      string `json:"method"`
+					URL         string `json:"url"`
+					QueryString []struct {
+						Name, Value string
+					} `json:"queryString"`
+					PostData struct {
+						Text string `json:"text"`
+					} `json:"postData"`
+				} `json:"request"`
+				Response struct {
+					Status  int `json:"status"`
+					Content struct {
+						Text string `json:"text"`
+					} `json:"content"`
+				} `json:"response"`
+				Timings struct {
+					Wait float64 `json:"wait"`
+				} `json:"timings"`
+				Input map[string]string `json:"_input"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(b, &har); err != nil {
+		t.Fatalf("HAR is not valid JSON: %v", err)
+	}
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 2 {
+		t.Fatalf("unexpected HAR log: version %q, %d entries", har.Log.Version, len(har.Log.Entries))
+	}
+	var inputs []string
+	for _, e := range har.Log.Entries {
+		inputs = append(inputs, e.Input["FUZZ"])
+		if e.Request.Method != "POST" || e.Request.PostData.Text != "token="+e.Input["FUZZ"]+"\n" {
+			t.Errorf("unexpected request %+v", e.Request)
+		}
+		if len(e.Request.QueryString) != 1 || e.Request.QueryString[0].Name != "q" {
+			t.Errorf("unexpected query string %+v", e.Request.QueryString)
+		}
+		if e.Response.Status != 200 || e.Response.Content.Text != "body ok" {
+			t.Errorf("unexpected response %+v", e.Response)
+		}
+		if e.StartedDateTime == "" || e.Timings.Wait < 0 {
+			t.Errorf("unexpected timings %s %+v", e.StartedDateTime, e.Timings)
+		}
+	}
+	assertSet(t, inputs, []string{"alpha", "beta"})
+
+	files, err := os.ReadDir(requestDir)
+	if err != nil {
+		t.Fatalf("read request directory: %v", err)
+	}
+	var bodies []string
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(requestDir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		reqs, err := ffuf.ImportRequests(data, ffuf.DetectRequestFormat(data), "https")
+		if err != nil {
+			t.Fatalf("%s does not import: %v", f.Name(), err)
+		}
+		req := reqs[0]
+		if req.Method != "POST" || req.Url != target.URL+"/needs-body?q=1" || req.Headers["X-Test"] != "yes" {
+			t.Errorf("%s imported as %s %s %v", f.Name(), req.Method, req.Url, req.Headers)
+		}
+		bodies = append(bodies, string(req.Data))
+	}
+	assertSet(t, bodies, []string{"token=alpha\n", "token=beta\n"})
+}
+
+// TestOutputHTMLReport checks the HTML report is self-contained, and embeds the
+// configuration and previews of the responses saved to -od.
+func TestOutputHTMLReport(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.html")
+
+	runScanStdout(t, target.URL+"/status/FUZZ", []string{"200", "201"}, func(o *ffuf.ConfigOptions) {
+		o.Output.OutputFile = report
+		o.Output.OutputFormat = "html"
+		o.Output.OutputDirectory = filepath.Join(dir, "od")
+	}, func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "200,201") })
+
+	b, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	html := string(b)
+	for _, external := range []string{"<link", "<script src", "cdn."} {
+		if strings.Contains(html, external) {
+			t.Errorf("report is not self-contained, it has %q", external)
+		}
+	}
+	for _, want := range []string{"status 200", "status 201", "&#34;url&#34;: &#34;" + target.URL + "/status/FUZZ&#34;", `data-host="` + strings.TrimPrefix(target.URL, "http://") + `"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+}
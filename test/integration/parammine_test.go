@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestParamMine packs the candidate names in batches of 8 and bisects the
+// batches whose response differs from the calibrated baseline. /params changes
+// the body for "debug" and the status for "admin", in every location.
+func TestParamMine(t *testing.T) {
+	words := []string{"debug", "admin"}
+	for i := 0; i < 38; i++ {
+		words = append(words, fmt.Sprintf("candidate%d", i))
+	}
+	for _, location := range []string{"query", "form", "json", "header"} {
+		t.Run(location, func(t *testing.T) {
+			target := testtarget.New()
+			defer target.Close()
+
+			got := runScan(t, target.URL+"/params", words,
+				func(o *ffuf.ConfigOptions) {
+					o.Input.ParamMine = location
+					o.Input.ParamBatch = 8
+					// Hermetic calibration strings, see TestAutoCalibration
+					o.General.AutoCalibrationStrings = []string{"calibrate-one", "calibrate-two"}
+				},
+				func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "all") },
+			)
+			assertSet(t, got, []string{"admin", "debug"})
+			if n := target.Count(); n >= len(words) {
+				t.Errorf("mining made %d requests for %d candidates", n, len(words))
+			}
+		})
+	}
+}
+
+// TestParamMineScript bisects a batch the -script matches although its response
+// does not differ from the baseline, down to the candidate the script wants.
+func TestParamMineScript(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	script := filepath.Join(t.TempDir(), "script.star")
+	src := `
+def on_response(resp):
+    if "candidate7=" in resp["url"]:
+        return True
+    return None
+`
+	if err := os.WriteFile(script, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	words := []string{"debug", "admin"}
+	for i := 0; i < 38; i++ {
+		words = append(words, fmt.Sprintf("candidate%d", i))
+	}
+
+	got := runScan(t, target.URL+"/params", words,
+		func(o *ffuf.ConfigOptions) {
+			o.Input.ParamMine = "query"
+			o.Input.ParamBatch = 8
+			o.General.AutoCalibrationStrings = []string{"calibrate-one", "calibrate-two"}
+			o.General.Script = script
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "all") },
+	)
+	assertSet(t, got, []string{"admin", "debug", "candidate7"})
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/plugin/plugintest"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestPlugins fuzzes with the values of an input plugin, which also mutates the
+// requests and filters the responses: the request for 500 is sent to
+// /status/201 instead, and the 403 response is filtered out.
+func TestPlugins(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	t.Setenv(plugintest.Env, "ok")
+
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/status/FUZZ"
+	opts.HTTP.Method = "GET"
+	opts.General.Quiet = true
+	opts.General.Plugins = []ffuf.PluginConfig{{
+		Name:    "test",
+		Command: os.Args[0],
+		Roles:   []string{ffuf.PluginInput, ffuf.PluginFilter, ffuf.PluginMutator},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	conf.MatcherManager = filter.NewMatcherManager()
+	mustMatch(t, conf.MatcherManager, "status", "all")
+	job, err := assembly.BuildJob(conf)
+	if err != nil {
+		t.Fatalf("BuildJob: %v", err)
+	}
+	defer job.Plugins.Close()
+	out := &capture{}
+	job.Output = out
+	job.Start()
+
+	statuses := make(map[string]int64)
+	for _, r := range out.responses {
+		statuses[string(r.Request.Input["FUZZ"])] = r.StatusCode
+		if r.Request.Headers["X-Plugin"] != "mutated" {
+			t.Errorf("the request for %s was not mutated", r.Request.Url)
+		}
+	}
+	want := map[string]int64{"200": 200, "404": 404, "500": 201}
+	if len(statuses) != len(want) {
+		t.Errorf("got results %v, want %v", statuses, want)
+	}
+	for input, status := range want {
+		if statuses[input] != status {
+			t.Errorf("got status %d for %s, want %d", statuses[input], input, status)
+		}
+	}
+}
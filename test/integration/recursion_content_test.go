@@ -0,0 +1,46 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestRecursionContentStrategy covers the content recursion strategy with a
+// -recursion-wordlist for the first depth: /listing is a directory listing and
+// /app a directory served with a 200, which are both descended into with the
+// smaller wordlist, while /plain does not match the -recursion-match matcher
+// and /app.js is named like a file. The -e extension is turned off for the
+// recursion jobs with -recursion-extensions.
+func TestRecursionContentStrategy(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	deeper := writeWordlist(t, []string{"deep", "login"})
+	got := runScan(t, target.URL+"/FUZZ",
+		[]string{"listing", "app", "plain", "missing"},
+		func(o *ffuf.ConfigOptions) {
+			o.HTTP.Recursion = true
+			o.HTTP.RecursionStrategy = "content"
+			o.HTTP.RecursionDepth = 1
+			o.HTTP.RecursionMatch = []string{"regexp:href"}
+			o.Input.Extensions = ".js"
+			o.Input.RecursionWordlists = []string{"1:" + deeper}
+			o.Input.RecursionExtensions = []string{"1:"}
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "200") },
+	)
+	assertSet(t, got, []string{"listing", "app", "plain", "app.js", "deep", "login"})
+
+	for _, r := range target.Requests() {
+		if strings.HasPrefix(r.Path, "/plain/") || strings.HasPrefix(r.Path, "/app.js/") {
+			t.Errorf("recursed into %s", r.Path)
+		}
+		deep := strings.Count(r.Path, "/") > 1
+		if deep && (strings.HasSuffix(r.Path, "/missing") || strings.HasSuffix(r.Path, ".js")) {
+			t.Errorf("the recursion job did not use its own wordlist and extensions: %s", r.Path)
+		}
+	}
+}
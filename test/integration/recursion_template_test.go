@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestRecursionTemplate recurses into a keyword in the middle of the URL path:
+// /api/v1/users matches, so the recursion job fuzzes /api/v1/FUZZ/users and
+// finds /api/v1/admin/users.
+func TestRecursionTemplate(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	got := runScan(t, target.URL+"/api/FUZZ/users",
+		[]string{"v1", "v2", "admin"},
+		func(o *ffuf.ConfigOptions) {
+			o.HTTP.Recursion = true
+			o.HTTP.RecursionStrategy = "greedy"
+			o.HTTP.RecursionDepth = 1
+			o.HTTP.RecursionTemplate = []string{"FUZZ:path"}
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "200") },
+	)
+	assertSet(t, got, []string{"v1", "admin"})
+
+	seen := false
+	for _, r := range target.Requests() {
+		if r.Path == "/api/v1/admin/users" {
+			seen = true
+		}
+	}
+	if !seen {
+		t.Errorf("the recursion job did not request /api/v1/admin/users")
+	}
+}
+
+// TestRecursionTemplateHost recurses into a virtual host name: dev.example.test
+// matches, so the recursion job fuzzes the Host header FUZZ.dev.example.test
+// and finds admin.dev.example.test.
+func TestRecursionTemplateHost(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+
+	got := runScan(t, target.URL+"/vhost",
+		[]string{"dev", "www", "admin"},
+		func(o *ffuf.ConfigOptions) {
+			o.HTTP.Headers = []string{"Host: FUZZ.example.test"}
+			o.HTTP.Recursion = true
+			o.HTTP.RecursionStrategy = "content"
+			o.HTTP.RecursionDepth = 1
+			o.HTTP.RecursionTemplate = []string{"FUZZ:host"}
+		},
+		func(mm ffuf.MatcherManager) { mustMatch(t, mm, "status", "200") },
+	)
+	assertSet(t, got, []string{"dev", "admin"})
+
+	seen := false
+	for _, r := range target.Requests() {
+		if r.Host == "admin.dev.example.test" {
+			seen = true
+		}
+		if r.Path != "/vhost" {
+			t.Errorf("the host recursion changed the path to %s", r.Path)
+		}
+	}
+	if !seen {
+		t.Errorf("the recursion job did not request the host admin.dev.example.test")
+	}
+}
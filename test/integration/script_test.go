@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/v2/pkg/assembly"
+	"github.com/ffuf/ffuf/v2/pkg/ffuf"
+	"github.com/ffuf/ffuf/v2/pkg/filter"
+	"github.com/ffuf/ffuf/v2/pkg/testtarget"
+)
+
+// TestScript sends the header /needs-header wants from on_request, and matches
+// the 403 responses the status matcher does not with on_response.
+func TestScript(t *testing.T) {
+	target := testtarget.New()
+	defer target.Close()
+	script := filepath.Join(t.TempDir(), "script.star")
+	src := `
+def on_request(req):
+    req["headers"]["X-Test"] = "yes"
+
+def on_response(resp):
+    if resp["status"] == 403:
+        return {"match": True, "data": {"note": "forbidden " + resp["input"]["FUZZ"]}}
+    return None
+`
+	if err := os.WriteFile(script, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := ffuf.NewConfigOptions()
+	opts.HTTP.URL = target.URL + "/FUZZ"
+	opts.HTTP.Method = "GET"
+	opts.Input.Wordlists = []string{writeWordlist(t, []string{"needs-header", "needs-cookie", "map/bad"})}
+	opts.General.Quiet = true
+	opts.General.Script = script
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		t.Fatalf("ConfigFromOptions: %v", err)
+	}
+	conf.MatcherManager = filter.NewMatcherManager()
+	mustMatch(t, conf.MatcherManager, "status", "200")
+	job, err := assembly.BuildJob(conf)
+	if err != nil {
+		t.Fatalf("BuildJob: %v", err)
+	}
+	out := &capture{}
+	job.Output = out
+	job.Start()
+
+	if got := strings.Join(out.inputs(), ","); got != "needs-cookie,needs-header" {
+		t.Errorf("got matches %s, want needs-cookie and needs-header", got)
+	}
+	for _, r := range out.responses {
+		input := string(r.Request.Input["FUZZ"])
+		if input == "needs-header" && r.StatusCode != 200 {
+			t.Errorf("the header of on_request was not sent, got status %d", r.StatusCode)
+		}
+		if input == "needs-cookie" && strings.Join(r.ScraperData["note"], ",") != "forbidden needs-cookie" {
+			t.Errorf("unexpected data %v of the on_response match", r.ScraperData)
+		}
+	}
+}